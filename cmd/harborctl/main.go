@@ -9,7 +9,10 @@ import (
 	"github.com/leandrodaf/harborctl/internal/config"
 	"github.com/leandrodaf/harborctl/pkg/cli"
 	"github.com/leandrodaf/harborctl/pkg/docker"
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
 	"github.com/leandrodaf/harborctl/pkg/fs"
+	"github.com/leandrodaf/harborctl/pkg/git"
+	"github.com/leandrodaf/harborctl/pkg/ssh"
 )
 
 var (
@@ -38,8 +41,17 @@ func main() {
 		return
 	}
 
+	// Global flags go before the command name
+	args, dryRun := extractGlobalFlags(os.Args[1:])
+
+	var recorder dryrun.Recorder
+	if dryRun {
+		recorder = dryrun.NewRecorder(output)
+		output.Info("🧪 Dry-run: nothing will be written or executed, printing the execution plan")
+	}
+
 	// Initialize dependencies with proper error handling
-	dependencies, err := initializeDependencies()
+	dependencies, err := initializeDependencies(recorder)
 	if err != nil {
 		output.Errorf("Failed to initialize dependencies: %v", err)
 		os.Exit(1)
@@ -49,13 +61,17 @@ func main() {
 	runner := cli.NewRunner(output)
 
 	// Register all commands
-	registerCommands(runner, dependencies.ConfigManager, dependencies.ComposeService, dependencies.DockerService, dependencies.FileSystem, output)
+	registerCommands(runner, dependencies, output)
 
 	// Run the CLI
-	if err := runner.Run(ctx, os.Args[1:]); err != nil {
+	if err := runner.Run(ctx, args); err != nil {
 		output.Errorf("Error: %v", err)
 		os.Exit(1)
 	}
+
+	if recorder != nil {
+		output.Infof("🧪 Dry-run finished: %d step(s) planned", len(recorder.Steps()))
+	}
 }
 
 // Dependencies holds all service dependencies
@@ -64,19 +80,51 @@ type Dependencies struct {
 	ComposeService compose.Service
 	DockerService  docker.Service
 	FileSystem     fs.FileSystem
+	GitClient      git.Client
+	SSHExecutor    ssh.Executor
 }
 
-func initializeDependencies() (*Dependencies, error) {
+// extractGlobalFlags removes the global flags that come before the command name and returns
+// the remaining arguments. Everything from the command on (or after "--") belongs to the
+// command, so a later --dry-run reaches its own flag set (deploy-service keeps it as a
+// deprecated alias of --validate-only)
+func extractGlobalFlags(args []string) ([]string, bool) {
+	dryRun := false
+	for i, arg := range args {
+		switch arg {
+		case "--dry-run", "-dry-run":
+			dryRun = true
+		case "--":
+			return args[i+1:], dryRun
+		default:
+			return args[i:], dryRun
+		}
+	}
+	return nil, dryRun
+}
+
+// initializeDependencies wires real implementations, or recording ones when recorder is set
+func initializeDependencies(recorder dryrun.Recorder) (*Dependencies, error) {
 	// Initialize filesystem
-	filesystem := fs.NewFileSystem()
+	var filesystem fs.FileSystem = fs.NewFileSystem()
+	if recorder != nil {
+		filesystem = fs.NewDryRunFileSystem(filesystem, recorder)
+	}
 
 	// Initialize config components
 	configLoader := fs.NewConfigLoader(filesystem)
 	validator := config.NewValidator()
 	configManager := config.NewManager(configLoader, filesystem, validator)
 
-	// Initialize docker components
+	// Initialize docker, git and ssh executors
 	dockerExecutor := docker.NewExecutor()
+	gitClient := git.NewClient()
+	sshExecutor := ssh.NewExecutor()
+	if recorder != nil {
+		dockerExecutor = docker.NewDryRunExecutor(recorder)
+		gitClient = git.NewDryRunClient(recorder)
+		sshExecutor = ssh.NewDryRunExecutor(recorder)
+	}
 	dockerService := docker.NewService(dockerExecutor)
 
 	// Initialize compose service
@@ -87,17 +135,17 @@ func initializeDependencies() (*Dependencies, error) {
 		ComposeService: composeService,
 		DockerService:  dockerService,
 		FileSystem:     filesystem,
+		GitClient:      gitClient,
+		SSHExecutor:    sshExecutor,
 	}, nil
 }
 
-func registerCommands(
-	runner cli.Runner,
-	configManager config.Manager,
-	composeService compose.Service,
-	dockerService docker.Service,
-	filesystem fs.FileSystem,
-	output cli.Output,
-) {
+func registerCommands(runner cli.Runner, deps *Dependencies, output cli.Output) {
+	configManager := deps.ConfigManager
+	composeService := deps.ComposeService
+	dockerService := deps.DockerService
+	filesystem := deps.FileSystem
+
 	// Register init command (handles both interactive and direct modes)
 	runner.Register(commands.NewInitCommand(configManager, output))
 
@@ -107,7 +155,7 @@ func registerCommands(
 	runner.Register(commands.NewBeszelSetupCommand(configManager, output))
 
 	// Register deploy-service command
-	runner.Register(commands.NewDeployServiceCommand(configManager, composeService, dockerService, filesystem, output))
+	runner.Register(commands.NewDeployServiceCommand(configManager, composeService, dockerService, filesystem, deps.GitClient, output))
 
 	// Register up command
 	runner.Register(commands.NewUpCommand(configManager, composeService, dockerService, filesystem, output))
@@ -122,7 +170,7 @@ func registerCommands(
 	runner.Register(commands.NewStartCommand(dockerService, output))
 
 	// Register restart command
-	runner.Register(commands.NewRestartCommand(dockerService, output))
+	runner.Register(commands.NewRestartCommand(dockerService, filesystem, output))
 
 	// Register pause command
 	runner.Register(commands.NewPauseCommand(dockerService, output))
@@ -137,10 +185,13 @@ func registerCommands(
 	runner.Register(commands.NewLogsCommand(dockerService, output))
 
 	// Register remote-logs command
-	runner.Register(commands.NewRemoteLogsCommand(output))
+	runner.Register(commands.NewRemoteLogsCommand(deps.SSHExecutor, output))
 
 	// Register remote-control command
-	runner.Register(commands.NewRemoteControlCommand(output))
+	runner.Register(commands.NewRemoteControlCommand(deps.SSHExecutor, output))
+
+	// Register mirror command
+	runner.Register(commands.NewMirrorCommand(configManager, composeService, dockerService, filesystem, output))
 
 	// Register scale command
 	runner.Register(commands.NewScaleCommand(configManager, dockerService, output))
@@ -148,15 +199,36 @@ func registerCommands(
 	// Register validate command
 	runner.Register(commands.NewValidateCommand(configManager, output))
 
+	// Register config command
+	runner.Register(commands.NewConfigCommand(configManager, output))
+
+	// Register service command
+	runner.Register(commands.NewServiceCommand(configManager, output))
+
+	// Register migrate command
+	runner.Register(commands.NewMigrateCommand(filesystem, output))
+
+	// Register schema command
+	runner.Register(commands.NewSchemaCommand(filesystem, output))
+
 	// Register render command
 	runner.Register(commands.NewRenderCommand(configManager, composeService, filesystem, output))
 
+	// Register certs command
+	runner.Register(commands.NewCertsCommand(configManager, composeService, dockerService, filesystem, output))
+
+	// Register ca command
+	runner.Register(commands.NewCACommand(filesystem, output))
+
 	// Register hash-password command
 	runner.Register(commands.NewHashPasswordCommand(output))
 
 	// Register security-audit command
 	runner.Register(commands.NewSecurityAuditCommand(configManager, output))
 
+	// Register probe command
+	runner.Register(commands.NewProbeCommand(output))
+
 	// Register docs command
 	runner.Register(commands.NewDocsCommand(output))
 }
@@ -165,7 +237,7 @@ func showHelp(output cli.Output) {
 	output.Info("🚢 Harbor CLI - Deployment Tool")
 	output.Info("")
 	output.Info("USAGE:")
-	output.Info("  harborctl [command] [flags]")
+	output.Info("  harborctl [global flags] [command] [flags]")
 	output.Info("")
 	output.Info("COMMANDS:")
 	output.Info("  init              🆕 Initialize project configuration (interactive or direct)")
@@ -186,6 +258,8 @@ func showHelp(output cli.Output) {
 	output.Info("  status            Show status of all services")
 	output.Info("  logs              Show services logs")
 	output.Info("  scale             Scale services up or down")
+	output.Info("  service           Add, remove, list or show services in stack.yml")
+	output.Info("  mirror            Promote or discard a shadow version receiving mirrored traffic")
 	output.Info("")
 	output.Info("REMOTE:")
 	output.Info("  remote-logs       View logs from remote server")
@@ -194,13 +268,21 @@ func showHelp(output cli.Output) {
 	output.Info("TOOLS:")
 	output.Info("  validate          Validate stack configuration")
 	output.Info("  render            Render docker-compose configuration")
+	output.Info("  config resolve    Print stack.yml with includes and variables expanded")
+	output.Info("  migrate           Upgrade stack.yml to the current schema version")
+	output.Info("  schema            Print the JSON Schema for stack.yml")
+	output.Info("  certs             List TLS certificates, flag expiring ones and uncovered hosts")
+	output.Info("  certs issue-client Issue a client certificate from the internal CA (mtls)")
+	output.Info("  ca export         Export the local CA certificate (tls.mode selfsigned)")
 	output.Info("  hash-password     Generate hashed password for authentication")
 	output.Info("  security-audit    Run security audit on the stack")
+	output.Info("  probe             Health probe run inside containers (health_check tool: probe)")
 	output.Info("  docs              Show documentation and guides")
 	output.Info("")
 	output.Info("FLAGS:")
 	output.Info("  -h, --help        Show this help message")
 	output.Info("  -v, --version     Show version information")
+	output.Info("  --dry-run         Print every file write and command without executing")
 	output.Info("")
 	output.Info("For more information about a specific command, run:")
 	output.Info("  harborctl [command] --help")
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
+)
+
+func TestExtractGlobalFlags(t *testing.T) {
+	tests := []struct {
+		args   []string
+		want   []string
+		dryRun bool
+	}{
+		{[]string{"up"}, []string{"up"}, false},
+		{[]string{"--dry-run", "up"}, []string{"up"}, true},
+		{[]string{"-dry-run", "up", "-f", "stack.yml"}, []string{"up", "-f", "stack.yml"}, true},
+		// After the command the flag belongs to it (deploy-service keeps it as an old alias)
+		{[]string{"deploy-service", "--dry-run"}, []string{"deploy-service", "--dry-run"}, false},
+		{[]string{"up", "--dry-run"}, []string{"up", "--dry-run"}, false},
+		{[]string{"--dry-run", "service", "add", "--dry-run"}, []string{"service", "add", "--dry-run"}, true},
+		// "--" ends the global flags
+		{[]string{"--", "--dry-run"}, []string{"--dry-run"}, false},
+		{[]string{"--dry-run", "--", "up"}, []string{"up"}, true},
+		{[]string{"--dry-run"}, nil, true},
+		{nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, dryRun := extractGlobalFlags(tt.args)
+			if !reflect.DeepEqual(got, tt.want) || dryRun != tt.dryRun {
+				t.Fatalf("extractGlobalFlags(%q) = %q, %v, want %q, %v", tt.args, got, dryRun, tt.want, tt.dryRun)
+			}
+		})
+	}
+}
+
+// recordingOutput keeps messages in the order they were printed
+type recordingOutput struct {
+	lines []string
+}
+
+func (o *recordingOutput) Info(msg string)  { o.lines = append(o.lines, msg) }
+func (o *recordingOutput) Error(msg string) { o.lines = append(o.lines, msg) }
+func (o *recordingOutput) Infof(format string, args ...interface{}) {
+	o.lines = append(o.lines, fmt.Sprintf(format, args...))
+}
+func (o *recordingOutput) Errorf(format string, args ...interface{}) {
+	o.lines = append(o.lines, fmt.Sprintf(format, args...))
+}
+
+const dryRunStack = `version: 1
+project: demo
+domain: localhost
+environment: local
+tls:
+  mode: disabled
+networks:
+  public: {}
+  private: {internal: true}
+services:
+  - name: api
+    image: nginx:1.27
+    expose: 80
+    subdomain: api
+`
+
+func TestDryRunUpPrintsPlanInOrder(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("stack.yml", []byte(dryRunStack), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := &recordingOutput{}
+	recorder := dryrun.NewRecorder(output)
+	deps, err := initializeDependencies(recorder)
+	if err != nil {
+		t.Fatalf("initializeDependencies() error = %v", err)
+	}
+	runner := cli.NewRunner(output)
+	registerCommands(runner, deps, output)
+
+	if err := runner.Run(context.Background(), []string{"up"}); err != nil {
+		t.Fatalf("up error = %v\n%s", err, strings.Join(output.lines, "\n"))
+	}
+
+	var printed []string
+	for _, line := range output.lines {
+		if strings.HasPrefix(line, "[dry-run]") || strings.HasPrefix(line, "compose generated") {
+			printed = append(printed, line)
+		}
+	}
+
+	// Writes come before the commands that use them; sizes depend on the generator and are left out
+	want := []string{
+		"[dry-run]  1. mkdir .deploy (mode 0755)",
+		"[dry-run]  2. write .deploy/compose.generated.yml (",
+		"[dry-run]  3. write .deploy/traefik-dynamic.yml (",
+		"compose generated at .deploy/compose.generated.yml",
+		"[dry-run]  4. exec  docker compose -f .deploy/compose.generated.yml up -d --build",
+		"[dry-run]  5. exec  docker image prune -af --filter until=168h",
+		"[dry-run]  6. exec  docker builder prune -af --filter until=168h",
+		"[dry-run]  7. exec  docker volume prune -f",
+	}
+	if len(printed) != len(want) {
+		t.Fatalf("printed plan:\n%s\nwant\n%s", strings.Join(printed, "\n"), strings.Join(want, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(printed[i], want[i]) {
+			t.Fatalf("plan line %d = %q, want %q\n%s", i+1, printed[i], want[i], strings.Join(printed, "\n"))
+		}
+	}
+	if steps := recorder.Steps(); len(steps) != 7 {
+		t.Fatalf("recorded %d steps, want 7", len(steps))
+	}
+
+	// Nothing was written to disk
+	if _, err := os.Stat(".deploy"); !os.IsNotExist(err) {
+		t.Fatalf(".deploy exists after a dry run: %v", err)
+	}
+}
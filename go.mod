@@ -4,10 +4,8 @@ go 1.24.6
 
 require (
 	golang.org/x/crypto v0.41.0
+	golang.org/x/term v0.34.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	golang.org/x/sys v0.35.0 // indirect
-	golang.org/x/term v0.34.0 // indirect
-)
+require golang.org/x/sys v0.35.0 // indirect
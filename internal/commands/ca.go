@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leandrodaf/harborctl/internal/compose"
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/internal/crypto"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+const (
+	caCertFile = "ca.crt"
+	caKeyFile  = "ca.key"
+)
+
+// caCommand implementa o comando ca e seus subcomandos
+type caCommand struct {
+	filesystem fs.FileSystem
+	output     cli.Output
+}
+
+// NewCACommand cria um novo comando ca
+func NewCACommand(filesystem fs.FileSystem, output cli.Output) cli.Command {
+	return &caCommand{
+		filesystem: filesystem,
+		output:     output,
+	}
+}
+
+func (c *caCommand) Name() string {
+	return "ca"
+}
+
+func (c *caCommand) Description() string {
+	return "Manage the local CA used by tls.mode selfsigned and mtls (export)"
+}
+
+func (c *caCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: harborctl ca export [-d .deploy] [-o ca.crt]")
+	}
+
+	switch args[0] {
+	case "export":
+		return c.export(args[1:])
+	default:
+		return fmt.Errorf("unknown ca subcommand: %s (available: export)", args[0])
+	}
+}
+
+// export imprime ou grava o certificado da CA local para ser instalado nos clientes
+func (c *caCommand) export(args []string) error {
+	fs := flag.NewFlagSet("ca export", flag.ExitOnError)
+
+	var dir, outputPath string
+	fs.StringVar(&dir, "d", ".deploy", "directory of the generated compose")
+	fs.StringVar(&outputPath, "o", "", "write the CA certificate to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	certPath := filepath.Join(dir, compose.SelfSignedCADir, caCertFile)
+	if !c.filesystem.Exists(certPath) {
+		return fmt.Errorf("no local CA found at %s (use tls.mode: selfsigned or mtls and run 'harborctl render' first)", certPath)
+	}
+	certPEM, err := c.filesystem.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	cert, err := crypto.ParseCertificate(certPEM)
+	if err != nil {
+		return fmt.Errorf("%s: %w", certPath, err)
+	}
+
+	if outputPath == "" {
+		c.output.Info(strings.TrimSuffix(string(certPEM), "\n"))
+		return nil
+	}
+
+	if err := c.filesystem.WriteFile(outputPath, certPEM, 0644); err != nil {
+		return err
+	}
+	c.output.Infof("CA %q (expires %s) exported to %s", cert.Subject.CommonName, cert.NotAfter.Format("2006-01-02"), outputPath)
+	c.output.Info("")
+	c.output.Info("Trust it on each client:")
+	c.output.Infof("  Debian/Ubuntu: sudo cp %s /usr/local/share/ca-certificates/harborctl.crt && sudo update-ca-certificates", outputPath)
+	c.output.Infof("  RHEL/Fedora:   sudo cp %s /etc/pki/ca-trust/source/anchors/harborctl.crt && sudo update-ca-trust", outputPath)
+	c.output.Infof("  macOS:         sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain %s", outputPath)
+	c.output.Infof("  Windows:       certutil -addstore -f Root %s", outputPath)
+	return nil
+}
+
+// writeSelfSignedCertificates garante a CA local, publica seu certificado para a validação de clientes
+// e (re)emite o certificado padrão do Traefik quando ele não existe, expira em breve ou não cobre os hosts
+func writeSelfSignedCertificates(ctx context.Context, composeService compose.Service, filesystem fs.FileSystem, stack *config.Stack, dir string) error {
+	if !compose.UsesInternalCA(stack) {
+		return nil
+	}
+
+	ca, err := loadOrCreateCA(filesystem, filepath.Join(dir, compose.SelfSignedCADir), stack.Project)
+	if err != nil {
+		return err
+	}
+
+	// Cópia pública da CA, montada no Traefik para o mtls
+	certDir := filepath.Join(dir, compose.SelfSignedDir)
+	if err := filesystem.MkdirAll(certDir, 0755); err != nil {
+		return err
+	}
+	if err := filesystem.WriteFile(filepath.Join(certDir, compose.InternalCACert), ca.CertPEM, 0644); err != nil {
+		return err
+	}
+
+	hosts := composeService.SelfSignedHosts(ctx, stack)
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	certPath := filepath.Join(certDir, compose.SelfSignedCert)
+	if filesystem.Exists(certPath) && filesystem.Exists(filepath.Join(certDir, compose.SelfSignedKey)) {
+		current, err := filesystem.ReadFile(certPath)
+		if err != nil {
+			return err
+		}
+		if !ca.NeedsRenewal(current, hosts, time.Now()) {
+			return nil
+		}
+	}
+
+	certPEM, keyPEM, err := ca.Issue(hosts)
+	if err != nil {
+		return err
+	}
+	if err := filesystem.WriteFile(filepath.Join(certDir, compose.SelfSignedKey), keyPEM, 0600); err != nil {
+		return err
+	}
+	return filesystem.WriteFile(certPath, certPEM, 0644)
+}
+
+// loadOrCreateCA carrega a CA local ou gera uma nova quando ausente ou perto de expirar
+func loadOrCreateCA(filesystem fs.FileSystem, dir, project string) (*crypto.CA, error) {
+	certPath := filepath.Join(dir, caCertFile)
+	keyPath := filepath.Join(dir, caKeyFile)
+
+	if filesystem.Exists(certPath) && filesystem.Exists(keyPath) {
+		certPEM, err := filesystem.ReadFile(certPath)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := filesystem.ReadFile(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		ca, err := crypto.LoadCA(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		if time.Now().Add(crypto.RenewBefore).Before(ca.Cert.NotAfter) {
+			return ca, nil
+		}
+	}
+
+	name := "harborctl local CA"
+	if project != "" {
+		name = project + " " + name
+	}
+	certPEM, keyPEM, err := crypto.GenerateCA(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := filesystem.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := filesystem.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return nil, err
+	}
+	if err := filesystem.WriteFile(certPath, certPEM, 0644); err != nil {
+		return nil, err
+	}
+	return crypto.LoadCA(certPEM, keyPEM)
+}
@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leandrodaf/harborctl/internal/compose"
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/internal/crypto"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/docker"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+// certsCommand implementa o comando certs
+type certsCommand struct {
+	configManager  config.Manager
+	composeService compose.Service
+	dockerService  docker.Service
+	filesystem     fs.FileSystem
+	output         cli.Output
+}
+
+// certEntry é uma linha do inventário, com o estado calculado
+type certEntry struct {
+	crypto.Certificate
+	DaysLeft int    `json:"days_left"`
+	Status   string `json:"status"` // ok | expiring | expired
+}
+
+// certsReport é o inventário completo, também emitido em JSON
+type certsReport struct {
+	Certificates []certEntry `json:"certificates"`
+	Missing      []string    `json:"missing"`
+	Threshold    int         `json:"threshold_days"`
+}
+
+// NewCertsCommand cria um novo comando certs
+func NewCertsCommand(configManager config.Manager, composeService compose.Service, dockerService docker.Service, filesystem fs.FileSystem, output cli.Output) cli.Command {
+	return &certsCommand{
+		configManager:  configManager,
+		composeService: composeService,
+		dockerService:  dockerService,
+		filesystem:     filesystem,
+		output:         output,
+	}
+}
+
+func (c *certsCommand) Name() string {
+	return "certs"
+}
+
+func (c *certsCommand) Description() string {
+	return "List TLS certificates and check expiry"
+}
+
+func (c *certsCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "issue-client" {
+		return c.issueClient(args[1:])
+	}
+
+	fs := flag.NewFlagSet("certs", flag.ExitOnError)
+
+	var stackPath, env, dir, composePath, acmePath, format string
+	var days int
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado")
+	fs.StringVar(&dir, "d", ".deploy", "diretório do compose gerado")
+	fs.StringVar(&composePath, "compose", "", "compose file com o container traefik (padrão: <d>/compose.generated.yml)")
+	fs.StringVar(&acmePath, "acme", "", "acme.json local (padrão: lido do container traefik)")
+	fs.IntVar(&days, "days", 21, "falha quando um certificado expira em menos dias")
+	fs.StringVar(&format, "format", "table", "formato do relatório (table|json)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("invalid format: %s (use table or json)", format)
+	}
+	if composePath == "" {
+		composePath = filepath.Join(dir, "compose.generated.yml")
+	}
+
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
+	if err != nil {
+		return err
+	}
+	environment := compose.GetEnvironmentFromStack(stack)
+
+	var certs []crypto.Certificate
+
+	// Certificados emitidos pelo ACME
+	if acmePath != "" || environment.ACMEEnabled(stack.TLS) {
+		data, err := c.readACME(ctx, composePath, acmePath, compose.ACMEStorage(stack.TLS))
+		if err != nil {
+			return err
+		}
+		// Vazio antes do primeiro certificado emitido (e em dry-run, que não lê o container)
+		if len(strings.TrimSpace(string(data))) > 0 {
+			acmeCerts, err := crypto.ParseACMEStore(data)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, acmeCerts...)
+		}
+	}
+
+	// Certificados gerenciados pelo harborctl: CA local e arquivos dos domínios
+	managed, err := c.managedCertificates(stack, dir)
+	if err != nil {
+		return err
+	}
+	certs = append(certs, managed...)
+
+	report := certsReport{Certificates: make([]certEntry, 0, len(certs)), Missing: []string{}, Threshold: days}
+	now := time.Now()
+	for _, cert := range certs {
+		entry := certEntry{Certificate: cert, DaysLeft: cert.DaysLeft(now), Status: "ok"}
+		switch {
+		case !now.Before(cert.NotAfter):
+			entry.Status = "expired"
+		case entry.DaysLeft < days:
+			entry.Status = "expiring"
+		}
+		report.Certificates = append(report.Certificates, entry)
+	}
+
+	// Hosts roteados sem certificado (sem TLS não há o que cobrir)
+	if environment.TLSEnabled() {
+		for _, host := range c.composeService.RoutedHosts(ctx, stack) {
+			covered := false
+			for _, cert := range certs {
+				if cert.Covers(host) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				report.Missing = append(report.Missing, host)
+			}
+		}
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		c.output.Info(string(data))
+	} else {
+		c.renderTable(report)
+	}
+
+	var expiring, expired int
+	for _, entry := range report.Certificates {
+		switch entry.Status {
+		case "expiring":
+			expiring++
+		case "expired":
+			expired++
+		}
+	}
+	if expiring+expired+len(report.Missing) > 0 {
+		return fmt.Errorf("certificate check failed: %d expired, %d expiring within %d days, %d host(s) without certificate",
+			expired, expiring, days, len(report.Missing))
+	}
+	return nil
+}
+
+// issueClient emite pela CA interna um certificado de cliente aceito pelos serviços com mtls
+func (c *certsCommand) issueClient(args []string) error {
+	fs := flag.NewFlagSet("certs issue-client", flag.ExitOnError)
+
+	var name, dir, outputDir string
+	var days int
+	fs.StringVar(&name, "name", "", "nome do cliente (CN do certificado)")
+	fs.IntVar(&days, "days", 365, "validade em dias")
+	fs.StringVar(&dir, "d", ".deploy", "diretório do compose gerado")
+	fs.StringVar(&outputDir, "o", ".", "diretório onde gravar <name>.crt e <name>.key")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: harborctl certs issue-client --name <client> [--days 365] [-d .deploy] [-o dir]")
+	}
+	if days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	caDir := filepath.Join(dir, compose.SelfSignedCADir)
+	certPath, keyPath := filepath.Join(caDir, caCertFile), filepath.Join(caDir, caKeyFile)
+	if !c.filesystem.Exists(certPath) || !c.filesystem.Exists(keyPath) {
+		return fmt.Errorf("no local CA found at %s (use mtls or tls.mode: selfsigned and run 'harborctl render' first)", caDir)
+	}
+	caCert, err := c.filesystem.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	caKey, err := c.filesystem.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	ca, err := crypto.LoadCA(caCert, caKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", caDir, err)
+	}
+
+	certPEM, keyPEM, err := ca.IssueClient(name, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+	clientCert := filepath.Join(outputDir, slug+".crt")
+	clientKey := filepath.Join(outputDir, slug+".key")
+	if err := c.filesystem.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	if err := c.filesystem.WriteFile(clientKey, keyPEM, 0600); err != nil {
+		return err
+	}
+	if err := c.filesystem.WriteFile(clientCert, certPEM, 0644); err != nil {
+		return err
+	}
+
+	c.output.Infof("Client certificate for %q written to %s and %s", name, clientCert, clientKey)
+	c.output.Info("")
+	c.output.Info("Use it against a service with mtls:")
+	c.output.Infof("  curl --cert %s --key %s https://api.example.com/", clientCert, clientKey)
+	c.output.Infof("  openssl pkcs12 -export -in %s -inkey %s -out %s.p12   # for browsers", clientCert, clientKey, slug)
+	return nil
+}
+
+// readACME lê o acme.json informado ou o do container traefik em execução (volume traefik_acme)
+func (c *certsCommand) readACME(ctx context.Context, composePath, acmePath, storage string) ([]byte, error) {
+	if acmePath != "" {
+		return c.filesystem.ReadFile(acmePath)
+	}
+
+	data, err := c.dockerService.ReadFile(ctx, composePath, "traefik", storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the traefik container: %w (is it running? use --acme to read a copy)", storage, err)
+	}
+	return data, nil
+}
+
+// managedCertificates lê os certificados gravados pelo harborctl e os fornecidos nos domínios
+func (c *certsCommand) managedCertificates(stack *config.Stack, dir string) ([]crypto.Certificate, error) {
+	var certs []crypto.Certificate
+	read := func(path, source, resolver string) error {
+		data, err := c.filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cert, err := crypto.CertificateInfo(data, source)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		cert.Resolver = resolver
+		certs = append(certs, cert)
+		return nil
+	}
+
+	for _, name := range []string{
+		filepath.Join(compose.SelfSignedCADir, caCertFile),
+		filepath.Join(compose.SelfSignedDir, compose.SelfSignedCert),
+	} {
+		if path := filepath.Join(dir, name); c.filesystem.Exists(path) {
+			if err := read(path, name, "selfsigned"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, service := range stack.Services {
+		for _, d := range service.Domains {
+			if d.CertFile == "" {
+				continue
+			}
+			// Caminhos relativos são resolvidos pelo docker compose a partir do diretório do compose
+			path := d.CertFile
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			if !c.filesystem.Exists(path) {
+				// O host aparece sem certificado no relatório
+				c.output.Errorf("%s: cert_file %s not found", service.Name, path)
+				continue
+			}
+			if err := read(path, d.CertFile, "file"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return certs, nil
+}
+
+func (c *certsCommand) renderTable(report certsReport) {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tEXPIRES\tDAYS\tRESOLVER\tISSUER\tDOMAINS\tSOURCE")
+	for _, entry := range report.Certificates {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", entry.Status, entry.NotAfter.Format("2006-01-02"), entry.DaysLeft,
+			entry.Resolver, entry.Issuer, strings.Join(entry.Domains, ","), entry.Source)
+	}
+	for _, host := range report.Missing {
+		fmt.Fprintf(w, "missing\t-\t-\t-\t-\t%s\t-\n", host)
+	}
+	w.Flush()
+
+	if len(report.Certificates)+len(report.Missing) == 0 {
+		c.output.Info("No certificates found.")
+		return
+	}
+	c.output.Info(strings.TrimRight(b.String(), "\n"))
+}
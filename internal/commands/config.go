@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+)
+
+// configCommand implementa o comando config e seus subcomandos
+type configCommand struct {
+	configManager config.Manager
+	output        cli.Output
+}
+
+// NewConfigCommand cria um novo comando config
+func NewConfigCommand(configManager config.Manager, output cli.Output) cli.Command {
+	return &configCommand{
+		configManager: configManager,
+		output:        output,
+	}
+}
+
+func (c *configCommand) Name() string {
+	return "config"
+}
+
+func (c *configCommand) Description() string {
+	return "Inspect stack configuration (resolve)"
+}
+
+func (c *configCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: harborctl config resolve [-f stack.yml] [--env name]")
+	}
+
+	switch args[0] {
+	case "resolve":
+		return c.resolve(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown config subcommand: %s (available: resolve)", args[0])
+	}
+}
+
+// resolve imprime o stack com includes e overlay mesclados e variáveis expandidas
+func (c *configCommand) resolve(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("config resolve", flag.ExitOnError)
+
+	var stackPath, env string
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := c.configManager.Resolve(ctx, stackPath, env)
+	if err != nil {
+		return err
+	}
+
+	// Garante que o resultado também é uma configuração válida
+	if _, err := c.configManager.LoadEnv(ctx, stackPath, env); err != nil {
+		return err
+	}
+
+	c.output.Info(strings.TrimSuffix(string(data), "\n"))
+	return nil
+}
@@ -23,7 +23,7 @@ type deployServiceCommand struct {
 	composeService compose.Service
 	dockerService  docker.Service
 	filesystem     fs.FileSystem
-	gitClient      *git.Client
+	gitClient      git.Client
 	prompter       prompt.Prompter
 	errorHandler   *prompt.ErrorHandler
 	output         cli.Output
@@ -35,6 +35,7 @@ func NewDeployServiceCommand(
 	composeService compose.Service,
 	dockerService docker.Service,
 	filesystem fs.FileSystem,
+	gitClient git.Client,
 	output cli.Output,
 ) cli.Command {
 	prompter := prompt.NewPrompter()
@@ -43,7 +44,7 @@ func NewDeployServiceCommand(
 		composeService: composeService,
 		dockerService:  dockerService,
 		filesystem:     filesystem,
-		gitClient:      git.NewClient(),
+		gitClient:      gitClient,
 		prompter:       prompter,
 		errorHandler:   prompt.NewErrorHandler(prompter),
 		output:         output,
@@ -61,24 +62,31 @@ func (c *deployServiceCommand) Description() string {
 func (c *deployServiceCommand) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("deploy-service", flag.ExitOnError)
 
-	var serviceName, repoURL, branch, path, envFile, secretsFile string
-	var dryRun, force bool
+	var serviceName, repoURL, branch, path, envFile, secretsFile, env string
+	var validateOnly, deprecatedDryRun, force bool
 	var replicas int
 
 	fs.StringVar(&serviceName, "service", "", "microservice name")
 	fs.StringVar(&repoURL, "repo", "", "repository URL (optional if already cloned)")
 	fs.StringVar(&branch, "branch", "main", "repository branch")
 	fs.StringVar(&path, "path", "deploy", "stack.yml path in repository")
+	fs.StringVar(&env, "env", "", "environment overlay (stack.<env>.yml)")
 	fs.StringVar(&envFile, "env-file", "", "environment variables file")
 	fs.StringVar(&secretsFile, "secrets-file", "", "secrets file")
 	fs.IntVar(&replicas, "replicas", 0, "number of replicas (override)")
-	fs.BoolVar(&dryRun, "dry-run", false, "validate only without deploying")
+	fs.BoolVar(&validateOnly, "validate-only", false, "validate only without deploying (see global --dry-run for the full plan)")
+	fs.BoolVar(&deprecatedDryRun, "dry-run", false, "deprecated alias of --validate-only (put --dry-run before the command for the execution plan)")
 	fs.BoolVar(&force, "force", false, "force deployment ignoring warnings")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	if deprecatedDryRun {
+		c.output.Error("⚠️  'deploy-service --dry-run' is deprecated: use --validate-only, or 'harborctl --dry-run deploy-service' for the execution plan")
+		validateOnly = true
+	}
+
 	if serviceName == "" {
 		return fmt.Errorf("--service is required")
 	}
@@ -94,14 +102,19 @@ func (c *deployServiceCommand) Execute(ctx context.Context, args []string) error
 	}
 
 	// Get microservice code
-	serviceDir, err := c.getServiceCode(ctx, serviceName, repoURL, branch, path)
+	serviceDir, fetched, err := c.getServiceCode(ctx, serviceName, repoURL, branch, path)
 	if err != nil {
 		return fmt.Errorf("error getting service code: %w", err)
 	}
+	if !fetched {
+		// Em dry-run o clone só é registrado; sem o código não há stack.yml para planejar
+		c.output.Info("🧪 Repository not cloned in dry-run: the plan stops here, the service stack.yml comes from the clone")
+		return nil
+	}
 
 	// Load microservice configuration
 	stackPath := filepath.Join(serviceDir, "stack.yml")
-	serviceConfig, err := c.configManager.Load(ctx, stackPath)
+	serviceConfig, err := c.configManager.LoadEnv(ctx, stackPath, env)
 	if err != nil {
 		return fmt.Errorf("error loading service stack.yml: %w", err)
 	}
@@ -122,8 +135,8 @@ func (c *deployServiceCommand) Execute(ctx context.Context, args []string) error
 		c.output.Errorf("⚠️  Validação falhou, mas continuando com --force: %v", err)
 	}
 
-	if dryRun {
-		c.output.Info("✅ Dry-run concluído. Configuração válida.")
+	if validateOnly {
+		c.output.Info("✅ Validação concluída. Configuração válida.")
 		return nil
 	}
 
@@ -139,42 +152,47 @@ func (c *deployServiceCommand) loadBaseConfig(ctx context.Context) (*config.Stac
 	return nil, fmt.Errorf("configuração base não encontrada")
 }
 
-func (c *deployServiceCommand) getServiceCode(ctx context.Context, serviceName, repoURL, branch, path string) (string, error) {
+// getServiceCode retorna o diretório do stack.yml do serviço e se o código está em disco
+// (false quando o clone foi apenas registrado pelo dry-run)
+func (c *deployServiceCommand) getServiceCode(ctx context.Context, serviceName, repoURL, branch, path string) (string, bool, error) {
 	serviceDir := filepath.Join(".services", serviceName)
 
 	// Se não tem URL do repo, assumir que já está clonado
 	if repoURL == "" {
 		if exists := c.filesystem.Exists(serviceDir); exists {
 			c.output.Infof("📁 Usando código local em: %s", serviceDir)
-			return filepath.Join(serviceDir, path), nil
+			return filepath.Join(serviceDir, path), true, nil
 		}
-		return "", fmt.Errorf("service code not found and --repo not specified")
+		return "", false, fmt.Errorf("service code not found and --repo not specified")
 	}
 
 	// Clone or update repository
 	c.output.Infof("📦 Getting code from repository: %s", repoURL)
 
 	if err := c.filesystem.MkdirAll(".services", 0755); err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	// Verificar se já existe
 	if exists := c.filesystem.Exists(serviceDir); exists {
 		// Fazer pull
 		if err := c.gitClient.Pull(ctx, serviceDir, branch); err != nil {
-			return "", fmt.Errorf("error updating repository: %w", err)
+			return "", false, fmt.Errorf("error updating repository: %w", err)
 		}
 		c.output.Info("✅ Repository updated")
 	} else {
 		// Clonar
 		token := getTokenFromEnv("GITHUB_TOKEN") // Tentar obter token
 		if err := c.gitClient.Clone(ctx, repoURL, serviceDir, token); err != nil {
-			return "", fmt.Errorf("error cloning repository: %w", err)
+			return "", false, fmt.Errorf("error cloning repository: %w", err)
+		}
+		if !c.filesystem.Exists(serviceDir) {
+			return "", false, nil
 		}
 		c.output.Info("✅ Repository cloned")
 	}
 
-	return filepath.Join(serviceDir, path), nil
+	return filepath.Join(serviceDir, path), true, nil
 }
 
 func (c *deployServiceCommand) applyRuntimeOverrides(serviceConfig *config.Stack, envFile, secretsFile string, replicas int) error {
@@ -274,6 +292,10 @@ func (c *deployServiceCommand) deployMicroservice(ctx context.Context, config *c
 		return err
 	}
 
+	if err := writeDynamicConfig(ctx, c.composeService, c.filesystem, config, composePath); err != nil {
+		return err
+	}
+
 	c.output.Infof("📄 Compose gerado: %s", composePath)
 
 	// Deploy
@@ -283,7 +305,7 @@ func (c *deployServiceCommand) deployMicroservice(ctx context.Context, config *c
 		Detach: true,
 	}
 
-	if err := c.dockerService.Deploy(ctx, composePath, deployOptions); err != nil {
+	if err := deployStack(ctx, c.composeService, c.dockerService, c.filesystem, c.output, config, composePath, data, deployOptions); err != nil {
 		return fmt.Errorf("deployment error: %w", err)
 	}
 
@@ -52,7 +52,7 @@ func (c *EditServerCommand) Execute(ctx context.Context, args []string) error {
 	}
 
 	// Load existing configuration
-	stack, err := c.loadConfiguration(filename)
+	stack, err := c.configManager.Load(ctx, filename)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -100,6 +100,7 @@ func (c *EditServerCommand) Execute(ctx context.Context, args []string) error {
 				c.output.Errorf("❌ Error editing advanced settings: %v", err)
 			}
 		case "Save and exit":
+			// Only changed fields are written; comments and formatting are kept
 			if err := c.configManager.SaveBaseConfig(ctx, filename, stack); err != nil {
 				return fmt.Errorf("failed to save configuration: %w", err)
 			}
@@ -114,46 +115,6 @@ func (c *EditServerCommand) Execute(ctx context.Context, args []string) error {
 	}
 }
 
-func (c *EditServerCommand) loadConfiguration(filename string) (*config.Stack, error) {
-	// This is a simplified loader - in a real implementation,
-	// you'd want to properly parse the YAML file
-	// For now, return a basic structure
-	return &config.Stack{
-		Version: 1,
-		Project: "deploy",
-		Domain:  "example.com",
-		TLS: config.TLS{
-			Mode:     "acme",
-			Email:    "admin@example.com",
-			Resolver: "le",
-		},
-		Observability: config.Observability{
-			Dozzle: config.Dozzle{
-				Enabled:    true,
-				Subdomain:  "logs",
-				DataVolume: "dozzle_data",
-			},
-			Beszel: config.Beszel{
-				Enabled:      true,
-				Subdomain:    "monitor",
-				DataVolume:   "beszel_data",
-				SocketVolume: "beszel_socket",
-			},
-		},
-		Networks: map[string]config.Network{
-			"private": {Internal: true},
-			"public":  {Internal: false},
-		},
-		Volumes: []config.Volume{
-			{Name: "traefik_acme"},
-			{Name: "dozzle_data"},
-			{Name: "beszel_data"},
-			{Name: "beszel_socket"},
-		},
-		Services: []config.Service{},
-	}, nil
-}
-
 func (c *EditServerCommand) showCurrentConfig(stack *config.Stack) {
 	c.output.Info("📋 Current Configuration:")
 	c.output.Infof("   Domain: %s", stack.Domain)
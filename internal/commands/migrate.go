@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+	"github.com/leandrodaf/harborctl/pkg/textdiff"
+)
+
+// migrateCommand implementa o comando migrate
+type migrateCommand struct {
+	filesystem fs.FileSystem
+	output     cli.Output
+}
+
+// NewMigrateCommand cria um novo comando migrate
+func NewMigrateCommand(filesystem fs.FileSystem, output cli.Output) cli.Command {
+	return &migrateCommand{
+		filesystem: filesystem,
+		output:     output,
+	}
+}
+
+func (c *migrateCommand) Name() string {
+	return "migrate"
+}
+
+func (c *migrateCommand) Description() string {
+	return "Upgrade stack.yml/server-base.yml to the current schema version"
+}
+
+func (c *migrateCommand) Execute(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+
+	var stackPath string
+	var check bool
+	fs.StringVar(&stackPath, "f", "stack.yml", "file to migrate")
+	fs.BoolVar(&check, "check", false, "only report whether a migration is needed (exit 1 if so)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := c.filesystem.ReadFile(stackPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", stackPath, err)
+	}
+
+	migrated, result, err := config.MigrateFile(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", stackPath, err)
+	}
+
+	if !result.Changed() {
+		c.output.Infof("✅ %s is already at schema version %d", stackPath, result.ToVersion)
+		return nil
+	}
+
+	c.output.Infof("🔄 %s: schema version %d -> %d", stackPath, result.FromVersion, result.ToVersion)
+	for _, m := range result.Applied {
+		c.output.Infof("   • v%d -> v%d: %s", m.From, m.From+1, m.Description)
+	}
+	c.output.Info("")
+	c.output.Info(textdiff.Unified(stackPath, stackPath+" (migrated)", string(data), string(migrated)))
+
+	if check {
+		return fmt.Errorf("%s needs migration", stackPath)
+	}
+
+	if err := c.filesystem.WriteFile(stackPath, migrated, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", stackPath, err)
+	}
+
+	c.output.Infof("✅ %s migrated", stackPath)
+	return nil
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/leandrodaf/harborctl/internal/compose"
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/docker"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+// mirrorCommand implementa o comando mirror, que encerra um shadow deploy
+type mirrorCommand struct {
+	configManager  config.Manager
+	composeService compose.Service
+	dockerService  docker.Service
+	filesystem     fs.FileSystem
+	output         cli.Output
+}
+
+// NewMirrorCommand cria um novo comando mirror
+func NewMirrorCommand(configManager config.Manager, composeService compose.Service, dockerService docker.Service, filesystem fs.FileSystem, output cli.Output) cli.Command {
+	return &mirrorCommand{
+		configManager:  configManager,
+		composeService: composeService,
+		dockerService:  dockerService,
+		filesystem:     filesystem,
+		output:         output,
+	}
+}
+
+func (c *mirrorCommand) Name() string {
+	return "mirror"
+}
+
+func (c *mirrorCommand) Description() string {
+	return "Promote or discard a shadow version (mirror promote|discard <service>)"
+}
+
+func (c *mirrorCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: harborctl mirror <promote|discard> <service> [-f stack.yml]")
+	}
+
+	action := args[0]
+	if action != "promote" && action != "discard" {
+		return fmt.Errorf("unknown mirror action: %s (use promote or discard)", action)
+	}
+
+	fs := flag.NewFlagSet("mirror "+action, flag.ExitOnError)
+
+	var stackPath, env string
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado no deploy")
+
+	name, err := parseNamedArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("specify the service: harborctl mirror %s <service>", action)
+	}
+
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
+	if err != nil {
+		return err
+	}
+	service := findService(stack, name)
+	if service == nil {
+		return fmt.Errorf("service %s not found in %s", name, stackPath)
+	}
+	if service.Mirror == nil {
+		return fmt.Errorf("service %s has no mirror", name)
+	}
+	if action == "promote" && service.Build != nil {
+		return fmt.Errorf("service %s is built from source, update its build and remove the mirror instead", name)
+	}
+
+	// O stack.yml passa a descrever só a versão que fica, preservando comentários
+	servicePath := fmt.Sprintf("services[name=%s]", name)
+	err = c.configManager.Edit(ctx, stackPath, func(doc *config.Document) error {
+		if action == "promote" {
+			if err := doc.Set(servicePath+".image", service.Mirror.Image); err != nil {
+				return err
+			}
+		}
+		if !doc.Delete(servicePath + ".mirror") {
+			return fmt.Errorf("mirror of %s is not defined in %s (check the environment overlay)", name, stackPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", stackPath, err)
+	}
+	if action == "promote" {
+		c.output.Infof("⬆️  %s now runs %s", name, service.Mirror.Image)
+	}
+
+	// Novo deploy sem o mirroring; o shadow sai do compose e é removido por nome
+	up := NewUpCommand(c.configManager, c.composeService, c.dockerService, c.filesystem, c.output)
+	if err := up.Execute(ctx, []string{"-f", stackPath, "--env", env}); err != nil {
+		return err
+	}
+
+	shadow := compose.ShadowService(name)
+	if err := c.dockerService.RemoveContainers(ctx, []string{shadow}); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", shadow, err)
+	}
+
+	c.output.Infof("✅ Shadow of %s removed", name)
+	return nil
+}
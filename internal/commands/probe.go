@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/probe"
+)
+
+// probeCommand implementa o comando probe, executado dentro dos containers pelo health check
+// com 'tool: probe'. Falha com código diferente de zero quando o serviço não responde
+type probeCommand struct {
+	output cli.Output
+}
+
+// NewProbeCommand cria um novo comando probe
+func NewProbeCommand(output cli.Output) cli.Command {
+	return &probeCommand{output: output}
+}
+
+func (c *probeCommand) Name() string {
+	return "probe"
+}
+
+func (c *probeCommand) Description() string {
+	return "Health probe run inside containers (http|tcp|grpc)"
+}
+
+func (c *probeCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: harborctl probe <http|tcp|grpc> [flags] <address>")
+	}
+
+	kind := args[0]
+	fs := flag.NewFlagSet("probe "+kind, flag.ExitOnError)
+
+	var timeout time.Duration
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "tempo máximo da verificação")
+
+	switch kind {
+	case "http":
+		opts := probe.HTTPOptions{Headers: make(map[string]string)}
+		fs.StringVar(&opts.Method, "method", "GET", "método HTTP")
+		fs.IntVar(&opts.Status, "status", 0, "status esperado (padrão: qualquer 2xx/3xx)")
+		fs.Func("header", "header 'Nome: valor' (repetível)", func(value string) error {
+			name, content, ok := strings.Cut(value, ":")
+			if !ok {
+				return fmt.Errorf("invalid header %q (use 'Name: value')", value)
+			}
+			opts.Headers[strings.TrimSpace(name)] = strings.TrimSpace(content)
+			return nil
+		})
+		url, err := parseNamedArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if url == "" {
+			return fmt.Errorf("specify the url: harborctl probe http http://localhost:8080/health")
+		}
+		opts.URL, opts.Timeout = url, timeout
+		return probe.HTTP(ctx, opts)
+
+	case "tcp":
+		address, err := parseNamedArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if address == "" {
+			return fmt.Errorf("specify the address: harborctl probe tcp localhost:5432")
+		}
+		return probe.TCP(ctx, address, timeout)
+
+	case "grpc":
+		var service string
+		fs.StringVar(&service, "service", "", "serviço consultado no grpc.health.v1 (padrão: o servidor)")
+		address, err := parseNamedArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if address == "" {
+			return fmt.Errorf("specify the address: harborctl probe grpc localhost:50051")
+		}
+		return probe.GRPC(ctx, address, service, timeout)
+	}
+
+	return fmt.Errorf("unknown probe type: %s (use http, tcp or grpc)", kind)
+}
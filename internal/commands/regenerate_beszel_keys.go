@@ -92,12 +92,14 @@ func (c *RegenerateBeszelKeysCommand) Execute(ctx context.Context, args []string
 		return fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	// Update configuration
-	stack.Observability.Beszel.PublicKey = pubKey
-	stack.Observability.Beszel.Token = token
-
-	// Save updated configuration
-	if err := c.configManager.SaveBaseConfig(ctx, configFile, stack); err != nil {
+	// Update only the key fields, keeping comments and formatting
+	err = c.configManager.Edit(ctx, configFile, func(doc *config.Document) error {
+		if err := doc.Set("observability.beszel.public_key", pubKey); err != nil {
+			return err
+		}
+		return doc.Set("observability.beszel.token", token)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
@@ -19,9 +19,9 @@ type RemoteControlCommand struct {
 }
 
 // NewRemoteControlCommand creates a new remote control command
-func NewRemoteControlCommand(output cli.Output) cli.Command {
+func NewRemoteControlCommand(sshExecutor ssh.Executor, output cli.Output) cli.Command {
 	return &RemoteControlCommand{
-		sshExecutor:     ssh.NewExecutor(),
+		sshExecutor:     sshExecutor,
 		commandBuilder:  ssh.NewCommandBuilder(),
 		validator:       validation.NewValidator(),
 		actionValidator: validation.NewActionValidator(),
@@ -18,9 +18,9 @@ type RemoteLogsCommand struct {
 }
 
 // NewRemoteLogsCommand creates a new remote logs command
-func NewRemoteLogsCommand(output cli.Output) cli.Command {
+func NewRemoteLogsCommand(sshExecutor ssh.Executor, output cli.Output) cli.Command {
 	return &RemoteLogsCommand{
-		sshExecutor:    ssh.NewExecutor(),
+		sshExecutor:    sshExecutor,
 		commandBuilder: ssh.NewCommandBuilder(),
 		validator:      validation.NewValidator(),
 		output:         output,
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"flag"
+	"path/filepath"
 
 	"github.com/leandrodaf/harborctl/internal/compose"
 	"github.com/leandrodaf/harborctl/internal/config"
@@ -44,10 +45,11 @@ func (c *renderCommand) Description() string {
 func (c *renderCommand) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("render", flag.ExitOnError)
 
-	var stackPath, outputPath string
+	var stackPath, outputPath, env string
 	var noDozzle, noBeszel bool
 
 	fs.StringVar(&stackPath, "f", "stack.yml", "stack.yml")
+	fs.StringVar(&env, "env", "", "environment overlay (stack.<env>.yml)")
 	fs.StringVar(&outputPath, "o", ".deploy/compose.generated.yml", "output compose")
 	fs.BoolVar(&noDozzle, "no-dozzle", false, "don't include dozzle")
 	fs.BoolVar(&noBeszel, "no-beszel", false, "don't include beszel")
@@ -57,7 +59,7 @@ func (c *renderCommand) Execute(ctx context.Context, args []string) error {
 	}
 
 	// Carregar e validar configuração
-	stack, err := c.configManager.Load(ctx, stackPath)
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
 	if err != nil {
 		return err
 	}
@@ -87,6 +89,33 @@ func (c *renderCommand) Execute(ctx context.Context, args []string) error {
 		return err
 	}
 
+	if err := writeDynamicConfig(ctx, c.composeService, c.filesystem, stack, outputPath); err != nil {
+		return err
+	}
+
 	c.output.Infof("compose generated at %s", outputPath)
 	return nil
 }
+
+// writeDynamicConfig grava a configuração dinâmica do Traefik no diretório do compose,
+// de onde o volume relativo do container traefik a monta, junto dos certificados da CA local
+func writeDynamicConfig(ctx context.Context, composeService compose.Service, filesystem fs.FileSystem, stack *config.Stack, composePath string) error {
+	dir := filepath.Dir(composePath)
+	state, err := loadRolloutState(filesystem, dir)
+	if err != nil {
+		return err
+	}
+	if err := writeSelfSignedCertificates(ctx, composeService, filesystem, stack, dir); err != nil {
+		return err
+	}
+	return writeDynamicFile(ctx, composeService, filesystem, stack, dir, state)
+}
+
+// writeDynamicFile gera e grava o arquivo dinâmico com a divisão de tráfego atual dos rollouts
+func writeDynamicFile(ctx context.Context, composeService compose.Service, filesystem fs.FileSystem, stack *config.Stack, dir string, state compose.RolloutState) error {
+	data, err := composeService.GenerateDynamic(ctx, stack, state)
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteFile(filepath.Join(dir, compose.DynamicConfigFile), data, 0644)
+}
@@ -6,18 +6,21 @@ import (
 
 	"github.com/leandrodaf/harborctl/pkg/cli"
 	"github.com/leandrodaf/harborctl/pkg/docker"
+	"github.com/leandrodaf/harborctl/pkg/fs"
 )
 
 // restartCommand implementa o comando restart
 type restartCommand struct {
 	dockerService docker.Service
+	filesystem    fs.FileSystem
 	output        cli.Output
 }
 
 // NewRestartCommand cria um novo comando restart
-func NewRestartCommand(dockerService docker.Service, output cli.Output) cli.Command {
+func NewRestartCommand(dockerService docker.Service, filesystem fs.FileSystem, output cli.Output) cli.Command {
 	return &restartCommand{
 		dockerService: dockerService,
+		filesystem:    filesystem,
 		output:        output,
 	}
 }
@@ -42,6 +45,16 @@ func (c *restartCommand) Execute(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// docker compose restart segue a ordem do depends_on mas não espera as condições;
+	// stop e start param na ordem inversa e sobem esperando healthy e completed_successfully
+	if data, err := c.filesystem.ReadFile(outputPath); err == nil && composeHasDependencies(data) {
+		c.output.Info("🔄 Reiniciando serviços na ordem das dependências...")
+		if err := c.dockerService.Stop(ctx, outputPath, timeout); err != nil {
+			return err
+		}
+		return c.dockerService.Start(ctx, outputPath)
+	}
+
 	c.output.Info("🔄 Reiniciando serviços...")
 	return c.dockerService.Restart(ctx, outputPath, timeout)
 }
@@ -0,0 +1,540 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leandrodaf/harborctl/internal/compose"
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/docker"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+const (
+	defaultHealthTimeout = 5 * time.Minute
+	defaultDrain         = 10 * time.Second
+	defaultDelay         = 10 * time.Second
+
+	// traefikSettle dá tempo ao provider docker do Traefik para registrar a nova cor
+	// antes que o arquivo dinâmico passe a citá-la
+	traefikSettle = 5 * time.Second
+)
+
+// defaultCanarySteps são usados quando o canary não declara steps
+var defaultCanarySteps = []config.CanaryStep{
+	{Weight: 10, Duration: "1m"},
+	{Weight: 50, Duration: "1m"},
+}
+
+// deployStack sobe o compose gerado: os serviços comuns com docker compose up, os com réplicas
+// em lotes (rolling) e os blue-green e canary com um rollout passo a passo entre as duas cores.
+// Os serviços seguem a ordem do depends_on: cada rollout espera as condições das suas
+// dependências e os serviços comuns que dependem dele só sobem depois
+func deployStack(ctx context.Context, composeService compose.Service, dockerService docker.Service, filesystem fs.FileSystem, output cli.Output, stack *config.Stack, composePath string, data []byte, options docker.DeployOptions) error {
+	managed := compose.ColorServices(stack)
+	for _, service := range stack.Services {
+		if service.RollingUpdate() {
+			managed[service.Name] = true
+		}
+	}
+	if len(managed) == 0 {
+		// Sem rollouts o próprio compose respeita o depends_on
+		return dockerService.Deploy(ctx, composePath, options)
+	}
+
+	names, err := composeServiceNames(data)
+	if err != nil {
+		return err
+	}
+	order, err := stack.DependencyOrder()
+	if err != nil {
+		return err
+	}
+
+	// Serviços do compose de cada serviço do stack (cores e shadow); os demais (traefik,
+	// observabilidade) sobem no primeiro lote
+	owner := make(map[string]string, len(names))
+	for _, service := range stack.Services {
+		owner[service.Name] = service.Name
+		owner[compose.ColorService(service.Name, compose.ColorBlue)] = service.Name
+		owner[compose.ColorService(service.Name, compose.ColorGreen)] = service.Name
+		owner[compose.ShadowService(service.Name)] = service.Name
+	}
+	owned := make(map[string][]string)
+	var batch []string
+	for _, name := range names {
+		if service, ok := owner[name]; ok {
+			owned[service] = append(owned[service], name)
+		} else {
+			batch = append(batch, name)
+		}
+	}
+
+	dir := filepath.Dir(composePath)
+	state, err := loadRolloutState(filesystem, dir)
+	if err != nil {
+		return err
+	}
+	r := &rollout{
+		composeService: composeService,
+		dockerService:  dockerService,
+		filesystem:     filesystem,
+		output:         output,
+		stack:          stack,
+		composePath:    composePath,
+		dir:            dir,
+		state:          state,
+		options:        docker.DeployOptions{Build: options.Build, Detach: options.Detach},
+	}
+
+	// O primeiro lote leva as opções completas (limpeza de imagens); os seguintes não
+	batchOptions := options
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := dockerService.DeployServices(ctx, composePath, batchOptions, batch)
+		batch, batchOptions = nil, r.options
+		return err
+	}
+
+	// A cada rodada sobem todos os serviços comuns já liberados pelas dependências e então
+	// o próximo rollout liberado; sem depends_on, os comuns sobem antes dos rollouts
+	deployed := make(map[string]bool, len(order))
+	ready := func(service *config.Service) bool {
+		for _, dep := range service.DependsOn {
+			if !deployed[dep.Service] && findService(stack, dep.Service) != nil {
+				return false
+			}
+		}
+		return true
+	}
+	for len(deployed) < len(order) {
+		for _, name := range order {
+			service := findService(stack, name)
+			if !deployed[name] && !service.Progressive() && !service.RollingUpdate() && ready(service) {
+				batch = append(batch, owned[name]...)
+				deployed[name] = true
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+
+		for _, name := range order {
+			service := findService(stack, name)
+			if deployed[name] || !ready(service) {
+				continue
+			}
+			if err := r.waitDependencies(ctx, *service); err != nil {
+				return err
+			}
+			if service.Progressive() {
+				err = r.run(ctx, *service)
+			} else {
+				err = r.rolling(ctx, *service)
+			}
+			if err != nil {
+				return err
+			}
+			deployed[name] = true
+			for _, composeName := range owned[name] {
+				if !managed[composeName] {
+					batch = append(batch, composeName)
+				}
+			}
+			break
+		}
+	}
+	return flush()
+}
+
+// waitDependencies espera as condições do depends_on antes de um rollout, que sobe os
+// containers com --no-deps e por isso não passa pela espera do compose
+func (r *rollout) waitDependencies(ctx context.Context, service config.Service) error {
+	timeout := defaultHealthTimeout
+	if service.Deploy != nil {
+		timeout = durationOr(service.Deploy.HealthTimeout, defaultHealthTimeout)
+	}
+
+	for _, dep := range service.DependsOn {
+		var err error
+		switch dep.DependencyCondition() {
+		case config.ConditionHealthy:
+			r.output.Infof("⏳ %s: waiting for %s to become healthy", service.Name, dep.Service)
+			err = r.dockerService.WaitHealthy(ctx, r.composePath, dep.Service, nil, timeout)
+		case config.ConditionCompletedSuccessfully:
+			r.output.Infof("⏳ %s: waiting for %s to complete", service.Name, dep.Service)
+			err = r.dockerService.WaitCompleted(ctx, r.composePath, dep.Service, timeout)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: dependency %s: %w", service.Name, dep.Service, err)
+		}
+	}
+	return nil
+}
+
+// rollout troca a versão de um serviço entre as cores, movendo o tráfego no arquivo dinâmico
+type rollout struct {
+	composeService compose.Service
+	dockerService  docker.Service
+	filesystem     fs.FileSystem
+	output         cli.Output
+	stack          *config.Stack
+	composePath    string
+	dir            string
+	state          compose.RolloutState
+	options        docker.DeployOptions
+}
+
+// run implanta a nova versão na cor livre, espera ficar saudável, move o tráfego (de uma vez
+// no blue-green, pelos steps no canary) e aposenta a cor antiga
+func (r *rollout) run(ctx context.Context, service config.Service) error {
+	deploy := service.Deploy
+	traffic := r.state.Traffic(service.Name)
+	stable := compose.ColorService(service.Name, traffic.Stable)
+	next := compose.ColorService(service.Name, traffic.Next())
+	healthTimeout := durationOr(deploy.HealthTimeout, defaultHealthTimeout)
+
+	containers, err := r.dockerService.Containers(ctx, r.composePath, stable)
+	if err != nil {
+		return fmt.Errorf("%s: %w", service.Name, err)
+	}
+	if !anyRunning(containers) {
+		// Primeiro deploy: não há tráfego a preservar, a cor estável sobe direto
+		r.output.Infof("🚀 %s: first %s deploy, starting %s", service.Name, deploy.Strategy, traffic.Stable)
+		if err := r.dockerService.DeployServices(ctx, r.composePath, r.options, []string{stable}); err != nil {
+			return fmt.Errorf("%s: %w", service.Name, err)
+		}
+		if err := r.dockerService.WaitHealthy(ctx, r.composePath, stable, nil, healthTimeout); err != nil {
+			return fmt.Errorf("%s: %w", service.Name, err)
+		}
+		if err := r.setTraffic(ctx, service.Name, compose.Traffic{Stable: traffic.Stable}); err != nil {
+			return err
+		}
+		r.output.Infof("✅ %s: %s is live", service.Name, traffic.Stable)
+		return nil
+	}
+
+	r.output.Infof("🚀 %s: %s deploy, starting %s next to %s", service.Name, deploy.Strategy, traffic.Next(), traffic.Stable)
+	if err := r.dockerService.DeployServices(ctx, r.composePath, r.options, []string{next}); err != nil {
+		return r.abort(ctx, service.Name, traffic, err)
+	}
+	r.output.Infof("⏳ %s: waiting for %s to become healthy (timeout %s)", service.Name, traffic.Next(), healthTimeout)
+	if err := r.dockerService.WaitHealthy(ctx, r.composePath, next, nil, healthTimeout); err != nil {
+		return r.abort(ctx, service.Name, traffic, err)
+	}
+	if err := r.dockerService.Wait(ctx, traefikSettle); err != nil {
+		return r.abort(ctx, service.Name, traffic, err)
+	}
+
+	if deploy.Strategy == "canary" {
+		steps := deploy.Steps
+		if len(steps) == 0 {
+			steps = defaultCanarySteps
+		}
+		for i, step := range steps {
+			r.output.Infof("🐤 %s: step %d/%d, %d%% of traffic to %s for %s", service.Name, i+1, len(steps), step.Weight, traffic.Next(), step.Duration)
+			if err := r.setTraffic(ctx, service.Name, compose.Traffic{Stable: traffic.Stable, Canary: step.Weight}); err != nil {
+				return r.abort(ctx, service.Name, traffic, err)
+			}
+			if err := r.dockerService.Wait(ctx, durationOr(step.Duration, time.Minute)); err != nil {
+				return r.abort(ctx, service.Name, traffic, err)
+			}
+			containers, err := r.dockerService.Containers(ctx, r.composePath, next)
+			if err == nil {
+				if unhealthy := firstUnhealthy(containers); unhealthy != "" {
+					err = fmt.Errorf("%s during step %d", unhealthy, i+1)
+				}
+			}
+			if err != nil {
+				return r.abort(ctx, service.Name, traffic, err)
+			}
+		}
+	}
+
+	r.output.Infof("🔀 %s: switching all traffic to %s", service.Name, traffic.Next())
+	if err := r.setTraffic(ctx, service.Name, compose.Traffic{Stable: traffic.Next()}); err != nil {
+		return r.abort(ctx, service.Name, traffic, err)
+	}
+
+	// A partir daqui a nova versão é a estável; falhas só deixam a cor antiga rodando sem tráfego
+	drain := durationOr(deploy.Drain, defaultDrain)
+	r.output.Infof("💤 %s: draining %s for %s", service.Name, traffic.Stable, drain)
+	if err := r.dockerService.Wait(ctx, drain); err != nil {
+		return fmt.Errorf("%s: %s is live but %s was not retired: %w", service.Name, traffic.Next(), traffic.Stable, err)
+	}
+	if err := r.dockerService.RemoveServices(ctx, r.composePath, []string{stable}); err != nil {
+		return fmt.Errorf("%s: %s is live but %s was not retired: %w", service.Name, traffic.Next(), traffic.Stable, err)
+	}
+	r.output.Infof("✅ %s: %s is live, %s retired", service.Name, traffic.Next(), traffic.Stable)
+	return nil
+}
+
+// rolling troca os containers de um serviço com réplicas em lotes de parallelism: cada lote novo sobe
+// ao lado dos antigos, fica saudável e é registrado no Traefik antes do próximo. Os antigos só param
+// quando todos os lotes passaram, então uma falha em qualquer lote remove os containers novos e o
+// serviço volta inteiro à versão anterior, sem mistura de versões
+func (r *rollout) rolling(ctx context.Context, service config.Service) error {
+	var deploy config.DeployConfig
+	if service.Deploy != nil {
+		deploy = *service.Deploy
+	}
+	healthTimeout := durationOr(deploy.HealthTimeout, defaultHealthTimeout)
+	delay := durationOr(deploy.Delay, defaultDelay)
+	parallelism := deploy.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	current, err := r.dockerService.Containers(ctx, r.composePath, service.Name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", service.Name, err)
+	}
+	var old []docker.Container
+	for _, container := range current {
+		if container.Running() {
+			old = append(old, container)
+		}
+	}
+	if len(old) == 0 {
+		// Primeiro deploy: não há containers a preservar
+		r.output.Infof("🚀 %s: starting %d replicas", service.Name, service.Replicas)
+		if err := r.dockerService.DeployServices(ctx, r.composePath, r.options, []string{service.Name}); err != nil {
+			return fmt.Errorf("%s: %w", service.Name, err)
+		}
+		return r.dockerService.WaitHealthy(ctx, r.composePath, service.Name, nil, healthTimeout)
+	}
+
+	// Configuração inalterada: só ajusta o número de réplicas, sem recriar (imagens construídas sempre mudam)
+	if service.Build == nil {
+		hash, err := r.dockerService.ConfigHash(ctx, r.composePath, service.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", service.Name, err)
+		}
+		if hash != "" && sameConfig(old, hash) {
+			return r.dockerService.ScaleService(ctx, r.composePath, service.Name, service.Replicas, false)
+		}
+	}
+
+	batches := (len(old) + parallelism - 1) / parallelism
+	r.output.Infof("🔄 %s: rolling update of %d containers, %d at a time", service.Name, len(old), parallelism)
+
+	previous := make([]string, 0, len(old))
+	for _, container := range old {
+		previous = append(previous, container.ID)
+	}
+	// known reúne os antigos e os novos já verificados; WaitHealthy só olha o lote atual
+	known := append([]string(nil), previous...)
+	started := 0
+	for i := 0; i < batches; i++ {
+		batch := old[i*parallelism : min((i+1)*parallelism, len(old))]
+		names := make([]string, len(batch))
+		for j, container := range batch {
+			names[j] = container.Name
+		}
+
+		r.output.Infof("🔄 %s: batch %d/%d, replacing %s", service.Name, i+1, batches, strings.Join(names, ", "))
+		build := r.options.Build && i == 0
+		if err := r.dockerService.ScaleService(ctx, r.composePath, service.Name, len(old)+started+len(batch), build); err != nil {
+			return r.abortRolling(ctx, service.Name, previous, i, batches, err)
+		}
+		if err := r.dockerService.WaitHealthy(ctx, r.composePath, service.Name, known, healthTimeout); err != nil {
+			return r.abortRolling(ctx, service.Name, previous, i, batches, err)
+		}
+		if traefik := service.GetTraefik(); traefik != nil && traefik.Enabled {
+			if err := r.dockerService.Wait(ctx, traefikSettle); err != nil {
+				return r.abortRolling(ctx, service.Name, previous, i, batches, err)
+			}
+		}
+		started += len(batch)
+
+		// Os containers novos passam a ser conhecidos; os do próximo lote serão os seguintes
+		current, err := r.dockerService.Containers(ctx, r.composePath, service.Name)
+		if err != nil {
+			return r.abortRolling(ctx, service.Name, previous, i, batches, err)
+		}
+		known = known[:0]
+		for _, container := range current {
+			known = append(known, container.ID)
+		}
+
+		if i < batches-1 {
+			if err := r.dockerService.Wait(ctx, delay); err != nil {
+				return r.abortRolling(ctx, service.Name, previous, i, batches, err)
+			}
+		}
+	}
+
+	if err := r.dockerService.RemoveContainers(ctx, previous); err != nil {
+		return fmt.Errorf("%s: all batches are healthy but the old containers were not stopped: %w", service.Name, err)
+	}
+
+	// Ajusta ao número de réplicas declarado (que pode ter mudado junto com a versão)
+	if err := r.dockerService.ScaleService(ctx, r.composePath, service.Name, service.Replicas, false); err != nil {
+		return fmt.Errorf("%s: %w", service.Name, err)
+	}
+	r.output.Infof("✅ %s: %d replicas updated", service.Name, service.Replicas)
+	return nil
+}
+
+// abortRolling remove os containers novos de todos os lotes, mesmo com o contexto cancelado (Ctrl-C);
+// previous são os containers da versão anterior, que seguem atendendo
+func (r *rollout) abortRolling(ctx context.Context, name string, previous []string, batch, batches int, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	r.output.Errorf("↩️  %s: %v at batch %d/%d, removing the new containers", name, cause, batch+1, batches)
+
+	skip := make(map[string]bool, len(previous))
+	for _, id := range previous {
+		skip[id] = true
+	}
+	containers, err := r.dockerService.Containers(ctx, r.composePath, name)
+	if err != nil {
+		return fmt.Errorf("%s: rollback failed: %w (deploy error: %v)", name, err, cause)
+	}
+	var failed []string
+	for _, container := range containers {
+		if !skip[container.ID] {
+			failed = append(failed, container.ID)
+		}
+	}
+	if len(failed) > 0 {
+		if err := r.dockerService.RemoveContainers(ctx, failed); err != nil {
+			return fmt.Errorf("%s: rollback failed: %w (deploy error: %v)", name, err, cause)
+		}
+	}
+	return fmt.Errorf("%s: rolling update aborted at batch %d/%d, all containers keep the previous version: %w", name, batch+1, batches, cause)
+}
+
+// sameConfig indica se todos os containers já rodam a configuração atual do serviço
+func sameConfig(containers []docker.Container, hash string) bool {
+	for _, container := range containers {
+		if container.Label(docker.ConfigHashLabel) != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// abort devolve todo o tráfego à cor estável e remove a nova, mesmo com o contexto cancelado (Ctrl-C)
+func (r *rollout) abort(ctx context.Context, name string, traffic compose.Traffic, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	r.output.Errorf("↩️  %s: %v, rolling back to %s", name, cause, traffic.Stable)
+
+	if err := r.setTraffic(ctx, name, compose.Traffic{Stable: traffic.Stable}); err != nil {
+		return fmt.Errorf("%s: rollback failed: %w (deploy error: %v)", name, err, cause)
+	}
+	if err := r.dockerService.RemoveServices(ctx, r.composePath, []string{compose.ColorService(name, traffic.Next())}); err != nil {
+		return fmt.Errorf("%s: traffic restored to %s but %s was not removed: %w (deploy error: %v)", name, traffic.Stable, traffic.Next(), err, cause)
+	}
+	return fmt.Errorf("%s: deploy aborted, %s still serves all traffic: %w", name, traffic.Stable, cause)
+}
+
+// setTraffic grava a nova divisão e regera o arquivo dinâmico, que o Traefik recarrega sozinho
+func (r *rollout) setTraffic(ctx context.Context, name string, traffic compose.Traffic) error {
+	r.state[name] = traffic
+	if err := saveRolloutState(r.filesystem, r.dir, r.state); err != nil {
+		return err
+	}
+	return writeDynamicFile(ctx, r.composeService, r.filesystem, r.stack, r.dir, r.state)
+}
+
+// loadRolloutState lê o estado dos rollouts do diretório do compose (vazio antes do primeiro deploy)
+func loadRolloutState(filesystem fs.FileSystem, dir string) (compose.RolloutState, error) {
+	state := make(compose.RolloutState)
+	path := filepath.Join(dir, compose.RolloutStateFile)
+	if !filesystem.Exists(path) {
+		return state, nil
+	}
+	data, err := filesystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveRolloutState(filesystem fs.FileSystem, dir string, state compose.RolloutState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteFile(filepath.Join(dir, compose.RolloutStateFile), append(data, '\n'), 0644)
+}
+
+// composeServiceNames lista os serviços do compose gerado
+func composeServiceNames(data []byte) ([]string, error) {
+	var file struct {
+		Services map[string]any `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to read generated compose: %w", err)
+	}
+	names := make([]string, 0, len(file.Services))
+	for name := range file.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// composeHasDependencies indica se algum serviço do compose gerado declara depends_on
+func composeHasDependencies(data []byte) bool {
+	var file struct {
+		Services map[string]struct {
+			DependsOn map[string]any `yaml:"depends_on"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return false
+	}
+	for _, service := range file.Services {
+		if len(service.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func durationOr(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+func anyRunning(containers []docker.Container) bool {
+	for _, container := range containers {
+		if container.Running() {
+			return true
+		}
+	}
+	return false
+}
+
+// firstUnhealthy descreve o primeiro container que não está saudável ("" se todos estão)
+func firstUnhealthy(containers []docker.Container) string {
+	if len(containers) == 0 {
+		return "no container running"
+	}
+	for _, container := range containers {
+		switch {
+		case container.Healthy():
+		case container.Health != "":
+			return fmt.Sprintf("%s became %s", container.Name, container.Health)
+		default:
+			return fmt.Sprintf("%s became %s", container.Name, container.State)
+		}
+	}
+	return ""
+}
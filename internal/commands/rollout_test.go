@@ -0,0 +1,586 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leandrodaf/harborctl/internal/compose"
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/docker"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+// fakeDocker simula os containers de cada serviço do compose em memória
+type fakeDocker struct {
+	containers map[string][]docker.Container
+	hash       string // config hash atual; containers novos nascem com ele
+	nextID     int
+
+	healthChecks  int
+	unhealthyAt   int // chamada de WaitHealthy que falha (0: nunca)
+	waits         []time.Duration
+	onWait        func(call int) // chamado a cada Wait, com a contagem (1-based)
+	scales        []int
+	removed       [][]string
+	deployed      []string
+	removedGroups []string
+	events        []string // up, scale e healthy na ordem das chamadas
+}
+
+func newFakeDocker(hash string) *fakeDocker {
+	return &fakeDocker{containers: make(map[string][]docker.Container), hash: hash}
+}
+
+// add cria containers rodando e saudáveis com o hash informado
+func (f *fakeDocker) add(service string, n int, hash string) {
+	for i := 0; i < n; i++ {
+		f.nextID++
+		id := fmt.Sprintf("%s-%d", service, f.nextID)
+		f.containers[service] = append(f.containers[service], docker.Container{
+			ID:     id,
+			Name:   id,
+			State:  "running",
+			Labels: docker.ConfigHashLabel + "=" + hash,
+		})
+	}
+}
+
+func (f *fakeDocker) ids(service string) []string {
+	var ids []string
+	for _, container := range f.containers[service] {
+		ids = append(ids, container.ID)
+	}
+	return ids
+}
+
+func (f *fakeDocker) Deploy(ctx context.Context, composePath string, options docker.DeployOptions) error {
+	return nil
+}
+func (f *fakeDocker) Teardown(ctx context.Context, composePath string) error { return nil }
+func (f *fakeDocker) Stop(ctx context.Context, composePath string, timeout int) error {
+	return nil
+}
+func (f *fakeDocker) Start(ctx context.Context, composePath string) error { return nil }
+func (f *fakeDocker) Restart(ctx context.Context, composePath string, timeout int) error {
+	return nil
+}
+func (f *fakeDocker) Pause(ctx context.Context, composePath string) error   { return nil }
+func (f *fakeDocker) Unpause(ctx context.Context, composePath string) error { return nil }
+func (f *fakeDocker) Cleanup(ctx context.Context, options docker.CleanupOptions) error {
+	return nil
+}
+func (f *fakeDocker) ReadFile(ctx context.Context, composePath, service, path string) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeDocker) DeployServices(ctx context.Context, composePath string, options docker.DeployOptions, services []string) error {
+	f.events = append(f.events, "up "+strings.Join(services, ","))
+	for _, service := range services {
+		f.deployed = append(f.deployed, service)
+		if len(f.containers[service]) == 0 {
+			f.add(service, 1, f.hash)
+		}
+	}
+	return nil
+}
+
+func (f *fakeDocker) RemoveServices(ctx context.Context, composePath string, services []string) error {
+	for _, service := range services {
+		f.removedGroups = append(f.removedGroups, service)
+		delete(f.containers, service)
+	}
+	return nil
+}
+
+func (f *fakeDocker) ScaleService(ctx context.Context, composePath string, service string, replicas int, build bool) error {
+	f.scales = append(f.scales, replicas)
+	f.events = append(f.events, fmt.Sprintf("scale %s %d", service, replicas))
+	if current := len(f.containers[service]); replicas > current {
+		f.add(service, replicas-current, f.hash)
+	} else {
+		f.containers[service] = f.containers[service][:replicas]
+	}
+	return nil
+}
+
+func (f *fakeDocker) RemoveContainers(ctx context.Context, ids []string) error {
+	f.removed = append(f.removed, ids)
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+	for service, containers := range f.containers {
+		var kept []docker.Container
+		for _, container := range containers {
+			if !drop[container.ID] {
+				kept = append(kept, container)
+			}
+		}
+		f.containers[service] = kept
+	}
+	return nil
+}
+
+func (f *fakeDocker) Containers(ctx context.Context, composePath string, service string) ([]docker.Container, error) {
+	return append([]docker.Container(nil), f.containers[service]...), nil
+}
+
+func (f *fakeDocker) ConfigHash(ctx context.Context, composePath string, service string) (string, error) {
+	return f.hash, nil
+}
+
+func (f *fakeDocker) WaitHealthy(ctx context.Context, composePath string, service string, exclude []string, timeout time.Duration) error {
+	f.healthChecks++
+	f.events = append(f.events, "healthy "+service)
+	if f.healthChecks == f.unhealthyAt {
+		return errors.New("container became unhealthy")
+	}
+	return nil
+}
+
+func (f *fakeDocker) WaitCompleted(ctx context.Context, composePath string, service string, timeout time.Duration) error {
+	return nil
+}
+
+func (f *fakeDocker) Wait(ctx context.Context, d time.Duration) error {
+	f.waits = append(f.waits, d)
+	if f.onWait != nil {
+		f.onWait(len(f.waits))
+	}
+	return nil
+}
+
+// discardOutput descarta as mensagens do rollout
+type discardOutput struct{}
+
+func (discardOutput) Info(msg string)                           {}
+func (discardOutput) Error(msg string)                          {}
+func (discardOutput) Infof(format string, args ...interface{})  {}
+func (discardOutput) Errorf(format string, args ...interface{}) {}
+
+func newTestRollout(t *testing.T, dockerService docker.Service, service config.Service, state compose.RolloutState) *rollout {
+	t.Helper()
+	dir := t.TempDir()
+	if state == nil {
+		state = make(compose.RolloutState)
+	}
+	return &rollout{
+		composeService: compose.NewDefaultService(),
+		dockerService:  dockerService,
+		filesystem:     fs.NewFileSystem(),
+		output:         discardOutput{},
+		stack: &config.Stack{
+			Project:  "demo",
+			Domain:   "example.com",
+			TLS:      config.TLS{Mode: "acme", Email: "ops@example.com"},
+			Services: []config.Service{service},
+		},
+		composePath: filepath.Join(dir, "compose.generated.yml"),
+		dir:         dir,
+		state:       state,
+	}
+}
+
+func rollingService(replicas, parallelism int) config.Service {
+	return config.Service{
+		Name:     "api",
+		Image:    "api:2",
+		Replicas: replicas,
+		Deploy:   &config.DeployConfig{Parallelism: parallelism},
+	}
+}
+
+func TestRollingReplacesAllContainers(t *testing.T) {
+	fake := newFakeDocker("new")
+	fake.add("api", 2, "old")
+	r := newTestRollout(t, fake, rollingService(3, 1), nil)
+
+	if err := r.rolling(context.Background(), rollingService(3, 1)); err != nil {
+		t.Fatalf("rolling() error = %v", err)
+	}
+
+	// Um container a mais por lote ao lado dos antigos, depois o número declarado
+	if want := []int{3, 4, 3}; !reflect.DeepEqual(fake.scales, want) {
+		t.Fatalf("scales = %v, want %v", fake.scales, want)
+	}
+	// Os antigos só saem depois do último lote
+	if want := [][]string{{"api-1", "api-2"}}; !reflect.DeepEqual(fake.removed, want) {
+		t.Fatalf("removed = %v, want %v", fake.removed, want)
+	}
+	containers := fake.containers["api"]
+	if len(containers) != 3 {
+		t.Fatalf("containers = %v, want 3 replicas", fake.ids("api"))
+	}
+	for _, container := range containers {
+		if container.Label(docker.ConfigHashLabel) != "new" {
+			t.Fatalf("container %s still runs the old config", container.ID)
+		}
+	}
+	// Só o intervalo entre os dois lotes
+	if want := []time.Duration{defaultDelay}; !reflect.DeepEqual(fake.waits, want) {
+		t.Fatalf("waits = %v, want %v", fake.waits, want)
+	}
+}
+
+func TestRollingFinalScaleMatchesReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      int
+		replicas int
+	}{
+		{"scale up", 2, 4},
+		{"scale down", 4, 2},
+		{"same", 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDocker("new")
+			fake.add("api", tt.old, "old")
+			service := rollingService(tt.replicas, 2)
+			r := newTestRollout(t, fake, service, nil)
+
+			if err := r.rolling(context.Background(), service); err != nil {
+				t.Fatalf("rolling() error = %v", err)
+			}
+			if last := fake.scales[len(fake.scales)-1]; last != tt.replicas {
+				t.Fatalf("final scale = %d, want %d", last, tt.replicas)
+			}
+			if got := len(fake.containers["api"]); got != tt.replicas {
+				t.Fatalf("containers = %d, want %d", got, tt.replicas)
+			}
+		})
+	}
+}
+
+func TestRollingUnchangedConfigOnlyScales(t *testing.T) {
+	fake := newFakeDocker("same")
+	fake.add("api", 2, "same")
+	service := rollingService(5, 1)
+	r := newTestRollout(t, fake, service, nil)
+
+	if err := r.rolling(context.Background(), service); err != nil {
+		t.Fatalf("rolling() error = %v", err)
+	}
+
+	if want := []int{5}; !reflect.DeepEqual(fake.scales, want) {
+		t.Fatalf("scales = %v, want %v", fake.scales, want)
+	}
+	if len(fake.removed) > 0 || fake.healthChecks > 0 || len(fake.waits) > 0 {
+		t.Fatalf("unchanged config recreated containers: removed %v, %d health checks, waits %v", fake.removed, fake.healthChecks, fake.waits)
+	}
+}
+
+func TestRollingBuildAlwaysReplaces(t *testing.T) {
+	fake := newFakeDocker("same")
+	fake.add("api", 2, "same")
+	service := rollingService(2, 2)
+	service.Build = &config.BuildSpec{Context: "."}
+	r := newTestRollout(t, fake, service, nil)
+
+	if err := r.rolling(context.Background(), service); err != nil {
+		t.Fatalf("rolling() error = %v", err)
+	}
+	if want := [][]string{{"api-1", "api-2"}}; !reflect.DeepEqual(fake.removed, want) {
+		t.Fatalf("removed = %v, want %v", fake.removed, want)
+	}
+}
+
+func TestRollingUnhealthySecondBatchRestoresPreviousVersion(t *testing.T) {
+	fake := newFakeDocker("new")
+	fake.add("api", 4, "old") // api-1..api-4
+	fake.unhealthyAt = 2
+	service := rollingService(4, 2)
+	r := newTestRollout(t, fake, service, nil)
+
+	err := r.rolling(context.Background(), service)
+	if err == nil || !strings.Contains(err.Error(), "aborted at batch 2/2") {
+		t.Fatalf("rolling() error = %v, want abort at batch 2/2", err)
+	}
+
+	// O lote 1 (api-5, api-6) passou e o lote 2 (api-7, api-8) falhou: saem os dois lotes novos
+	if want := [][]string{{"api-5", "api-6", "api-7", "api-8"}}; !reflect.DeepEqual(fake.removed, want) {
+		t.Fatalf("removed = %v, want %v", fake.removed, want)
+	}
+	if got, want := fake.ids("api"), []string{"api-1", "api-2", "api-3", "api-4"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("containers = %v, want %v", got, want)
+	}
+	for _, container := range fake.containers["api"] {
+		if container.Label(docker.ConfigHashLabel) != "old" {
+			t.Fatalf("container %s runs the new config after the rollback", container.ID)
+		}
+	}
+}
+
+func TestRollingInterruptedBetweenBatchesRestoresPreviousVersion(t *testing.T) {
+	fake := newFakeDocker("new")
+	fake.add("api", 2, "old")
+	service := rollingService(2, 1)
+	r := newTestRollout(t, cancelAwareDocker{fake}, service, nil)
+
+	// Ctrl-C durante o intervalo entre os lotes
+	ctx, cancel := context.WithCancel(context.Background())
+	fake.onWait = func(call int) { cancel() }
+
+	if err := r.rolling(ctx, service); err == nil || !strings.Contains(err.Error(), "aborted at batch 1/2") {
+		t.Fatalf("rolling() error = %v, want abort at batch 1/2", err)
+	}
+	if got, want := fake.ids("api"), []string{"api-1", "api-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("containers = %v, want %v", got, want)
+	}
+}
+
+// cancelAwareDocker devolve o erro do contexto em Wait, como o serviço real
+type cancelAwareDocker struct {
+	*fakeDocker
+}
+
+func (c cancelAwareDocker) Wait(ctx context.Context, d time.Duration) error {
+	if err := c.fakeDocker.Wait(ctx, d); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func TestRollingUnhealthyFirstBatchKeepsOldContainers(t *testing.T) {
+	fake := newFakeDocker("new")
+	fake.add("api", 2, "old")
+	fake.unhealthyAt = 1
+	service := rollingService(2, 1)
+	r := newTestRollout(t, fake, service, nil)
+
+	if err := r.rolling(context.Background(), service); err == nil {
+		t.Fatal("rolling() error = nil, want abort")
+	}
+	if got, want := fake.ids("api"), []string{"api-1", "api-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("containers = %v, want %v", got, want)
+	}
+}
+
+func TestRollingAbortIgnoresCancellation(t *testing.T) {
+	fake := newFakeDocker("new")
+	fake.add("api", 2, "old")
+	service := rollingService(2, 1)
+	r := newTestRollout(t, fake, service, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fake.add("api", 1, "new") // api-3, do lote interrompido
+	if err := r.abortRolling(ctx, "api", []string{"api-1", "api-2"}, 0, 2, context.Canceled); err == nil {
+		t.Fatal("abortRolling() error = nil, want the deploy error")
+	}
+	if want := [][]string{{"api-3"}}; !reflect.DeepEqual(fake.removed, want) {
+		t.Fatalf("removed = %v, want %v", fake.removed, want)
+	}
+}
+
+func progressiveService(strategy string, steps ...config.CanaryStep) config.Service {
+	return config.Service{
+		Name:      "api",
+		Image:     "api:2",
+		Subdomain: "api",
+		Expose:    8080,
+		Traefik:   &config.ServiceTraefik{Enabled: true},
+		Deploy:    &config.DeployConfig{Strategy: strategy, Steps: steps},
+	}
+}
+
+func TestRunFirstDeployStartsStableColor(t *testing.T) {
+	fake := newFakeDocker("v1")
+	service := progressiveService("blue-green")
+	r := newTestRollout(t, fake, service, nil)
+
+	if err := r.run(context.Background(), service); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if want := []string{"api-blue"}; !reflect.DeepEqual(fake.deployed, want) {
+		t.Fatalf("deployed = %v, want %v", fake.deployed, want)
+	}
+	if got := r.state["api"]; got != (compose.Traffic{Stable: compose.ColorBlue}) {
+		t.Fatalf("traffic = %+v, want all on blue", got)
+	}
+}
+
+func TestRunBlueGreenSwitchesAndRetires(t *testing.T) {
+	fake := newFakeDocker("v2")
+	fake.add("api-blue", 1, "v1")
+	service := progressiveService("blue-green")
+	r := newTestRollout(t, fake, service, compose.RolloutState{"api": {Stable: compose.ColorBlue}})
+
+	if err := r.run(context.Background(), service); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if want := []string{"api-green"}; !reflect.DeepEqual(fake.deployed, want) {
+		t.Fatalf("deployed = %v, want %v", fake.deployed, want)
+	}
+	if want := []string{"api-blue"}; !reflect.DeepEqual(fake.removedGroups, want) {
+		t.Fatalf("removed services = %v, want %v", fake.removedGroups, want)
+	}
+	if got := r.state["api"]; got != (compose.Traffic{Stable: compose.ColorGreen}) {
+		t.Fatalf("traffic = %+v, want all on green", got)
+	}
+	if want := []time.Duration{traefikSettle, defaultDrain}; !reflect.DeepEqual(fake.waits, want) {
+		t.Fatalf("waits = %v, want %v", fake.waits, want)
+	}
+
+	saved, err := loadRolloutState(r.filesystem, r.dir)
+	if err != nil {
+		t.Fatalf("loadRolloutState() error = %v", err)
+	}
+	if !reflect.DeepEqual(saved, r.state) {
+		t.Fatalf("saved state = %+v, want %+v", saved, r.state)
+	}
+	if !r.filesystem.Exists(filepath.Join(r.dir, compose.DynamicConfigFile)) {
+		t.Fatal("dynamic config was not written")
+	}
+}
+
+func TestRunUnhealthyNextColorAborts(t *testing.T) {
+	fake := newFakeDocker("v2")
+	fake.add("api-green", 1, "v1")
+	fake.unhealthyAt = 1
+	service := progressiveService("blue-green")
+	r := newTestRollout(t, fake, service, compose.RolloutState{"api": {Stable: compose.ColorGreen}})
+
+	err := r.run(context.Background(), service)
+	if err == nil || !strings.Contains(err.Error(), "green still serves all traffic") {
+		t.Fatalf("run() error = %v, want abort keeping green", err)
+	}
+	if want := []string{"api-blue"}; !reflect.DeepEqual(fake.removedGroups, want) {
+		t.Fatalf("removed services = %v, want %v", fake.removedGroups, want)
+	}
+	if got := fake.ids("api-green"); len(got) != 1 {
+		t.Fatalf("stable containers = %v, want untouched", got)
+	}
+	if got := r.state["api"]; got != (compose.Traffic{Stable: compose.ColorGreen}) {
+		t.Fatalf("traffic = %+v, want all on green", got)
+	}
+}
+
+func TestRunCanarySteps(t *testing.T) {
+	fake := newFakeDocker("v2")
+	fake.add("api-blue", 1, "v1")
+	service := progressiveService("canary",
+		config.CanaryStep{Weight: 10, Duration: "2m"},
+		config.CanaryStep{Weight: 50, Duration: "5m"},
+	)
+	r := newTestRollout(t, fake, service, compose.RolloutState{"api": {Stable: compose.ColorBlue}})
+
+	var weights []int
+	fake.onWait = func(int) { weights = append(weights, r.state["api"].Canary) }
+
+	if err := r.run(context.Background(), service); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	// Settle sem tráfego, um Wait por step com o peso aplicado, drain com tudo na nova cor
+	if want := []int{0, 10, 50, 0}; !reflect.DeepEqual(weights, want) {
+		t.Fatalf("canary weight at each wait = %v, want %v", weights, want)
+	}
+	if want := []time.Duration{traefikSettle, 2 * time.Minute, 5 * time.Minute, defaultDrain}; !reflect.DeepEqual(fake.waits, want) {
+		t.Fatalf("waits = %v, want %v", fake.waits, want)
+	}
+	if got := r.state["api"]; got != (compose.Traffic{Stable: compose.ColorGreen}) {
+		t.Fatalf("traffic = %+v, want all on green", got)
+	}
+}
+
+func TestRunCanaryUnhealthyStepRollsBack(t *testing.T) {
+	fake := newFakeDocker("v2")
+	fake.add("api-blue", 1, "v1")
+	service := progressiveService("canary",
+		config.CanaryStep{Weight: 10, Duration: "1m"},
+		config.CanaryStep{Weight: 50, Duration: "1m"},
+	)
+	r := newTestRollout(t, fake, service, compose.RolloutState{"api": {Stable: compose.ColorBlue}})
+
+	// A nova cor adoece durante o segundo step (settle, step 1, step 2)
+	fake.onWait = func(call int) {
+		if call == 3 {
+			fake.containers["api-green"][0].Health = "unhealthy"
+		}
+	}
+
+	err := r.run(context.Background(), service)
+	if err == nil || !strings.Contains(err.Error(), "during step 2") {
+		t.Fatalf("run() error = %v, want failure during step 2", err)
+	}
+	if got := r.state["api"]; got != (compose.Traffic{Stable: compose.ColorBlue}) {
+		t.Fatalf("traffic = %+v, want all back on blue", got)
+	}
+	if want := []string{"api-green"}; !reflect.DeepEqual(fake.removedGroups, want) {
+		t.Fatalf("removed services = %v, want %v", fake.removedGroups, want)
+	}
+	if got := fake.ids("api-blue"); len(got) != 1 {
+		t.Fatalf("stable containers = %v, want untouched", got)
+	}
+}
+
+// dependencyStack declara os serviços fora da ordem de dependência:
+// web -> api (rolling) -> db, e worker (rolling) independente
+func dependencyStack() *config.Stack {
+	healthy := func(name string) []config.Dependency {
+		return []config.Dependency{{Service: name, Condition: config.ConditionHealthy}}
+	}
+	return &config.Stack{
+		Project: "demo",
+		Domain:  "example.com",
+		Services: []config.Service{
+			{Name: "web", Image: "web:1", Expose: 80, DependsOn: healthy("api")},
+			{Name: "api", Image: "api:1", Expose: 8080, Replicas: 2, DependsOn: healthy("db")},
+			{Name: "db", Image: "postgres:16", Expose: 5432},
+			{Name: "worker", Image: "worker:1", Expose: 9000, Replicas: 2},
+		},
+	}
+}
+
+const dependencyCompose = "services:\n  web: {}\n  api: {}\n  db: {}\n  worker: {}\n  traefik: {}\n"
+
+func deployTestStack(t *testing.T, fake *fakeDocker, stack *config.Stack) error {
+	t.Helper()
+	composePath := filepath.Join(t.TempDir(), "compose.generated.yml")
+	return deployStack(context.Background(), compose.NewDefaultService(), fake, fs.NewFileSystem(), discardOutput{},
+		stack, composePath, []byte(dependencyCompose), docker.DeployOptions{Build: true, Prune: true, Detach: true})
+}
+
+func TestDeployStackFollowsDependencyOrder(t *testing.T) {
+	fake := newFakeDocker("v1")
+
+	if err := deployTestStack(t, fake, dependencyStack()); err != nil {
+		t.Fatalf("deployStack() error = %v", err)
+	}
+
+	// traefik e db sobem juntos; api espera db, web espera api e worker vem por último
+	want := []string{
+		"up traefik,db",
+		"healthy db",
+		"up api",
+		"healthy api",
+		"up web",
+		"up worker",
+		"healthy worker",
+	}
+	if !reflect.DeepEqual(fake.events, want) {
+		t.Fatalf("events = %v, want %v", fake.events, want)
+	}
+}
+
+func TestDeployStackRejectsDependencyCycle(t *testing.T) {
+	fake := newFakeDocker("v1")
+	stack := dependencyStack()
+	stack.Services[2].DependsOn = []config.Dependency{{Service: "web"}} // db -> web fecha o ciclo
+
+	err := deployTestStack(t, fake, stack)
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle: web -> api -> db -> web") {
+		t.Fatalf("deployStack() error = %v, want a dependency cycle", err)
+	}
+	if len(fake.events) > 0 {
+		t.Fatalf("events = %v, want nothing deployed", fake.events)
+	}
+}
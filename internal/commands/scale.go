@@ -38,8 +38,11 @@ func (c *scaleCommand) Description() string {
 func (c *scaleCommand) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("scale", flag.ExitOnError)
 
-	var composePath string
+	var composePath, stackPath string
+	var save bool
 	fs.StringVar(&composePath, "f", ".deploy/compose.generated.yml", "arquivo compose")
+	fs.StringVar(&stackPath, "stack", "stack.yml", "stack.yml atualizado com --save")
+	fs.BoolVar(&save, "save", false, "grava as réplicas no stack.yml (preserva comentários)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -80,6 +83,43 @@ func (c *scaleCommand) Execute(ctx context.Context, args []string) error {
 		c.output.Infof("✅ Successfully scaled %s to %d replicas", service, replicas)
 	}
 
+	if save {
+		return c.saveReplicas(ctx, stackPath, scaleSpecs)
+	}
+
+	return nil
+}
+
+// saveReplicas persiste as réplicas no stack.yml alterando apenas esses campos
+func (c *scaleCommand) saveReplicas(ctx context.Context, stackPath string, scaleSpecs map[string]int) error {
+	stack, err := c.configManager.Load(ctx, stackPath)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(stack.Services))
+	for _, service := range stack.Services {
+		known[service.Name] = true
+	}
+	for service := range scaleSpecs {
+		if !known[service] {
+			return fmt.Errorf("service %s not found in %s", service, stackPath)
+		}
+	}
+
+	err = c.configManager.Edit(ctx, stackPath, func(doc *config.Document) error {
+		for service, replicas := range scaleSpecs {
+			if err := doc.Set(fmt.Sprintf("services[name=%s].replicas", service), replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save replicas: %w", err)
+	}
+
+	c.output.Infof("💾 Replicas saved to %s", stackPath)
 	return nil
 }
 
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+// schemaCommand implementa o comando schema
+type schemaCommand struct {
+	filesystem fs.FileSystem
+	output     cli.Output
+}
+
+// NewSchemaCommand cria um novo comando schema
+func NewSchemaCommand(filesystem fs.FileSystem, output cli.Output) cli.Command {
+	return &schemaCommand{
+		filesystem: filesystem,
+		output:     output,
+	}
+}
+
+func (c *schemaCommand) Name() string {
+	return "schema"
+}
+
+func (c *schemaCommand) Description() string {
+	return "Print the JSON Schema for stack.yml (editor autocomplete/validation)"
+}
+
+func (c *schemaCommand) Execute(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+
+	var outputPath string
+	fs.StringVar(&outputPath, "o", "", "write the schema to a file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	if outputPath == "" {
+		c.output.Info(string(data))
+		return nil
+	}
+
+	if err := c.filesystem.WriteFile(outputPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	c.output.Infof("✅ Schema written to %s", outputPath)
+	c.output.Infof("   Add '# yaml-language-server: $schema=%s' to the top of stack.yml", outputPath)
+	return nil
+}
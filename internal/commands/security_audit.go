@@ -38,10 +38,11 @@ func (c *securityAuditCommand) Description() string {
 func (c *securityAuditCommand) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("security-audit", flag.ExitOnError)
 
-	var stackPath, repoConfigPath string
+	var stackPath, repoConfigPath, env string
 	var includeRepos bool
 
 	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado")
 	fs.StringVar(&repoConfigPath, "repos", "repos.yml", "configuração de repositórios")
 	fs.BoolVar(&includeRepos, "include-repos", false, "incluir auditoria de repositórios")
 
@@ -52,7 +53,7 @@ func (c *securityAuditCommand) Execute(ctx context.Context, args []string) error
 	c.output.Info("🔒 Iniciando auditoria de segurança...")
 
 	// Auditoria da configuração local
-	if err := c.auditLocalConfig(ctx, stackPath); err != nil {
+	if err := c.auditLocalConfig(ctx, stackPath, env); err != nil {
 		return err
 	}
 
@@ -67,11 +68,11 @@ func (c *securityAuditCommand) Execute(ctx context.Context, args []string) error
 	return nil
 }
 
-func (c *securityAuditCommand) auditLocalConfig(ctx context.Context, stackPath string) error {
+func (c *securityAuditCommand) auditLocalConfig(ctx context.Context, stackPath, env string) error {
 	c.output.Info("🔍 Auditando configuração local...")
 
 	// Carregar configuração
-	stack, err := c.configManager.Load(ctx, stackPath)
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
 	if err != nil {
 		return fmt.Errorf("erro ao carregar configuração: %w", err)
 	}
@@ -0,0 +1,496 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leandrodaf/harborctl/internal/compose"
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/cli"
+	"github.com/leandrodaf/harborctl/pkg/prompt"
+)
+
+var serviceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// serviceCommand implementa o comando service e seus subcomandos
+type serviceCommand struct {
+	configManager config.Manager
+	prompter      prompt.Prompter
+	output        cli.Output
+}
+
+// NewServiceCommand cria um novo comando service
+func NewServiceCommand(configManager config.Manager, output cli.Output) cli.Command {
+	return &serviceCommand{
+		configManager: configManager,
+		prompter:      prompt.NewPrompter(),
+		output:        output,
+	}
+}
+
+func (c *serviceCommand) Name() string {
+	return "service"
+}
+
+func (c *serviceCommand) Description() string {
+	return "Manage services in stack.yml (add, remove, list, show)"
+}
+
+func (c *serviceCommand) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: harborctl service <add|remove|list|show> [flags]")
+	}
+
+	switch args[0] {
+	case "add":
+		return c.add(ctx, args[1:])
+	case "remove", "rm":
+		return c.remove(ctx, args[1:])
+	case "list", "ls":
+		return c.list(ctx, args[1:])
+	case "show":
+		return c.show(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown service subcommand: %s (available: add, remove, list, show)", args[0])
+	}
+}
+
+// add inclui um novo serviço no stack.yml, por flags ou de forma interativa
+func (c *serviceCommand) add(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("service add", flag.ExitOnError)
+
+	var stackPath, image, buildContext, dockerfile, subdomain, healthPath, memory, cpus, networks string
+	var expose, replicas int
+	var internet, interactive bool
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&image, "image", "", "imagem do serviço (ex: nginx:1.27)")
+	fs.StringVar(&buildContext, "build", "", "contexto de build (alternativa a --image)")
+	fs.StringVar(&dockerfile, "dockerfile", "Dockerfile", "Dockerfile usado com --build")
+	fs.IntVar(&expose, "expose", 0, "porta interna do container")
+	fs.IntVar(&replicas, "replicas", 0, "número de réplicas")
+	fs.StringVar(&subdomain, "subdomain", "", "subdomínio público (habilita o Traefik)")
+	fs.StringVar(&healthPath, "health-path", "", "path HTTP do health check (ex: /health)")
+	fs.StringVar(&memory, "memory", "", "limite de memória (ex: 512m)")
+	fs.StringVar(&cpus, "cpus", "", "limite de CPU (ex: 0.5)")
+	fs.BoolVar(&internet, "internet", false, "permite acesso de saída à internet")
+	fs.StringVar(&networks, "networks", "", "redes customizadas adicionais, separadas por vírgula")
+	fs.BoolVar(&interactive, "interactive", false, "pergunta cada campo")
+
+	name, err := parseNamedArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	stack, err := c.configManager.Load(ctx, stackPath)
+	if err != nil {
+		return err
+	}
+
+	var service config.Service
+	if interactive || name == "" {
+		service, err = c.promptService(stack, name)
+		if err != nil {
+			return err
+		}
+	} else {
+		service, err = buildService(name, image, buildContext, dockerfile, expose)
+		if err != nil {
+			return err
+		}
+		service.Replicas = replicas
+		if subdomain != "" {
+			service.Subdomain = subdomain
+			service.Traefik = &config.ServiceTraefik{Enabled: true}
+		}
+		if healthPath != "" {
+			service.HealthCheck = &config.HealthCheck{Enabled: true, Path: healthPath}
+		}
+		if memory != "" || cpus != "" {
+			service.Resources = &config.Resources{Memory: memory, CPUs: cpus}
+		}
+		if internet || networks != "" {
+			service.NetworkAccess = &config.NetworkAccess{Internet: internet, Custom: splitList(networks)}
+		}
+	}
+
+	if err := validateServiceName(service.Name); err != nil {
+		return err
+	}
+	if findService(stack, service.Name) != nil {
+		return fmt.Errorf("service %s already exists in %s", service.Name, stackPath)
+	}
+
+	// Valida o stack resultante antes de gravar
+	candidate := *stack
+	candidate.Services = append(append([]config.Service(nil), stack.Services...), service)
+	if err := c.configManager.Validate(ctx, &candidate); err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := node.Encode(service); err != nil {
+		return fmt.Errorf("failed to encode service: %w", err)
+	}
+	err = c.configManager.Edit(ctx, stackPath, func(doc *config.Document) error {
+		return doc.SetNode(fmt.Sprintf("services[name=%s]", service.Name), &node)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save service: %w", err)
+	}
+
+	c.output.Infof("✅ Service %s added to %s", service.Name, stackPath)
+	if url := serviceURL(service, stack.Domain, compose.GetEnvironmentFromStack(&candidate)); url != "" {
+		c.output.Infof("🌐 %s", url)
+	}
+	return nil
+}
+
+// promptService pergunta os campos do serviço
+func (c *serviceCommand) promptService(stack *config.Stack, name string) (config.Service, error) {
+	var service config.Service
+	var err error
+
+	if name == "" {
+		name, err = c.prompter.TextWithValidation("Service name", prompt.CombineValidators(
+			validateServiceName,
+			func(input string) error {
+				if findService(stack, input) != nil {
+					return fmt.Errorf("service %s already exists", input)
+				}
+				return nil
+			},
+		))
+		if err != nil {
+			return service, err
+		}
+	}
+
+	source, err := c.prompter.Select("Where does the service come from?", []string{
+		"Docker image",
+		"Build from Dockerfile",
+	}, 0)
+	if err != nil {
+		return service, err
+	}
+
+	var image, buildContext, dockerfile string
+	if source == "Docker image" {
+		if image, err = c.prompter.TextWithValidation("Image (ex: nginx:1.27)", prompt.ValidateRequired); err != nil {
+			return service, err
+		}
+	} else {
+		if buildContext, err = c.prompter.Text("Build context", "."); err != nil {
+			return service, err
+		}
+		if dockerfile, err = c.prompter.Text("Dockerfile", "Dockerfile"); err != nil {
+			return service, err
+		}
+	}
+
+	portText, err := c.prompter.TextWithValidation("Container port", validatePort, "8080")
+	if err != nil {
+		return service, err
+	}
+	port, _ := strconv.Atoi(portText)
+
+	if service, err = buildService(name, image, buildContext, dockerfile, port); err != nil {
+		return service, err
+	}
+
+	public, err := c.prompter.Confirm("Expose publicly through Traefik?", true)
+	if err != nil {
+		return service, err
+	}
+	if public {
+		if service.Subdomain, err = c.prompter.TextWithValidation("Subdomain", prompt.ValidateSubdomain, name); err != nil {
+			return service, err
+		}
+		service.Traefik = &config.ServiceTraefik{Enabled: true}
+	}
+
+	health, err := c.prompter.Confirm("Enable HTTP health check?", true)
+	if err != nil {
+		return service, err
+	}
+	if health {
+		path, err := c.prompter.Text("Health check path", "/health")
+		if err != nil {
+			return service, err
+		}
+		service.HealthCheck = &config.HealthCheck{Enabled: true, Path: path}
+	}
+
+	limits, err := c.prompter.Confirm("Set resource limits?", false)
+	if err != nil {
+		return service, err
+	}
+	if limits {
+		resources := &config.Resources{}
+		if resources.Memory, err = c.prompter.Text("Memory limit (ex: 512m)", "512m"); err != nil {
+			return service, err
+		}
+		if resources.CPUs, err = c.prompter.Text("CPU limit (ex: 0.5)", "0.5"); err != nil {
+			return service, err
+		}
+		service.Resources = resources
+	}
+
+	internet, err := c.prompter.Confirm("Allow outbound internet access?", false)
+	if err != nil {
+		return service, err
+	}
+	if internet {
+		service.NetworkAccess = &config.NetworkAccess{Internet: true}
+	}
+
+	return service, nil
+}
+
+// remove exclui um serviço do stack.yml
+func (c *serviceCommand) remove(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("service remove", flag.ExitOnError)
+
+	var stackPath string
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+
+	name, err := parseNamedArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: harborctl service remove <name> [-f stack.yml]")
+	}
+
+	removed := false
+	err = c.configManager.Edit(ctx, stackPath, func(doc *config.Document) error {
+		removed = doc.Delete(fmt.Sprintf("services[name=%s]", name))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !removed {
+		// O serviço pode vir de um include ou overlay, que não são editados aqui
+		return fmt.Errorf("service %s not found in %s", name, stackPath)
+	}
+
+	c.output.Infof("🗑️  Service %s removed from %s", name, stackPath)
+	return nil
+}
+
+// list mostra os serviços com URL e redes calculadas para o ambiente
+func (c *serviceCommand) list(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("service list", flag.ExitOnError)
+
+	var stackPath, env string
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
+	if err != nil {
+		return err
+	}
+
+	if len(stack.Services) == 0 {
+		c.output.Infof("No services defined in %s", stackPath)
+		return nil
+	}
+
+	environment := compose.GetEnvironmentFromStack(stack)
+	builder := newServiceBuilder()
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tPORT\tREPLICAS\tURL\tNETWORKS")
+	for _, service := range stack.Services {
+		fragment := builder.BuildWithEnvironment(ctx, service, stack.Domain, environment, stack.Project)
+		networks, _ := fragment["networks"].([]string)
+
+		url := serviceURL(service, stack.Domain, environment)
+		if url == "" {
+			url = "-"
+		}
+		replicas := service.Replicas
+		if replicas == 0 {
+			replicas = 1
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			service.Name, serviceSource(service), service.Expose, replicas, url, strings.Join(networks, ","))
+	}
+	w.Flush()
+
+	c.output.Info(strings.TrimRight(b.String(), "\n"))
+	return nil
+}
+
+// show imprime a definição do serviço e o trecho de compose gerado a partir dela
+func (c *serviceCommand) show(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("service show", flag.ExitOnError)
+
+	var stackPath, env string
+	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado")
+
+	name, err := parseNamedArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: harborctl service show <name> [-f stack.yml] [--env name]")
+	}
+
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
+	if err != nil {
+		return err
+	}
+
+	service := findService(stack, name)
+	if service == nil {
+		return fmt.Errorf("service %s not found in %s", name, stackPath)
+	}
+
+	definition, err := yaml.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("failed to encode service: %w", err)
+	}
+
+	environment := compose.GetEnvironmentFromStack(stack)
+	fragment := newServiceBuilder().BuildWithEnvironment(ctx, *service, stack.Domain, environment, stack.Project)
+	generated, err := yaml.Marshal(map[string]any{"services": map[string]any{service.Name: fragment}})
+	if err != nil {
+		return fmt.Errorf("failed to encode compose fragment: %w", err)
+	}
+
+	c.output.Infof("# %s (%s)", stackPath, environment.Name)
+	c.output.Info(strings.TrimSuffix(string(definition), "\n"))
+	c.output.Info("")
+	c.output.Info("# docker compose")
+	c.output.Info(strings.TrimSuffix(string(generated), "\n"))
+	return nil
+}
+
+// parseNamedArgs aceita o nome do serviço antes ou depois das flags
+func parseNamedArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+
+	switch {
+	case fs.NArg() == 0:
+		return name, nil
+	case fs.NArg() == 1 && name == "":
+		return fs.Arg(0), nil
+	default:
+		return "", fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+}
+
+func newServiceBuilder() compose.ServiceBuilder {
+	return compose.NewServiceBuilder(compose.NewHealthChecker(), compose.NewDeployStrategy())
+}
+
+// buildService monta o serviço mínimo a partir da origem (imagem ou build) e da porta
+func buildService(name, image, buildContext, dockerfile string, expose int) (config.Service, error) {
+	service := config.Service{Name: name, Expose: expose}
+
+	switch {
+	case image != "" && buildContext != "":
+		return service, fmt.Errorf("use either --image or --build, not both")
+	case image != "":
+		service.Image = image
+	case buildContext != "":
+		service.Build = &config.BuildSpec{Context: buildContext, Dockerfile: dockerfile}
+	default:
+		return service, fmt.Errorf("service %s needs --image or --build", name)
+	}
+
+	if expose <= 0 || expose > 65535 {
+		return service, fmt.Errorf("invalid --expose %d (use 1-65535)", expose)
+	}
+	return service, nil
+}
+
+func findService(stack *config.Stack, name string) *config.Service {
+	for i := range stack.Services {
+		if stack.Services[i].Name == name {
+			return &stack.Services[i]
+		}
+	}
+	return nil
+}
+
+// serviceURL retorna o endereço público do serviço, ou a regra customizada do Traefik
+func serviceURL(service config.Service, domain string, env compose.Environment) string {
+	traefik := service.GetTraefik()
+	if traefik == nil || !traefik.Enabled {
+		return ""
+	}
+	if rule := traefik.CustomRule(); rule != "" {
+		return rule
+	}
+
+	scheme := "http"
+	if env.TLSEnabled() {
+		scheme = "https"
+	}
+	if len(service.Routes) > 0 {
+		// Primeira rota; as demais aparecem em 'service show'
+		route := service.Routes[0]
+		url := fmt.Sprintf("%s://%s", scheme, service.RouteHosts(route, domain)[0])
+		if len(route.Paths) > 0 {
+			url += route.Paths[0]
+		}
+		return url
+	}
+	if service.Subdomain == "" && len(service.Domains) > 0 {
+		return fmt.Sprintf("%s://%s", scheme, service.Domains[0].Host)
+	}
+	return fmt.Sprintf("%s://%s.%s", scheme, service.Subdomain, domain)
+}
+
+func serviceSource(service config.Service) string {
+	if service.Build != nil {
+		return "build:" + service.Build.Context
+	}
+	return service.Image
+}
+
+func validateServiceName(input string) error {
+	if !serviceNamePattern.MatchString(input) {
+		return fmt.Errorf("invalid service name %q (use lowercase letters, numbers, '-' and '_')", input)
+	}
+	return nil
+}
+
+func validatePort(input string) error {
+	port, err := strconv.Atoi(input)
+	if err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf("port must be a number between 1 and 65535")
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
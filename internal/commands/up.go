@@ -48,10 +48,11 @@ func (c *upCommand) Description() string {
 func (c *upCommand) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("up", flag.ExitOnError)
 
-	var stackPath, outputPath string
+	var stackPath, outputPath, env string
 	var noDozzle, noBeszel bool
 
 	fs.StringVar(&stackPath, "f", "stack.yml", "stack.yml")
+	fs.StringVar(&env, "env", "", "environment overlay (stack.<env>.yml)")
 	fs.StringVar(&outputPath, "o", ".deploy/compose.generated.yml", "output compose file")
 	fs.BoolVar(&noDozzle, "no-dozzle", false, "don't include dozzle")
 	fs.BoolVar(&noBeszel, "no-beszel", false, "don't include beszel")
@@ -61,7 +62,7 @@ func (c *upCommand) Execute(ctx context.Context, args []string) error {
 	}
 
 	// Load and validate configuration
-	stack, err := c.configManager.Load(ctx, stackPath)
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
 	if err != nil {
 		return err
 	}
@@ -91,6 +92,10 @@ func (c *upCommand) Execute(ctx context.Context, args []string) error {
 		return err
 	}
 
+	if err := writeDynamicConfig(ctx, c.composeService, c.filesystem, stack, outputPath); err != nil {
+		return err
+	}
+
 	c.output.Infof("compose generated at %s", outputPath)
 
 	// Deploy
@@ -100,5 +105,5 @@ func (c *upCommand) Execute(ctx context.Context, args []string) error {
 		Detach: true,
 	}
 
-	return c.dockerService.Deploy(ctx, outputPath, deployOptions)
+	return deployStack(ctx, c.composeService, c.dockerService, c.filesystem, c.output, stack, outputPath, data, deployOptions)
 }
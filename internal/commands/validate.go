@@ -2,7 +2,11 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/leandrodaf/harborctl/internal/config"
 	"github.com/leandrodaf/harborctl/pkg/cli"
@@ -10,17 +14,15 @@ import (
 
 // validateCommand implementa o comando validate
 type validateCommand struct {
-	configManager   config.Manager
-	secureValidator *config.SecureValidator
-	output          cli.Output
+	configManager config.Manager
+	output        cli.Output
 }
 
 // NewValidateCommand cria um novo comando validate
 func NewValidateCommand(configManager config.Manager, output cli.Output) cli.Command {
 	return &validateCommand{
-		configManager:   configManager,
-		secureValidator: config.NewSecureValidator(),
-		output:          output,
+		configManager: configManager,
+		output:        output,
 	}
 }
 
@@ -35,29 +37,72 @@ func (c *validateCommand) Description() string {
 func (c *validateCommand) Execute(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("validate", flag.ExitOnError)
 
-	var stackPath string
+	var stackPath, format, env string
+	var strict bool
 	fs.StringVar(&stackPath, "f", "stack.yml", "caminho do stack.yml")
+	fs.StringVar(&env, "env", "", "ambiente cujo overlay (stack.<env>.yml) é mesclado")
+	fs.StringVar(&format, "format", "table", "formato do relatório (table|json)")
+	fs.BoolVar(&strict, "strict", false, "falha também com warnings")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	stack, err := c.configManager.Load(ctx, stackPath)
+	stack, err := c.configManager.LoadEnv(ctx, stackPath, env)
 	if err != nil {
 		return err
 	}
 
-	// Validação padrão
-	if err := c.configManager.Validate(ctx, stack); err != nil {
-		return err
+	// Todas as regras (estrutura, segurança e boas práticas) em uma única passada
+	report := c.configManager.Check(ctx, stack)
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		c.output.Info(string(data))
+	case "table":
+		c.renderTable(report)
+	default:
+		return fmt.Errorf("invalid format: %s (use table or json)", format)
 	}
 
-	// Validação de segurança
-	if err := c.secureValidator.ValidateStack(stack); err != nil {
-		c.output.Error("Falha na validação de segurança: " + err.Error())
-		return err
+	errorsCount := report.Count(config.SeverityError)
+	warningsCount := report.Count(config.SeverityWarning)
+	if errorsCount > 0 {
+		return fmt.Errorf("validation failed: %d error(s), %d warning(s)", errorsCount, warningsCount)
+	}
+	if strict && warningsCount > 0 {
+		return fmt.Errorf("validation failed in strict mode: %d warning(s)", warningsCount)
 	}
 
-	c.output.Info("OK: configuração válida e segura.")
 	return nil
 }
+
+func (c *validateCommand) renderTable(report *config.Report) {
+	if len(report.Findings) == 0 {
+		c.output.Info("OK: configuração válida e segura.")
+		return
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tRULE\tLOCATION\tMESSAGE")
+	for _, f := range report.Findings {
+		location := f.Location
+		if location == "" {
+			location = f.Path
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Severity, f.Rule, location, f.Message)
+		if f.Hint != "" {
+			fmt.Fprintf(w, "\t\t\t↳ %s\n", f.Hint)
+		}
+	}
+	w.Flush()
+
+	c.output.Info(strings.TrimRight(b.String(), "\n"))
+	c.output.Infof("%d error(s), %d warning(s), %d info",
+		report.Count(config.SeverityError), report.Count(config.SeverityWarning), report.Count(config.SeverityInfo))
+}
@@ -0,0 +1,145 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+const (
+	// letsEncryptStaging é o diretório ACME de staging do Let's Encrypt
+	letsEncryptStaging = "https://acme-staging-v02.api.letsencrypt.org/directory"
+
+	// acmeVolume guarda o acme.json no container do Traefik
+	acmeVolume = "traefik_acme:/letsencrypt"
+)
+
+// ACMEStorage retorna o acme.json do resolver no container do Traefik; staging usa
+// outro arquivo para não misturar contas e certificados com os de produção
+func ACMEStorage(tls config.TLS) string {
+	if tls.Staging {
+		return "/letsencrypt/acme-staging.json"
+	}
+	return "/letsencrypt/acme.json"
+}
+
+// acmeSettings é o que o resolver ACME acrescenta ao container do Traefik
+type acmeSettings struct {
+	commands    []string
+	environment map[string]string
+	secrets     []string
+}
+
+// acmeResolver monta o resolver ACME de tls: CA, EAB, challenge, domínios explícitos e credenciais DNS
+func acmeResolver(stack *config.Stack) acmeSettings {
+	tls := stack.TLS
+	prefix := "--certificatesresolvers." + tls.Resolver + ".acme."
+
+	settings := acmeSettings{
+		commands: []string{
+			prefix + "email=" + tls.Email,
+			prefix + "storage=" + ACMEStorage(tls),
+		},
+	}
+
+	switch {
+	case tls.CAServer != "":
+		settings.commands = append(settings.commands, prefix+"caserver="+tls.CAServer)
+	case tls.Staging:
+		settings.commands = append(settings.commands, prefix+"caserver="+letsEncryptStaging)
+	}
+	if tls.EAB != nil {
+		settings.commands = append(settings.commands,
+			prefix+"eab.kid="+tls.EAB.KID,
+			prefix+"eab.hmacencoded="+tls.EAB.HMAC,
+		)
+	}
+
+	if dns := tls.DNS; dns != nil && dns.Provider != "" {
+		// Usar DNS challenge quando configurado
+		settings.commands = append(settings.commands,
+			prefix+"dnschallenge=true",
+			prefix+"dnschallenge.provider="+dns.Provider,
+		)
+		if len(dns.Resolvers) > 0 {
+			settings.commands = append(settings.commands, prefix+"dnschallenge.resolvers="+strings.Join(dns.Resolvers, ","))
+		}
+		if dns.PropagationDelay != "" {
+			settings.commands = append(settings.commands, prefix+"dnschallenge.propagation.delaybeforechecks="+dns.PropagationDelay)
+		}
+		if dns.DisablePropagationCheck {
+			settings.commands = append(settings.commands, prefix+"dnschallenge.propagation.disablechecks=true")
+		}
+
+		settings.environment = make(map[string]string)
+		for _, envVar := range dns.Env {
+			// Assume formato KEY=VALUE
+			parts := strings.SplitN(envVar, "=", 2)
+			if len(parts) == 2 {
+				settings.environment[parts[0]] = parts[1]
+			}
+		}
+
+		// Credenciais lidas pelo lego de <VARIÁVEL>_FILE, montadas como secrets
+		for _, variable := range sortedCredentials(dns.Credentials) {
+			name := dnsCredentialSecret(variable, dns.Credentials[variable])
+			settings.environment[variable+"_FILE"] = "/run/secrets/" + name
+			settings.secrets = append(settings.secrets, name)
+		}
+	} else {
+		// Usar HTTP challenge como fallback
+		settings.commands = append(settings.commands,
+			prefix+"httpchallenge=true",
+			prefix+"httpchallenge.entrypoint=web",
+		)
+	}
+
+	// Certificados pedidos explicitamente no entrypoint seguro (ex: curingas)
+	if len(tls.Domains) > 0 {
+		settings.commands = append(settings.commands, "--entrypoints.websecure.http.tls.certresolver="+tls.Resolver)
+		for i, d := range tls.Domains {
+			settings.commands = append(settings.commands, fmt.Sprintf("--entrypoints.websecure.http.tls.domains[%d].main=%s", i, d.Main))
+			if len(d.SANs) > 0 {
+				settings.commands = append(settings.commands, fmt.Sprintf("--entrypoints.websecure.http.tls.domains[%d].sans=%s", i, strings.Join(d.SANs, ",")))
+			}
+		}
+	}
+
+	return settings
+}
+
+// acmeSecrets declara no compose as secrets das credenciais DNS
+func acmeSecrets(stack *config.Stack) map[string]map[string]any {
+	if stack.TLS.DNS == nil || len(stack.TLS.DNS.Credentials) == 0 {
+		return nil
+	}
+	secrets := make(map[string]map[string]any, len(stack.TLS.DNS.Credentials))
+	for variable, credential := range stack.TLS.DNS.Credentials {
+		name := dnsCredentialSecret(variable, credential)
+		if credential.Secret != "" {
+			secrets[name] = map[string]any{"external": true}
+		} else {
+			secrets[name] = map[string]any{"external": false, "file": credential.File}
+		}
+	}
+	return secrets
+}
+
+// dnsCredentialSecret retorna o nome da secret de uma credencial DNS
+func dnsCredentialSecret(variable string, credential config.DNSCredential) string {
+	if credential.Secret != "" {
+		return credential.Secret
+	}
+	return "acme_" + strings.ToLower(variable)
+}
+
+func sortedCredentials(credentials map[string]config.DNSCredential) []string {
+	variables := make([]string, 0, len(credentials))
+	for variable := range credentials {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+	return variables
+}
@@ -26,6 +26,11 @@ type TraefikBuilder interface {
 	Build(ctx context.Context, stack *config.Stack, env Environment) map[string]any
 }
 
+// DynamicBuilder constrói a configuração dinâmica do Traefik (provider file)
+type DynamicBuilder interface {
+	Build(ctx context.Context, stack *config.Stack, env Environment) map[string]any
+}
+
 // ObservabilityBuilder constrói serviços de observabilidade
 type ObservabilityBuilder interface {
 	Build(ctx context.Context, observability config.Observability, domain string, env Environment, options GenerateOptions, project string, tls config.TLS) map[string]map[string]any
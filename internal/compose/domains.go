@@ -0,0 +1,47 @@
+package compose
+
+import "github.com/leandrodaf/harborctl/internal/config"
+
+// certMountDir é onde os certificados fornecidos são montados no container do Traefik
+const certMountDir = "/etc/traefik/certs/"
+
+// domainCertificate é um certificado fornecido para um domínio próprio de serviço
+type domainCertificate struct {
+	Name     string // <serviço>-<domínio>, usado nos arquivos montados
+	CertFile string
+	KeyFile  string
+}
+
+// domainCertificates lista os certificados fornecidos pelos domínios dos serviços
+func domainCertificates(stack *config.Stack) []domainCertificate {
+	var certs []domainCertificate
+	for _, service := range stack.Services {
+		for _, d := range service.Domains {
+			if d.CertFile == "" || d.KeyFile == "" {
+				continue
+			}
+			certs = append(certs, domainCertificate{
+				Name:     service.Name + "-" + d.Slug(),
+				CertFile: d.CertFile,
+				KeyFile:  d.KeyFile,
+			})
+		}
+	}
+	return certs
+}
+
+// volumes monta o certificado e a chave, somente leitura
+func (c domainCertificate) volumes() []string {
+	return []string{
+		c.CertFile + ":" + certMountDir + c.Name + ".crt:ro",
+		c.KeyFile + ":" + certMountDir + c.Name + ".key:ro",
+	}
+}
+
+// dynamic retorna a entrada do certificado em tls.certificates
+func (c domainCertificate) dynamic() map[string]string {
+	return map[string]string{
+		"certFile": certMountDir + c.Name + ".crt",
+		"keyFile":  certMountDir + c.Name + ".key",
+	}
+}
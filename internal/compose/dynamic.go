@@ -0,0 +1,272 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+const (
+	// DynamicConfigFile é o arquivo de configuração dinâmica gravado ao lado do compose
+	DynamicConfigFile = "traefik-dynamic.yml"
+
+	// dynamicConfigMount é onde o arquivo é montado no container do Traefik
+	dynamicConfigMount = "/etc/traefik/dynamic/harborctl.yml"
+)
+
+// defaultMiddlewares são os middlewares padrão dos perfis, sempre definidos no arquivo dinâmico
+var defaultMiddlewares = map[string]map[string]any{
+	"security-headers": {
+		"headers": map[string]any{
+			"frameDeny":            true,
+			"contentTypeNosniff":   true,
+			"browserXssFilter":     true,
+			"referrerPolicy":       "strict-origin-when-cross-origin",
+			"stsSeconds":           31536000,
+			"stsIncludeSubdomains": true,
+			"customResponseHeaders": map[string]string{
+				"X-Powered-By": "",
+				"Server":       "",
+			},
+		},
+	},
+	"rate-limit": {
+		"rateLimit": map[string]any{
+			"average": 100,
+			"burst":   200,
+			"period":  "1s",
+		},
+	},
+	"request-size": {
+		"buffering": map[string]any{
+			"maxRequestBodyBytes": 10 * 1024 * 1024,
+		},
+	},
+}
+
+// fileMiddlewares retorna os middlewares servidos pelo arquivo dinâmico
+func fileMiddlewares(stack *config.Stack) map[string]bool {
+	names := make(map[string]bool, len(defaultMiddlewares))
+	for name := range defaultMiddlewares {
+		names[name] = true
+	}
+	if stack.Traefik != nil {
+		for name := range stack.Traefik.Middlewares {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// qualifyMiddlewares referencia no provider file os middlewares definidos no arquivo dinâmico,
+// já que routers do provider docker só enxergam middlewares sem sufixo do próprio provider
+func qualifyMiddlewares(names []string, env Environment) []string {
+	qualified := make([]string, len(names))
+	for i, name := range names {
+		if !strings.Contains(name, "@") && env.FileMiddlewares[name] {
+			name += "@file"
+		}
+		qualified[i] = name
+	}
+	return qualified
+}
+
+// dynamicBuilder implementa DynamicBuilder
+type dynamicBuilder struct{}
+
+func NewDynamicBuilder() DynamicBuilder {
+	return &dynamicBuilder{}
+}
+
+// Build monta a configuração dinâmica: middlewares, opções TLS e routers para backends fora do Docker
+func (b *dynamicBuilder) Build(ctx context.Context, stack *config.Stack, env Environment) map[string]any {
+	middlewares := make(map[string]any, len(defaultMiddlewares))
+	for name, middleware := range defaultMiddlewares {
+		middlewares[name] = middleware
+	}
+
+	var backends map[string]config.TraefikBackend
+	if stack.Traefik != nil {
+		for name, middleware := range stack.Traefik.Middlewares {
+			middlewares[name] = middlewareConfig(middleware)
+		}
+		backends = stack.Traefik.Backends
+	}
+
+	http := map[string]any{"middlewares": middlewares}
+	if len(backends) > 0 {
+		routers, services := b.buildBackends(stack, env, backends)
+		http["routers"] = routers
+		http["services"] = services
+	}
+
+	dynamic := map[string]any{"http": http}
+
+	// Perfis TLS: versão mínima para todos os routers, perfis da stack e mtls dos serviços
+	if env.TLSEnabled() {
+		tls := map[string]any{
+			"options": buildTLSOptions(stack, env),
+		}
+
+		// Certificados fornecidos pelos domínios próprios dos serviços
+		if certs := domainCertificates(stack); len(certs) > 0 {
+			certificates := make([]map[string]string, len(certs))
+			for i, cert := range certs {
+				certificates[i] = cert.dynamic()
+			}
+			tls["certificates"] = certificates
+		}
+
+		// Certificado padrão emitido pela CA local
+		if env.SelfSignedEnabled(stack.TLS) {
+			tls["stores"] = selfSignedStore()
+		}
+
+		dynamic["tls"] = tls
+	}
+
+	return dynamic
+}
+
+// buildBackends gera router e service do provider file para cada backend
+func (b *dynamicBuilder) buildBackends(stack *config.Stack, env Environment, backends map[string]config.TraefikBackend) (map[string]any, map[string]any) {
+	routers := make(map[string]any, len(backends))
+	services := make(map[string]any, len(backends))
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		backend := backends[name]
+
+		rule := backend.CustomRule()
+		if rule == "" {
+			rule = fmt.Sprintf("Host(`%s.%s`)", backend.Subdomain, stack.Domain)
+		}
+
+		router := map[string]any{
+			"rule":    rule,
+			"service": name,
+		}
+		if env.TLSEnabled() {
+			router["entryPoints"] = []string{"websecure"}
+			tls := map[string]any{}
+			if env.CertResolver != "" {
+				tls["certResolver"] = env.CertResolver
+			}
+			router["tls"] = tls
+		} else {
+			router["entryPoints"] = []string{"web"}
+		}
+
+		middlewares := append(append([]string{}, env.Middlewares...), backend.Middlewares...)
+		if len(middlewares) > 0 {
+			router["middlewares"] = middlewares
+		}
+		routers[name] = router
+
+		servers := make([]map[string]string, len(backend.URLs))
+		for i, url := range backend.URLs {
+			servers[i] = map[string]string{"url": url}
+		}
+		loadBalancer := map[string]any{"servers": servers}
+		if backend.PassHostHeader != nil {
+			loadBalancer["passHostHeader"] = *backend.PassHostHeader
+		}
+		services[name] = map[string]any{"loadBalancer": loadBalancer}
+	}
+
+	return routers, services
+}
+
+// middlewareConfig converte um middleware do stack.yml para o formato dinâmico do Traefik
+func middlewareConfig(m config.TraefikMiddleware) map[string]any {
+	result := make(map[string]any)
+
+	if m.AddPrefix != nil {
+		result["addPrefix"] = m.AddPrefix
+	}
+	if m.StripPrefix != nil {
+		result["stripPrefix"] = m.StripPrefix
+	}
+	if m.ReplacePathRegex != nil {
+		result["replacePathRegex"] = m.ReplacePathRegex
+	}
+	if m.Auth != nil {
+		if m.Auth.Basic != nil {
+			result["basicAuth"] = m.Auth.Basic
+		}
+		if m.Auth.Digest != nil {
+			result["digestAuth"] = m.Auth.Digest
+		}
+		if m.Auth.Forward != nil {
+			result["forwardAuth"] = m.Auth.Forward
+		}
+	}
+	if m.Headers != nil || m.CORS != nil {
+		// CORS no Traefik é configurado pelo middleware headers
+		headers := toMap(m.Headers)
+		for key, value := range toMap(m.CORS) {
+			headers[key] = value
+		}
+		result["headers"] = headers
+	}
+	if m.RateLimit != nil {
+		result["rateLimit"] = m.RateLimit
+	}
+	if m.Retry != nil {
+		result["retry"] = m.Retry
+	}
+	if m.CircuitBreaker != nil {
+		result["circuitBreaker"] = m.CircuitBreaker
+	}
+	if m.Compress != nil {
+		result["compress"] = m.Compress
+	}
+
+	// Demais tipos (buffering, ipAllowList, plugin...) passam sem alteração
+	for key, value := range m.CustomMiddleware {
+		result[key] = value
+	}
+
+	return result
+}
+
+// toMap converte uma struct com tags yaml em mapa
+func toMap(v any) map[string]any {
+	result := make(map[string]any)
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return result
+	}
+	if err := yaml.Unmarshal(data, &result); err != nil || result == nil {
+		return make(map[string]any)
+	}
+	return result
+}
+
+// dynamicProviderCommands habilita o provider file apontando para o arquivo gerado
+func dynamicProviderCommands(commands []string) []string {
+	for _, cmd := range commands {
+		if strings.HasPrefix(cmd, "--providers.file.") {
+			return commands // provider file configurado manualmente
+		}
+	}
+	return append(commands,
+		"--providers.file.filename="+dynamicConfigMount,
+		"--providers.file.watch=true",
+	)
+}
+
+// dynamicConfigVolume monta o arquivo dinâmico, relativo ao diretório do compose
+func dynamicConfigVolume() string {
+	return "./" + DynamicConfigFile + ":" + dynamicConfigMount + ":ro"
+}
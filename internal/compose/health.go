@@ -0,0 +1,140 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+// ProbePath é onde o binário do harborctl é montado nos containers com 'tool: probe'
+const ProbePath = "/usr/local/bin/harborctl-probe"
+
+// ProbeBinary retorna o caminho do harborctl no host. O binário é estático, então roda
+// em qualquer imagem da mesma arquitetura, inclusive distroless
+func ProbeBinary() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "/usr/local/bin/harborctl"
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		return resolved
+	}
+	return exe
+}
+
+// probeVolume monta o harborctl somente leitura no container
+func probeVolume() string {
+	return ProbeBinary() + ":" + ProbePath + ":ro"
+}
+
+// healthTest monta o comando do health check conforme o tipo e a ferramenta disponível na imagem
+func healthTest(healthConfig config.HealthCheck, port int) []string {
+	if healthConfig.Port > 0 {
+		port = healthConfig.Port
+	}
+	host := "localhost"
+	if port > 0 {
+		host = fmt.Sprintf("localhost:%d", port)
+	}
+
+	switch healthConfig.ProbeType() {
+	case "tcp":
+		if healthConfig.ProbeTool() == "probe" {
+			return []string{"CMD", ProbePath, "probe", "tcp", host}
+		}
+		return []string{"CMD-SHELL", fmt.Sprintf("nc -z localhost %d || exit 1", port)}
+	case "grpc":
+		test := []string{"CMD", ProbePath, "probe", "grpc"}
+		if healthConfig.Service != "" {
+			test = append(test, "--service", healthConfig.Service)
+		}
+		return escapeTest(append(test, host))
+	case "exec":
+		return escapeTest(append([]string{"CMD"}, healthConfig.Command...))
+	}
+
+	path := healthConfig.Path
+	if path == "" {
+		path = "/health"
+	}
+	url := "http://" + host + path
+	headers := sortedHeaders(healthConfig.Headers)
+
+	switch healthConfig.ProbeTool() {
+	case "probe":
+		test := []string{"CMD", ProbePath, "probe", "http"}
+		if healthConfig.Method != "" {
+			test = append(test, "--method", healthConfig.Method)
+		}
+		if healthConfig.Status > 0 {
+			test = append(test, "--status", strconv.Itoa(healthConfig.Status))
+		}
+		for _, header := range headers {
+			test = append(test, "--header", header)
+		}
+		return escapeTest(append(test, url))
+	case "wget":
+		args := []string{"wget -q -O /dev/null"}
+		for _, header := range headers {
+			args = append(args, "--header", shellQuote(header))
+		}
+		args = append(args, shellQuote(url), "|| exit 1")
+		return escapeTest([]string{"CMD-SHELL", strings.Join(args, " ")})
+	}
+
+	// curl: -f falha em 4xx/5xx; com status esperado o código é comparado
+	var args []string
+	if healthConfig.Status > 0 {
+		args = append(args, "curl -s -o /dev/null -w '%{http_code}'")
+	} else {
+		args = append(args, "curl -f")
+	}
+	switch method := strings.ToUpper(healthConfig.Method); method {
+	case "", "GET":
+	case "HEAD":
+		args = append(args, "-I")
+	default:
+		args = append(args, "-X", method)
+	}
+	for _, header := range headers {
+		args = append(args, "-H", shellQuote(header))
+	}
+	args = append(args, shellQuote(url))
+	if healthConfig.Status > 0 {
+		args = append(args, "| grep -qx", strconv.Itoa(healthConfig.Status))
+	} else {
+		args = append(args, "|| exit 1")
+	}
+	return escapeTest([]string{"CMD-SHELL", strings.Join(args, " ")})
+}
+
+// sortedHeaders formata os headers como "Nome: valor" em ordem estável
+func sortedHeaders(headers map[string]string) []string {
+	result := make([]string, 0, len(headers))
+	for name, value := range headers {
+		result = append(result, name+": "+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// shellQuote protege um argumento do CMD-SHELL só quando o shell o dividiria
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"$`\\|&;<>()*?[]{}!#~") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// escapeTest escapa "$" para o compose não interpolar o comando
+func escapeTest(test []string) []string {
+	for i, arg := range test {
+		test[i] = strings.ReplaceAll(arg, "$", "$$")
+	}
+	return test
+}
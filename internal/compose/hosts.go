@@ -0,0 +1,87 @@
+package compose
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+	"github.com/leandrodaf/harborctl/pkg/traefikrule"
+)
+
+// hostSNIArg extrai os hosts das regras HostSNI dos routers TCP, que o parser HTTP não aceita
+var hostSNIArg = regexp.MustCompile("HostSNI\\(`([^`]+)`\\)")
+
+// tlsDomainLabel casa as labels tls.domains[n].main e tls.domains[n].sans dos routers
+var tlsDomainLabel = regexp.MustCompile(`^traefik\.(http|tcp)\.routers\.[^.]+\.tls\.domains\[\d+\]\.(main|sans)$`)
+
+// RoutedHosts lista os hostnames roteados pelo Traefik: regras Host() dos routers HTTP,
+// HostSNI dos routers TCP, domínios explícitos em tls.domains e backends
+func (g *GeneratorImpl) RoutedHosts(ctx context.Context, stack *config.Stack) []string {
+	env := GetEnvironmentFromStack(stack)
+
+	hosts := make(map[string]bool)
+	addRule := func(rule string) {
+		matchers, err := traefikrule.Parse(strings.ReplaceAll(rule, "$$", "$"))
+		if err != nil {
+			return // regra inválida já é apontada pelo validate
+		}
+		for _, m := range matchers {
+			if m.Name == "Host" {
+				for _, host := range m.Args {
+					hosts[strings.ToLower(host)] = true
+				}
+			}
+		}
+	}
+	addLabels := func(labels map[string]string) {
+		for key, value := range labels {
+			switch {
+			case strings.HasPrefix(key, "traefik.tcp.routers.") && strings.HasSuffix(key, ".rule"):
+				for _, m := range hostSNIArg.FindAllStringSubmatch(value, -1) {
+					if m[1] != "*" {
+						hosts[strings.ToLower(m[1])] = true
+					}
+				}
+			case strings.HasPrefix(key, "traefik.http.routers.") && strings.HasSuffix(key, ".rule"):
+				addRule(value)
+			case tlsDomainLabel.MatchString(key):
+				for _, host := range strings.Split(value, ",") {
+					if host = strings.TrimSpace(host); host != "" {
+						hosts[strings.ToLower(host)] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, service := range stack.Services {
+		serviceConfig := g.serviceBuilder.BuildWithEnvironment(ctx, service, stack.Domain, env, stack.Project)
+		if labels, ok := serviceConfig["labels"].(map[string]string); ok {
+			addLabels(labels)
+		}
+	}
+	// Observabilidade entra conforme o stack, independente das flags --no-dozzle/--no-beszel
+	for _, service := range g.observabilityBuilder.Build(ctx, stack.Observability, stack.Domain, env, GenerateOptions{}, stack.Project, stack.TLS) {
+		if labels, ok := service["labels"].(map[string]string); ok {
+			addLabels(labels)
+		}
+	}
+	if stack.Traefik != nil {
+		for _, backend := range stack.Traefik.Backends {
+			if rule := backend.CustomRule(); rule != "" {
+				addRule(rule)
+			} else {
+				hosts[backend.Subdomain+"."+stack.Domain] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(hosts))
+	for host := range hosts {
+		result = append(result, host)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -14,6 +14,7 @@ type GeneratorImpl struct {
 	volumeBuilder        VolumeBuilder
 	serviceBuilder       ServiceBuilder
 	traefikBuilder       TraefikBuilder
+	dynamicBuilder       DynamicBuilder
 	observabilityBuilder ObservabilityBuilder
 	marshaler            Marshaler
 }
@@ -25,6 +26,7 @@ func NewMicroGenerator() Generator {
 		volumeBuilder:        NewVolumeBuilder(),
 		serviceBuilder:       NewServiceBuilder(NewHealthChecker(), NewDeployStrategy()),
 		traefikBuilder:       NewTraefikBuilder(),
+		dynamicBuilder:       NewDynamicBuilder(),
 		observabilityBuilder: NewObservabilityBuilder(),
 		marshaler:            NewMarshaler(),
 	}
@@ -55,9 +57,27 @@ func (g *GeneratorImpl) Generate(ctx context.Context, stack *config.Stack, optio
 	// Volumes
 	compose.Volumes = g.volumeBuilder.Build(ctx, stack.Volumes)
 
+	// Serviços esperados até terminarem com sucesso rodam uma vez: não são reiniciados ao sair com 0
+	oneShot := stack.OneShotServices()
+
 	// Services
 	for _, service := range stack.Services {
 		serviceConfig := g.serviceBuilder.BuildWithEnvironment(ctx, service, stack.Domain, env, stack.Project)
+		if oneShot[service.Name] {
+			serviceConfig["restart"] = "on-failure"
+		}
+		if service.Progressive() {
+			// Blue-green e canary: duas cores, o tráfego é dividido no arquivo dinâmico
+			for _, color := range []string{ColorBlue, ColorGreen} {
+				compose.Services[ColorService(service.Name, color)] = colorFragment(service, serviceConfig, color)
+			}
+			continue
+		}
+		if service.Mirror != nil {
+			// Mirror: o shadow recebe uma cópia das requests pelo service de mirroring
+			compose.Services[ShadowService(service.Name)] = shadowFragment(service, serviceConfig)
+			serviceConfig = mirrorFragment(serviceConfig)
+		}
 		compose.Services[service.Name] = serviceConfig
 	}
 
@@ -78,7 +98,73 @@ func (g *GeneratorImpl) Generate(ctx context.Context, stack *config.Stack, optio
 
 	// Marshal
 	return g.marshaler.Marshal(compose)
-} // buildSecrets constrói as secrets do compose
+}
+
+// GenerateDynamic gera a configuração dinâmica do Traefik montada pelo provider file,
+// com os pesos atuais dos serviços blue-green e canary e o mirroring dos shadows
+func (g *GeneratorImpl) GenerateDynamic(ctx context.Context, stack *config.Stack, state RolloutState) ([]byte, error) {
+	env := GetEnvironmentFromStack(stack)
+	dynamic := g.dynamicBuilder.Build(ctx, stack, env)
+	g.addWeightedServices(ctx, dynamic, stack, env, state)
+	g.addMirroringServices(ctx, dynamic, stack, env)
+	return yaml.Marshal(dynamic)
+}
+
+// addWeightedServices acrescenta os services com pesos usados pelos routers das cores
+func (g *GeneratorImpl) addWeightedServices(ctx context.Context, dynamic map[string]any, stack *config.Stack, env Environment, state RolloutState) {
+	http, ok := dynamic["http"].(map[string]any)
+	if !ok {
+		return
+	}
+	services, _ := http["services"].(map[string]any)
+
+	for _, service := range stack.Services {
+		if !service.Progressive() {
+			continue
+		}
+		fragment := g.serviceBuilder.BuildWithEnvironment(ctx, service, stack.Domain, env, stack.Project)
+		labels, _ := fragment["labels"].(map[string]string)
+		for name, weighted := range weightedServices(labels, state.Traffic(service.Name)) {
+			if services == nil {
+				services = make(map[string]any)
+			}
+			services[name] = weighted
+		}
+	}
+
+	if len(services) > 0 {
+		http["services"] = services
+	}
+}
+
+// addMirroringServices acrescenta os services de mirroring usados pelos routers dos serviços com shadow
+func (g *GeneratorImpl) addMirroringServices(ctx context.Context, dynamic map[string]any, stack *config.Stack, env Environment) {
+	http, ok := dynamic["http"].(map[string]any)
+	if !ok {
+		return
+	}
+	services, _ := http["services"].(map[string]any)
+
+	for _, service := range stack.Services {
+		if service.Mirror == nil {
+			continue
+		}
+		fragment := g.serviceBuilder.BuildWithEnvironment(ctx, service, stack.Domain, env, stack.Project)
+		labels, _ := fragment["labels"].(map[string]string)
+		for name, mirroring := range mirroringServices(service, labels) {
+			if services == nil {
+				services = make(map[string]any)
+			}
+			services[name] = mirroring
+		}
+	}
+
+	if len(services) > 0 {
+		http["services"] = services
+	}
+}
+
+// buildSecrets constrói as secrets do compose
 func (g *GeneratorImpl) buildSecrets(compose *ComposeFile, stack *config.Stack) {
 	secretsMap := make(map[string]bool)
 
@@ -97,6 +183,13 @@ func (g *GeneratorImpl) buildSecrets(compose *ComposeFile, stack *config.Stack)
 			}
 		}
 	}
+
+	// Credenciais do DNS challenge entregues ao Traefik
+	if GetEnvironmentFromStack(stack).ACMEEnabled(stack.TLS) {
+		for name, secretConfig := range acmeSecrets(stack) {
+			compose.Secrets[name] = secretConfig
+		}
+	}
 }
 
 // MarshalerImpl implementa Marshaler
@@ -0,0 +1,127 @@
+package compose
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+// DefaultMirrorPercent é a fração de requests copiada para o shadow quando percent é omitido
+const DefaultMirrorPercent = 10
+
+// ShadowService retorna o nome do serviço do compose que roda a versão shadow
+func ShadowService(service string) string {
+	return service + "-shadow"
+}
+
+// mirrorFragment aponta os routers do serviço para o service de mirroring do arquivo dinâmico,
+// que entrega a request ao serviço e uma cópia ao shadow
+func mirrorFragment(fragment map[string]any) map[string]any {
+	labels, ok := fragment["labels"].(map[string]string)
+	if !ok {
+		return fragment
+	}
+
+	mirrored := make(map[string]string, len(labels))
+	for key, value := range labels {
+		mirrored[key] = value
+	}
+	for router, target := range routerServices(labels) {
+		if !strings.Contains(target, "@") {
+			mirrored["traefik.http.routers."+router+".service"] = target + "-mirror@file"
+		}
+	}
+
+	result := make(map[string]any, len(fragment))
+	for key, value := range fragment {
+		result[key] = value
+	}
+	result["labels"] = mirrored
+	return result
+}
+
+// shadowFragment deriva o container shadow do serviço gerado: mesma configuração com a imagem
+// e as variáveis do mirror, sem labels do Traefik (ele só recebe as cópias) e sem réplicas
+func shadowFragment(service config.Service, fragment map[string]any) map[string]any {
+	result := make(map[string]any, len(fragment))
+	for key, value := range fragment {
+		switch key {
+		case "labels", "build":
+			continue
+		}
+		result[key] = value
+	}
+	result["container_name"] = ShadowService(service.Name)
+	result["image"] = service.Mirror.Image
+
+	if len(service.Mirror.Env) > 0 {
+		env := make(map[string]string, len(service.Env)+len(service.Mirror.Env))
+		for key, value := range service.Env {
+			env[key] = value
+		}
+		for key, value := range service.Mirror.Env {
+			env[key] = value
+		}
+		result["environment"] = env
+	}
+
+	if deploy, ok := fragment["deploy"].(map[string]interface{}); ok {
+		single := make(map[string]interface{}, len(deploy))
+		for key, value := range deploy {
+			if key != "replicas" {
+				single[key] = value
+			}
+		}
+		if len(single) > 0 {
+			result["deploy"] = single
+		} else {
+			delete(result, "deploy")
+		}
+	}
+
+	return result
+}
+
+// mirroringServices cria no provider file, para cada service do Traefik das rotas, o service de
+// mirroring e o service do shadow. O shadow é um service do provider file para que o router
+// continue válido mesmo com o container shadow parado
+func mirroringServices(service config.Service, labels map[string]string) map[string]any {
+	seen := make(map[string]bool)
+	var names []string
+	for _, target := range routerServices(labels) {
+		if !strings.Contains(target, "@") && !seen[target] {
+			seen[target] = true
+			names = append(names, target)
+		}
+	}
+	sort.Strings(names)
+
+	percent := service.Mirror.Percent
+	if percent == 0 {
+		percent = DefaultMirrorPercent
+	}
+
+	services := make(map[string]any, len(names)*2)
+	for _, name := range names {
+		port := labels["traefik.http.services."+name+".loadbalancer.server.port"]
+		if port == "" {
+			continue
+		}
+
+		mirroring := map[string]any{
+			"service": name + "@docker",
+			"mirrors": []map[string]any{{"name": name + "-shadow", "percent": percent}},
+		}
+		if service.Mirror.MaxBodySize > 0 {
+			mirroring["maxBodySize"] = service.Mirror.MaxBodySize
+		}
+		services[name+"-mirror"] = map[string]any{"mirroring": mirroring}
+		services[name+"-shadow"] = map[string]any{
+			"loadBalancer": map[string]any{
+				"servers": []map[string]any{{"url": "http://" + ShadowService(service.Name) + ":" + port}},
+			},
+		}
+	}
+	return services
+}
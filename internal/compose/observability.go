@@ -43,7 +43,7 @@ func (o *ObservabilityBuilderImpl) buildDozzle(observability config.Observabilit
 		dockerSocket = observability.DockerSocket
 	}
 
-	if env.IsLocalhost() {
+	if !env.TLSEnabled() {
 		// Configuração para desenvolvimento local
 		entrypoint = "web"
 		if domain == "localhost" {
@@ -69,7 +69,9 @@ func (o *ObservabilityBuilderImpl) buildDozzle(observability config.Observabilit
 			"traefik.http.routers.dozzle.rule":                      fmt.Sprintf("Host(`%s`)", subdomain),
 			"traefik.http.routers.dozzle.entrypoints":               entrypoint,
 			"traefik.http.routers.dozzle.tls":                       "true",
-			"traefik.http.routers.dozzle.tls.certresolver":          tls.Resolver,
+		}
+		if env.CertResolver != "" {
+			labels["traefik.http.routers.dozzle.tls.certresolver"] = env.CertResolver
 		}
 	}
 
@@ -177,12 +179,14 @@ func (o *ObservabilityBuilderImpl) buildBeszelHubLabels(subdomain, project strin
 		"traefik.http.routers.beszel-hub.rule":                      fmt.Sprintf("Host(`%s`)", subdomain),
 	}
 
-	if env.IsLocalhost() {
+	if !env.TLSEnabled() {
 		labels["traefik.http.routers.beszel-hub.entrypoints"] = "web"
 	} else {
 		labels["traefik.http.routers.beszel-hub.entrypoints"] = "web,websecure"
 		labels["traefik.http.routers.beszel-hub.tls"] = "true"
-		labels["traefik.http.routers.beszel-hub.tls.certresolver"] = tls.Resolver
+		if env.CertResolver != "" {
+			labels["traefik.http.routers.beszel-hub.tls.certresolver"] = env.CertResolver
+		}
 		labels["traefik.http.routers.beszel-hub.tls.domains[0].main"] = subdomain
 	}
 
@@ -200,7 +204,7 @@ func (o *ObservabilityBuilderImpl) buildBeszelHubEnvironment(beszel config.Besze
 	if beszel.AppURL != "" {
 		appURL = beszel.AppURL
 	} else {
-		if env.IsLocalhost() {
+		if !env.TLSEnabled() {
 			appURL = fmt.Sprintf("http://monitor.%s", domain)
 		} else {
 			appURL = fmt.Sprintf("https://monitor.%s", domain)
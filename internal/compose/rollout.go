@@ -0,0 +1,147 @@
+package compose
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+const (
+	// RolloutStateFile guarda, ao lado do compose, a cor estável e o peso do canary de cada serviço
+	RolloutStateFile = "rollout.json"
+
+	// ColorBlue e ColorGreen são as duas versões de um serviço blue-green ou canary
+	ColorBlue  = "blue"
+	ColorGreen = "green"
+)
+
+// Traffic é a divisão do tráfego de um serviço entre as duas cores
+type Traffic struct {
+	Stable string `json:"stable"`           // cor que recebe o tráfego estável
+	Canary int    `json:"canary,omitempty"` // porcentagem enviada à outra cor
+}
+
+// Next retorna a cor que recebe a nova versão
+func (t Traffic) Next() string {
+	if t.Stable == ColorGreen {
+		return ColorBlue
+	}
+	return ColorGreen
+}
+
+// RolloutState é a divisão do tráfego de cada serviço blue-green ou canary
+type RolloutState map[string]Traffic
+
+// Traffic retorna a divisão do serviço; sem deploy anterior, todo o tráfego vai para blue
+func (s RolloutState) Traffic(service string) Traffic {
+	if t, ok := s[service]; ok && (t.Stable == ColorBlue || t.Stable == ColorGreen) {
+		return t
+	}
+	return Traffic{Stable: ColorBlue}
+}
+
+// ColorService retorna o serviço do compose de uma cor
+func ColorService(service, color string) string {
+	return service + "-" + color
+}
+
+// ColorServices lista os serviços do compose gerados para as cores
+func ColorServices(stack *config.Stack) map[string]bool {
+	names := make(map[string]bool)
+	for _, service := range stack.Services {
+		if service.Progressive() {
+			names[ColorService(service.Name, ColorBlue)] = true
+			names[ColorService(service.Name, ColorGreen)] = true
+		}
+	}
+	return names
+}
+
+// colorFragment adapta o serviço gerado a uma cor: os services do Traefik ganham o sufixo da cor
+// e os routers apontam para o service com pesos do arquivo dinâmico. Os routers são idênticos
+// nas duas cores, então o Traefik os mescla; o nome do serviço continua resolvendo como alias
+func colorFragment(service config.Service, fragment map[string]any, color string) map[string]any {
+	result := make(map[string]any, len(fragment))
+	for key, value := range fragment {
+		result[key] = value
+	}
+	if _, ok := fragment["container_name"]; ok {
+		result["container_name"] = ColorService(service.Name, color)
+	}
+
+	if labels, ok := fragment["labels"].(map[string]string); ok {
+		colored := make(map[string]string, len(labels))
+		for key, value := range labels {
+			if rest, ok := strings.CutPrefix(key, "traefik.http.services."); ok {
+				name, field, _ := strings.Cut(rest, ".")
+				key = "traefik.http.services." + ColorService(name, color) + "." + field
+			}
+			colored[key] = value
+		}
+		for router, target := range routerServices(labels) {
+			if !strings.Contains(target, "@") {
+				colored["traefik.http.routers."+router+".service"] = target + "@file"
+			}
+		}
+		result["labels"] = colored
+	}
+
+	if networks, ok := fragment["networks"].([]string); ok {
+		aliased := make(map[string]any, len(networks))
+		for _, network := range networks {
+			aliased[network] = map[string]any{"aliases": []string{service.Name}}
+		}
+		result["networks"] = aliased
+	}
+
+	return result
+}
+
+// routerServices mapeia cada router HTTP das labels para o service do Traefik que ele usa
+func routerServices(labels map[string]string) map[string]string {
+	routers := make(map[string]string)
+	for key := range labels {
+		rest, ok := strings.CutPrefix(key, "traefik.http.routers.")
+		if !ok {
+			continue
+		}
+		router, field, _ := strings.Cut(rest, ".")
+		if field != "rule" {
+			continue
+		}
+		if target := labels["traefik.http.routers."+router+".service"]; target != "" {
+			routers[router] = target
+		} else {
+			routers[router] = router
+		}
+	}
+	return routers
+}
+
+// weightedServices cria no provider file um service com pesos por service do Traefik das rotas,
+// citando só as cores com tráfego para não depender de containers parados
+func weightedServices(labels map[string]string, traffic Traffic) map[string]any {
+	seen := make(map[string]bool)
+	var names []string
+	for _, target := range routerServices(labels) {
+		if !strings.Contains(target, "@") && !seen[target] {
+			seen[target] = true
+			names = append(names, target)
+		}
+	}
+	sort.Strings(names)
+
+	services := make(map[string]any, len(names))
+	for _, name := range names {
+		var weighted []map[string]any
+		if weight := 100 - traffic.Canary; weight > 0 {
+			weighted = append(weighted, map[string]any{"name": ColorService(name, traffic.Stable) + "@docker", "weight": weight})
+		}
+		if traffic.Canary > 0 {
+			weighted = append(weighted, map[string]any{"name": ColorService(name, traffic.Next()) + "@docker", "weight": traffic.Canary})
+		}
+		services[name] = map[string]any{"weighted": map[string]any{"services": weighted}}
+	}
+	return services
+}
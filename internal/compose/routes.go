@@ -0,0 +1,192 @@
+package compose
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+// httpRoute é um router HTTP do serviço e o service do Traefik para onde ele aponta
+type httpRoute struct {
+	Router      string
+	Service     string
+	Rule        string
+	EntryPoints []string
+	Middlewares []string // middlewares próprios da rota
+	Priority    int
+	Port        int
+	Domain      *config.ServiceDomain // domínio próprio atendido pelo router, com TLS independente
+	Redirect    bool                  // router que só redireciona para o domínio
+	TLSOptions  string                // perfil TLS do router (tls.options ou mtls do serviço)
+}
+
+// httpRoutes retorna os routers do serviço: um por rota, ou o router padrão Host(subdomain.domain),
+// repetidos para cada domínio próprio
+func (sb *ServiceBuilderImpl) httpRoutes(service config.Service, domain string, env Environment) []httpRoute {
+	traefik := service.GetTraefik()
+
+	entryPoints := []string{"web"}
+	if env.TLSEnabled() {
+		entryPoints = []string{"websecure"}
+	}
+	if traefik != nil && len(traefik.EntryPoints) > 0 {
+		entryPoints = traefik.EntryPoints
+	}
+
+	var priority int
+	if traefik != nil {
+		priority = traefik.Priority
+	}
+
+	var routes []httpRoute
+	if len(service.Routes) == 0 {
+		base := httpRoute{
+			Router:      service.Name,
+			Service:     service.Name,
+			Rule:        fmt.Sprintf("Host(`%s.%s`)", service.Subdomain, domain),
+			EntryPoints: entryPoints,
+			Priority:    priority,
+			Port:        service.Expose,
+		}
+		if traefik != nil && traefik.CustomRule() != "" {
+			base.Rule = traefik.CustomRule()
+			routes = append(routes, base)
+		} else if service.Subdomain != "" {
+			routes = append(routes, base)
+		}
+		routes = append(routes, domainRoutes(service, base, config.Route{})...)
+		return append(routes, redirectRoutes(service, base, env)...)
+	}
+
+	for i, route := range service.Routes {
+		name := service.RouteName(i)
+		r := httpRoute{
+			Router:      name,
+			Service:     name,
+			Rule:        routeRule(route, hostsMatch(service.RouteHosts(route, domain)...)),
+			EntryPoints: entryPoints,
+			Priority:    priority,
+			Port:        service.Expose,
+		}
+		if len(route.EntryPoints) > 0 {
+			r.EntryPoints = route.EntryPoints
+		}
+		if route.Priority > 0 {
+			r.Priority = route.Priority
+		}
+		if route.Port > 0 {
+			r.Port = route.Port
+		}
+		if route.StripPrefix && len(route.Paths) > 0 {
+			r.Middlewares = append(r.Middlewares, name+"-strip")
+		}
+		r.Middlewares = append(r.Middlewares, qualifyMiddlewares(route.Middlewares, env)...)
+
+		if len(route.Hosts) > 0 {
+			routes = append(routes, r)
+			continue
+		}
+		if service.Subdomain != "" {
+			routes = append(routes, r)
+		}
+		routes = append(routes, domainRoutes(service, r, route)...)
+	}
+	if len(routes) > 0 {
+		routes = append(routes, redirectRoutes(service, routes[0], env)...)
+	}
+	return routes
+}
+
+// domainRoutes repete o router base para cada domínio próprio do serviço
+func domainRoutes(service config.Service, base httpRoute, route config.Route) []httpRoute {
+	routes := make([]httpRoute, 0, len(service.Domains))
+	for i := range service.Domains {
+		d := &service.Domains[i]
+		r := base
+		r.Router = base.Router + "-" + d.Slug()
+		r.Rule = routeRule(route, hostsMatch(d.Host))
+		r.Domain = d
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// redirectRoutes cria um router por domínio que redireciona redirect_from (ex: www) para o host
+func redirectRoutes(service config.Service, target httpRoute, env Environment) []httpRoute {
+	var routes []httpRoute
+	for i := range service.Domains {
+		d := &service.Domains[i]
+		if len(d.RedirectFrom) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("%s-%s-redirect", service.Name, d.Slug())
+		routes = append(routes, httpRoute{
+			Router:      name,
+			Service:     target.Service,
+			Rule:        hostsMatch(d.RedirectFrom...).Rule(),
+			EntryPoints: target.EntryPoints,
+			Middlewares: []string{name},
+			Priority:    target.Priority,
+			Port:        target.Port,
+			Domain:      d,
+			Redirect:    true,
+		})
+	}
+	return routes
+}
+
+// hostsMatch casa os hosts pelo nome; curingas (*.example.org) viram HostRegexp
+func hostsMatch(hosts ...string) config.RuleMatch {
+	var match config.RuleMatch
+	for _, host := range hosts {
+		if rest, ok := strings.CutPrefix(host, "*."); ok {
+			match.HostRegexp = append(match.HostRegexp, `^[^.]+\.`+regexp.QuoteMeta(rest)+`$`)
+			continue
+		}
+		match.Host = append(match.Host, host)
+	}
+	return match
+}
+
+// routeRule compila hosts, caminhos, métodos e headers da rota em uma regra do Traefik
+func routeRule(route config.Route, hosts config.RuleMatch) string {
+	hosts.PathPrefix = route.Paths
+	hosts.Method = route.Methods
+	hosts.Header = route.Headers
+	return hosts.Rule()
+}
+
+// addRedirectLabels define os middlewares redirectregex dos domínios com redirect_from
+func (sb *ServiceBuilderImpl) addRedirectLabels(labels map[string]string, service config.Service, env Environment) {
+	scheme := "http"
+	if env.TLSEnabled() {
+		scheme = "https"
+	}
+	for _, d := range service.Domains {
+		if len(d.RedirectFrom) == 0 {
+			continue
+		}
+		hosts := make([]string, len(d.RedirectFrom))
+		for i, host := range d.RedirectFrom {
+			hosts[i] = regexp.QuoteMeta(host)
+		}
+		name := fmt.Sprintf("%s-%s-redirect", service.Name, d.Slug())
+		prefix := fmt.Sprintf("traefik.http.middlewares.%s.redirectregex", name)
+		// $ é duplicado para não ser interpolado pelo Docker Compose
+		labels[prefix+".regex"] = strings.ReplaceAll(fmt.Sprintf("^https?://(?:%s)(?::[0-9]+)?(.*)", strings.Join(hosts, "|")), "$", "$$")
+		labels[prefix+".replacement"] = fmt.Sprintf("%s://%s$${1}", scheme, d.Host)
+		labels[prefix+".permanent"] = "true"
+	}
+}
+
+// addStripPrefixLabels define o middleware que remove os prefixos da rota
+func (sb *ServiceBuilderImpl) addStripPrefixLabels(labels map[string]string, service config.Service) {
+	for i, route := range service.Routes {
+		if route.StripPrefix && len(route.Paths) > 0 {
+			name := service.RouteName(i) + "-strip"
+			labels[fmt.Sprintf("traefik.http.middlewares.%s.stripprefix.prefixes", name)] = strings.Join(route.Paths, ",")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package compose
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+const (
+	// SelfSignedDir guarda os certificados emitidos pela CA local, ao lado do compose
+	SelfSignedDir = "selfsigned"
+
+	// SelfSignedCADir guarda a CA local (ca.crt e ca.key); nunca é montado no Traefik
+	SelfSignedCADir = "selfsigned-ca"
+
+	// SelfSignedCert e SelfSignedKey são os arquivos do certificado padrão em SelfSignedDir
+	SelfSignedCert = "default.crt"
+	SelfSignedKey  = "default.key"
+
+	// selfSignedMount é onde SelfSignedDir é montado no container do Traefik
+	selfSignedMount = "/etc/traefik/selfsigned/"
+)
+
+// selfSignedVolume monta o diretório dos certificados, relativo ao diretório do compose
+func selfSignedVolume() string {
+	return "./" + SelfSignedDir + ":" + selfSignedMount + ":ro"
+}
+
+// selfSignedStore define o certificado emitido pela CA local como padrão do Traefik
+func selfSignedStore() map[string]any {
+	return map[string]any{
+		"default": map[string]any{
+			"defaultCertificate": map[string]string{
+				"certFile": selfSignedMount + SelfSignedCert,
+				"keyFile":  selfSignedMount + SelfSignedKey,
+			},
+		},
+	}
+}
+
+// SelfSignedHosts lista os hostnames cobertos pelo certificado da CA local: o domínio,
+// seu curinga e todos os hosts roteados. Retorna nil quando o modo TLS não é selfsigned
+func (g *GeneratorImpl) SelfSignedHosts(ctx context.Context, stack *config.Stack) []string {
+	env := GetEnvironmentFromStack(stack)
+	if !env.SelfSignedEnabled(stack.TLS) {
+		return nil
+	}
+
+	hosts := []string{stack.Domain, "*." + stack.Domain}
+	for _, host := range g.RoutedHosts(ctx, stack) {
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
@@ -9,11 +9,21 @@ import (
 // Generator gera docker-compose
 type Generator interface {
 	Generate(ctx context.Context, stack *config.Stack, options GenerateOptions) ([]byte, error)
+	GenerateDynamic(ctx context.Context, stack *config.Stack, state RolloutState) ([]byte, error)
+	SelfSignedHosts(ctx context.Context, stack *config.Stack) []string
+	RoutedHosts(ctx context.Context, stack *config.Stack) []string
 }
 
 // Service gerencia geração de compose
 type Service interface {
 	Generate(ctx context.Context, stack *config.Stack, options GenerateOptions) ([]byte, error)
+	// GenerateDynamic gera o arquivo DynamicConfigFile, gravado ao lado do compose,
+	// com a divisão de tráfego dos serviços blue-green e canary
+	GenerateDynamic(ctx context.Context, stack *config.Stack, state RolloutState) ([]byte, error)
+	// SelfSignedHosts lista os hostnames que a CA local deve cobrir (nil fora do modo selfsigned)
+	SelfSignedHosts(ctx context.Context, stack *config.Stack) []string
+	// RoutedHosts lista os hostnames servidos pelo Traefik, que precisam de certificado com TLS
+	RoutedHosts(ctx context.Context, stack *config.Stack) []string
 }
 
 // GenerateOptions configura a geração
@@ -37,3 +47,15 @@ func NewService(generator Generator) Service {
 func (s *service) Generate(ctx context.Context, stack *config.Stack, options GenerateOptions) ([]byte, error) {
 	return s.generator.Generate(ctx, stack, options)
 }
+
+func (s *service) GenerateDynamic(ctx context.Context, stack *config.Stack, state RolloutState) ([]byte, error) {
+	return s.generator.GenerateDynamic(ctx, stack, state)
+}
+
+func (s *service) SelfSignedHosts(ctx context.Context, stack *config.Stack) []string {
+	return s.generator.SelfSignedHosts(ctx, stack)
+}
+
+func (s *service) RoutedHosts(ctx context.Context, stack *config.Stack) []string {
+	return s.generator.RoutedHosts(ctx, stack)
+}
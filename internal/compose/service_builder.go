@@ -27,8 +27,10 @@ func NewServiceBuilder(healthChecker HealthChecker, deployStrategy DeployStrateg
 func (sb *ServiceBuilderImpl) BuildWithEnvironment(ctx context.Context, service config.Service, domain string, env Environment, project string) map[string]any {
 	serviceConfig := make(map[string]interface{})
 
-	// Nome do container
-	serviceConfig["container_name"] = service.Name
+	// Nome do container (o docker compose não escala serviços com container_name)
+	if service.Replicas <= 1 {
+		serviceConfig["container_name"] = service.Name
+	}
 
 	// Image ou build
 	if service.Build != nil {
@@ -81,30 +83,51 @@ func (sb *ServiceBuilderImpl) BuildWithEnvironment(ctx context.Context, service
 		serviceConfig["secrets"] = secrets
 	}
 
-	// Resources
+	// Resources (o perfil do ambiente define limites padrão)
 	if service.Resources != nil {
 		sb.addResourceLimits(serviceConfig, service.Resources)
+	} else if env.Resources != nil {
+		sb.addResourceLimits(serviceConfig, env.Resources)
 	}
 
-	// Health check
-	if service.HealthCheck != nil && service.HealthCheck.Enabled {
+	// Health check; com 'tool: probe' o próprio harborctl é montado para executá-lo
+	if service.HealthCheck != nil {
 		healthConfig := sb.healthChecker.Build(*service.HealthCheck, service.Expose)
 		if healthConfig != nil {
 			serviceConfig["healthcheck"] = healthConfig
 		}
+		if service.HealthCheck.UsesProbe() {
+			volumes, _ := serviceConfig["volumes"].([]string)
+			serviceConfig["volumes"] = append(volumes, probeVolume())
+		}
 	}
 
-	// Deploy configuration
+	// Deploy configuration, somada aos limites de recursos
+	var deployConfig map[string]interface{}
 	if service.Deploy != nil {
-		deployConfig := sb.deployStrategy.Build(*service.Deploy, service.Replicas)
-		if deployConfig != nil {
+		deployConfig = sb.deployStrategy.Build(*service.Deploy, service.Replicas)
+	} else if service.Replicas > 1 {
+		deployConfig = map[string]interface{}{"replicas": service.Replicas}
+	}
+	if len(deployConfig) > 0 {
+		if current, ok := serviceConfig["deploy"].(map[string]interface{}); ok {
+			for key, value := range deployConfig {
+				current[key] = value
+			}
+		} else {
 			serviceConfig["deploy"] = deployConfig
 		}
 	}
 
 	// Labels do Traefik
+	labels := make(map[string]string)
 	if sb.isTraefikEnabled(service) {
-		labels := sb.buildTraefikLabelsWithEnvironment(service, domain, env, project)
+		labels = sb.buildTraefikLabelsWithEnvironment(service, domain, env, project)
+	}
+	if hasStreams(service) {
+		sb.addStreamLabels(labels, service, env, project)
+	}
+	if len(labels) > 0 {
 		serviceConfig["labels"] = labels
 	}
 
@@ -112,11 +135,20 @@ func (sb *ServiceBuilderImpl) BuildWithEnvironment(ctx context.Context, service
 	networks := sb.buildNetworks(service, env)
 	serviceConfig["networks"] = networks
 
+	// Dependências: o compose só cria o serviço depois que elas atingem a condição
+	if len(service.DependsOn) > 0 {
+		dependsOn := make(map[string]interface{}, len(service.DependsOn))
+		for _, dep := range service.DependsOn {
+			dependsOn[dep.Service] = map[string]interface{}{"condition": dep.ComposeCondition()}
+		}
+		serviceConfig["depends_on"] = dependsOn
+	}
+
 	// Restart policy
 	serviceConfig["restart"] = "unless-stopped"
 
-	// Configurações de segurança do container (apenas para produção)
-	if !env.IsLocalhost() {
+	// Configurações de segurança do container (conforme o perfil do ambiente)
+	if env.Hardening {
 		sb.addSecurityConfig(serviceConfig)
 	}
 
@@ -138,96 +170,136 @@ func (sb *ServiceBuilderImpl) buildTraefikLabelsWithEnvironment(service config.S
 	labels["traefik.enable"] = "true"
 	labels["traefik.docker.network"] = project + "_traefik"
 
-	// Port do serviço
-	labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port", serviceName)] = strconv.Itoa(service.Expose)
-
-	// Configurações customizadas vs padrão
-	var rule string
-	var entryPoints []string
+	// Middlewares padrão do ambiente antes dos definidos no serviço
 	var middlewares []string
-	var priority int
+	middlewares = append(middlewares, qualifyMiddlewares(env.Middlewares, env)...)
 
 	traefik := service.GetTraefik()
 	if traefik != nil {
-		// Configurações customizadas
-		if traefik.Rule != "" {
-			rule = traefik.Rule
-		} else {
-			rule = fmt.Sprintf("Host(`%s.%s`)", service.Subdomain, domain)
-		}
+		middlewares = append(middlewares, qualifyMiddlewares(traefik.Middlewares, env)...)
 
-		if len(traefik.EntryPoints) > 0 {
-			entryPoints = traefik.EntryPoints
-		} else {
-			// EntryPoints baseados no ambiente
-			if env.IsLocalhost() {
-				entryPoints = []string{"web"}
-			} else {
-				entryPoints = []string{"websecure"}
-			}
+		// Encaminha o certificado do cliente para o serviço
+		if traefik.Service != nil && traefik.Service.PassTLSCert {
+			passTLSMiddleware := fmt.Sprintf("%s-passtlscert", serviceName)
+			labels[fmt.Sprintf("traefik.http.middlewares.%s.passtlsclientcert.pem", passTLSMiddleware)] = "true"
+			middlewares = append(middlewares, passTLSMiddleware)
 		}
 
-		middlewares = append(middlewares, traefik.Middlewares...)
-		priority = traefik.Priority
-
 		// Labels customizados
 		for key, value := range traefik.Labels {
 			labels[key] = value
 		}
-	} else {
-		// Configuração padrão
-		rule = fmt.Sprintf("Host(`%s.%s`)", service.Subdomain, domain)
-		if env.IsLocalhost() {
-			entryPoints = []string{"web"}
+	}
+
+	// Basic Auth se habilitado
+	var authMiddleware string
+	if service.BasicAuth != nil && service.BasicAuth.Enabled {
+		authMiddleware = fmt.Sprintf("%s-auth", serviceName)
+		labels[fmt.Sprintf("traefik.http.middlewares.%s.basicauth.users", authMiddleware)] = sb.buildBasicAuthUsers(service.BasicAuth)
+	}
+
+	// Um router e um service do Traefik por rota
+	tlsOptions := routerTLSOptions(service, env)
+	for _, route := range sb.httpRoutes(service, domain, env) {
+		route.TLSOptions = tlsOptions
+		if route.Redirect {
+			sb.addRouterLabels(labels, route, traefik, env, route.Middlewares, "")
 		} else {
-			entryPoints = []string{"websecure"}
-			middlewares = []string{"security-headers", "rate-limit", "request-size"}
+			routerMiddlewares := append(append([]string{}, middlewares...), route.Middlewares...)
+			sb.addRouterLabels(labels, route, traefik, env, routerMiddlewares, authMiddleware)
+		}
+		if len(service.Routes) > 0 || route.Router != route.Service {
+			labels[fmt.Sprintf("traefik.http.routers.%s.service", route.Router)] = route.Service
 		}
+
+		labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port", route.Service)] = strconv.Itoa(route.Port)
+		sb.addLoadBalancerLabels(labels, route.Service, service, env)
 	}
+	sb.addStripPrefixLabels(labels, service)
+	sb.addRedirectLabels(labels, service, env)
+
+	return labels
+}
 
-	// Aplicar configurações do router
-	routerName := serviceName
-	labels[fmt.Sprintf("traefik.http.routers.%s.rule", routerName)] = rule
-	labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", routerName)] = strings.Join(entryPoints, ",")
+// addRouterLabels aplica regra, entrypoints, prioridade, TLS e middlewares de um router
+func (sb *ServiceBuilderImpl) addRouterLabels(labels map[string]string, route httpRoute, traefik *config.ServiceTraefik, env Environment, middlewares []string, authMiddleware string) {
+	routerName := route.Router
+	// $ de regexps é duplicado para não ser interpolado pelo Docker Compose
+	labels[fmt.Sprintf("traefik.http.routers.%s.rule", routerName)] = strings.ReplaceAll(route.Rule, "$", "$$")
+	labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", routerName)] = strings.Join(route.EntryPoints, ",")
 
-	if priority > 0 {
-		labels[fmt.Sprintf("traefik.http.routers.%s.priority", routerName)] = strconv.Itoa(priority)
+	if route.Priority > 0 {
+		labels[fmt.Sprintf("traefik.http.routers.%s.priority", routerName)] = strconv.Itoa(route.Priority)
 	}
 
-	// TLS apenas para produção
-	if !env.IsLocalhost() {
-		if traefik != nil && traefik.TLS != nil {
+	// TLS conforme o perfil do ambiente
+	if env.TLSEnabled() {
+		if route.Domain != nil {
+			// Domínio próprio: resolver do domínio, certificado fornecido ou resolver padrão
+			sb.addDomainTLSLabels(labels, routerName, route.Domain, env)
+		} else if traefik != nil && traefik.TLS != nil {
 			// Configurações TLS customizadas
 			labels[fmt.Sprintf("traefik.http.routers.%s.tls", routerName)] = "true"
 			if traefik.TLS.CertResolver != "" {
 				labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = traefik.TLS.CertResolver
-			} else {
-				labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = "letsencrypt"
+			} else if env.CertResolver != "" {
+				labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = env.CertResolver
 			}
-			if traefik.TLS.Options != "" {
-				labels[fmt.Sprintf("traefik.http.routers.%s.tls.options", routerName)] = traefik.TLS.Options
+			for i, d := range traefik.TLS.Domains {
+				labels[fmt.Sprintf("traefik.http.routers.%s.tls.domains[%d].main", routerName, i)] = d.Main
+				if len(d.SANs) > 0 {
+					labels[fmt.Sprintf("traefik.http.routers.%s.tls.domains[%d].sans", routerName, i)] = strings.Join(d.SANs, ",")
+				}
 			}
 		} else {
 			// TLS padrão para produção
 			labels[fmt.Sprintf("traefik.http.routers.%s.tls", routerName)] = "true"
-			labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = "letsencrypt"
+			if env.CertResolver != "" {
+				labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = env.CertResolver
+			}
+		}
+		if route.TLSOptions != "" {
+			labels[fmt.Sprintf("traefik.http.routers.%s.tls.options", routerName)] = route.TLSOptions
 		}
 	}
 
 	// Middlewares
+	if len(middlewares) > 0 && env.Hardening && !route.Redirect {
+		// Adicionar middlewares de timeout padrão em ambientes com hardening
+		timeoutMiddleware := fmt.Sprintf("%s-timeout", routerName)
+		labels[fmt.Sprintf("traefik.http.middlewares.%s.circuitbreaker.expression", timeoutMiddleware)] = "NetworkErrorRatio() > 0.30"
+		middlewares = append(middlewares, timeoutMiddleware)
+	}
+	if authMiddleware != "" {
+		middlewares = append(middlewares, authMiddleware)
+	}
 	if len(middlewares) > 0 {
-		// Adicionar middlewares de timeout padrão para produção
-		if !env.IsLocalhost() {
-			timeoutMiddleware := fmt.Sprintf("%s-timeout", serviceName)
-			labels[fmt.Sprintf("traefik.http.middlewares.%s.forwardauth.authresponseheaders", timeoutMiddleware)] = "X-Forwarded-User"
-			labels[fmt.Sprintf("traefik.http.middlewares.%s.circuitbreaker.expression", timeoutMiddleware)] = "NetworkErrorRatio() > 0.30"
-			middlewares = append(middlewares, timeoutMiddleware)
-		}
-
 		labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", routerName)] = strings.Join(middlewares, ",")
 	}
+}
+
+// addDomainTLSLabels configura o TLS de um router de domínio próprio
+func (sb *ServiceBuilderImpl) addDomainTLSLabels(labels map[string]string, routerName string, d *config.ServiceDomain, env Environment) {
+	labels[fmt.Sprintf("traefik.http.routers.%s.tls", routerName)] = "true"
+
+	switch {
+	case d.CertFile != "":
+		// Certificado servido pelo arquivo dinâmico, escolhido pelo SNI
+	case d.CertResolver != "":
+		labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = d.CertResolver
+	case env.CertResolver != "":
+		labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", routerName)] = env.CertResolver
+	}
 
-	// Configurações de Load Balancer avançadas
+	// Curingas não aparecem em Host(), então o domínio do certificado é explícito
+	if d.Wildcard() && d.CertFile == "" {
+		labels[fmt.Sprintf("traefik.http.routers.%s.tls.domains[0].main", routerName)] = d.Host
+	}
+}
+
+// addLoadBalancerLabels aplica as configurações de load balancer do serviço a um service do Traefik
+func (sb *ServiceBuilderImpl) addLoadBalancerLabels(labels map[string]string, serviceName string, service config.Service, env Environment) {
+	traefik := service.GetTraefik()
 	if traefik != nil && traefik.LoadBalancer != nil {
 		lb := traefik.LoadBalancer
 
@@ -241,6 +313,7 @@ func (sb *ServiceBuilderImpl) buildTraefikLabelsWithEnvironment(service config.S
 			if lb.HealthCheck.Timeout != "" {
 				labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.healthcheck.timeout", serviceName)] = lb.HealthCheck.Timeout
 			}
+			sb.addLBHealthCheckLabels(labels, serviceName, lb.HealthCheck)
 		} else {
 			// Health check padrão para produção
 			if !env.IsLocalhost() {
@@ -260,9 +333,25 @@ func (sb *ServiceBuilderImpl) buildTraefikLabelsWithEnvironment(service config.S
 			if lb.Sticky.Cookie.Name != "" {
 				labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.sticky.cookie.name", serviceName)] = lb.Sticky.Cookie.Name
 			}
+			if lb.Sticky.Cookie.Secure {
+				labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.sticky.cookie.secure", serviceName)] = "true"
+			}
+			if lb.Sticky.Cookie.HTTPOnly {
+				labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.sticky.cookie.httponly", serviceName)] = "true"
+			}
+			if lb.Sticky.Cookie.SameSite != "" {
+				labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.sticky.cookie.samesite", serviceName)] = strings.ToLower(lb.Sticky.Cookie.SameSite)
+			}
 		} else if service.Replicas > 1 {
 			labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.sticky.cookie", serviceName)] = "true"
 		}
+
+		if lb.PassHostHeader != nil {
+			labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.passhostheader", serviceName)] = strconv.FormatBool(*lb.PassHostHeader)
+		}
+		if lb.ServersTransport != "" {
+			labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.serverstransport", serviceName)] = lb.ServersTransport
+		}
 	} else {
 		// Configurações padrão de Load Balancer para produção
 		if !env.IsLocalhost() {
@@ -275,22 +364,33 @@ func (sb *ServiceBuilderImpl) buildTraefikLabelsWithEnvironment(service config.S
 			labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.sticky.cookie", serviceName)] = "true"
 		}
 	}
+}
 
-	// Basic Auth se habilitado
-	if service.BasicAuth != nil && service.BasicAuth.Enabled {
-		middlewareName := fmt.Sprintf("%s-auth", serviceName)
-		labels[fmt.Sprintf("traefik.http.middlewares.%s.basicauth.users", middlewareName)] = sb.buildBasicAuthUsers(service.BasicAuth)
+// addLBHealthCheckLabels adiciona as opções do health check do load balancer além de path/interval/timeout
+func (sb *ServiceBuilderImpl) addLBHealthCheckLabels(labels map[string]string, serviceName string, hc *config.LBHealthCheck) {
+	prefix := fmt.Sprintf("traefik.http.services.%s.loadbalancer.healthcheck", serviceName)
 
-		// Adicionar auth middleware à lista
-		currentMiddlewares := labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", routerName)]
-		if currentMiddlewares != "" {
-			labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", routerName)] = currentMiddlewares + "," + middlewareName
-		} else {
-			labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", routerName)] = middlewareName
-		}
+	if hc.Port > 0 {
+		labels[prefix+".port"] = strconv.Itoa(hc.Port)
+	}
+	if hc.Hostname != "" {
+		labels[prefix+".hostname"] = hc.Hostname
+	}
+	if hc.FollowRedirects {
+		labels[prefix+".followredirects"] = "true"
+	}
+	if hc.Method != "" {
+		labels[prefix+".method"] = strings.ToUpper(hc.Method)
+	}
+	if hc.Status > 0 {
+		labels[prefix+".status"] = strconv.Itoa(hc.Status)
+	}
+	if hc.Scheme != "" {
+		labels[prefix+".scheme"] = hc.Scheme
+	}
+	for name, value := range hc.Headers {
+		labels[fmt.Sprintf("%s.headers.%s", prefix, name)] = value
 	}
-
-	return labels
 }
 
 // addSecurityConfig adiciona configurações de segurança ao container
@@ -405,7 +505,7 @@ func (sb *ServiceBuilderImpl) buildNetworks(service config.Service, env Environm
 	networks = append(networks, "private")
 
 	// Se o serviço tem Traefik habilitado, precisa da rede traefik para roteamento
-	if sb.isTraefikEnabled(service) {
+	if sb.isTraefikEnabled(service) || hasStreams(service) {
 		networks = append(networks, "traefik")
 	}
 
@@ -1,8 +1,6 @@
 package compose
 
 import (
-	"fmt"
-
 	"github.com/leandrodaf/harborctl/internal/config"
 )
 
@@ -16,24 +14,18 @@ func NewHealthChecker() HealthChecker {
 
 // Build constrói a configuração de health check
 func (h *HealthCheckerImpl) Build(healthConfig config.HealthCheck, port int) map[string]interface{} {
+	// none desliga também o HEALTHCHECK da imagem
+	if healthConfig.ProbeType() == "none" {
+		return map[string]interface{}{"disable": true}
+	}
 	if !healthConfig.Enabled {
 		return nil
 	}
 
 	check := make(map[string]interface{})
 
-	// Path padrão se não especificado
-	path := healthConfig.Path
-	if path == "" {
-		path = "/health"
-	}
-
 	// Comando de health check
-	if port > 0 {
-		check["test"] = []string{"CMD-SHELL", fmt.Sprintf("curl -f http://localhost:%d%s || exit 1", port, path)}
-	} else {
-		check["test"] = []string{"CMD-SHELL", fmt.Sprintf("curl -f http://localhost%s || exit 1", path)}
-	}
+	check["test"] = healthTest(healthConfig, port)
 
 	// Intervalo
 	if healthConfig.Interval != "" {
@@ -57,7 +49,11 @@ func (h *HealthCheckerImpl) Build(healthConfig config.HealthCheck, port int) map
 	}
 
 	// Start period
-	check["start_period"] = "60s"
+	if healthConfig.StartPeriod != "" {
+		check["start_period"] = healthConfig.StartPeriod
+	} else {
+		check["start_period"] = "60s"
+	}
 
 	return check
 }
@@ -70,29 +66,11 @@ func NewDeployStrategy() DeployStrategy {
 	return &DeployStrategyImpl{}
 }
 
-// Build constrói a configuração de deploy
+// Build constrói a configuração de deploy. update_config não é gerado: só o Swarm o honra,
+// e rolling, blue-green e canary são conduzidos pelo harborctl no deploy
 func (d *DeployStrategyImpl) Build(deployConfig config.DeployConfig, replicas int) map[string]interface{} {
 	deploy := make(map[string]interface{})
 
-	// Configuração de update
-	updateConfig := make(map[string]interface{})
-
-	switch deployConfig.Strategy {
-	case "recreate":
-		updateConfig["order"] = "stop-first"
-		updateConfig["parallelism"] = 0
-	case "rolling", "":
-		// Rolling update é o padrão
-		updateConfig["order"] = "start-first"
-		updateConfig["parallelism"] = 1
-		updateConfig["delay"] = "10s"
-		updateConfig["failure_action"] = "rollback"
-		updateConfig["monitor"] = "60s"
-		updateConfig["max_failure_ratio"] = 0.3
-	}
-
-	deploy["update_config"] = updateConfig
-
 	// Configuração de restart
 	restartPolicy := make(map[string]interface{})
 	restartPolicy["condition"] = "on-failure"
@@ -0,0 +1,134 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+// Protocolos das rotas de stream
+const (
+	protocolTCP = "tcp"
+	protocolUDP = "udp"
+)
+
+// streamEntryPoint é um entrypoint TCP/UDP criado para rotas de serviços
+type streamEntryPoint struct {
+	Name     string
+	Listen   int
+	Protocol string
+}
+
+// command retorna o argumento do Traefik que declara o entrypoint
+func (ep streamEntryPoint) command() string {
+	address := fmt.Sprintf(":%d", ep.Listen)
+	if ep.Protocol == protocolUDP {
+		address += "/udp"
+	}
+	return fmt.Sprintf("--entrypoints.%s.address=%s", ep.Name, address)
+}
+
+// port retorna a porta publicada no container do Traefik
+func (ep streamEntryPoint) port() string {
+	port := fmt.Sprintf("%d:%d", ep.Listen, ep.Listen)
+	if ep.Protocol == protocolUDP {
+		port += "/udp"
+	}
+	return port
+}
+
+func hasStreams(service config.Service) bool {
+	return len(service.TCP) > 0 || len(service.UDP) > 0
+}
+
+// streamEntryPointName retorna o entrypoint da rota: o informado ou tcp-<porta>/udp-<porta>
+func streamEntryPointName(protocol string, route config.StreamRoute) string {
+	if route.EntryPoint != "" {
+		return route.EntryPoint
+	}
+	return fmt.Sprintf("%s-%d", protocol, route.ListenPort())
+}
+
+// streamEntryPoints lista os entrypoints novos exigidos pelas rotas TCP/UDP dos serviços
+func streamEntryPoints(stack *config.Stack) []streamEntryPoint {
+	seen := make(map[string]bool)
+	var entryPoints []streamEntryPoint
+
+	add := func(protocol string, routes []config.StreamRoute) {
+		for _, route := range routes {
+			if route.EntryPoint != "" {
+				continue // entrypoint já existente
+			}
+			name := streamEntryPointName(protocol, route)
+			if seen[name] {
+				continue // rotas TCP com SNI compartilham o entrypoint
+			}
+			seen[name] = true
+			entryPoints = append(entryPoints, streamEntryPoint{Name: name, Listen: route.ListenPort(), Protocol: protocol})
+		}
+	}
+	for _, service := range stack.Services {
+		add(protocolTCP, service.TCP)
+		add(protocolUDP, service.UDP)
+	}
+
+	sort.Slice(entryPoints, func(i, j int) bool {
+		if entryPoints[i].Listen != entryPoints[j].Listen {
+			return entryPoints[i].Listen < entryPoints[j].Listen
+		}
+		return entryPoints[i].Protocol < entryPoints[j].Protocol
+	})
+	return entryPoints
+}
+
+// hostSNIRule monta a regra do router TCP a partir dos hosts SNI
+func hostSNIRule(hosts []string) string {
+	if len(hosts) == 0 {
+		return "HostSNI(`*`)"
+	}
+	parts := make([]string, len(hosts))
+	for i, host := range hosts {
+		parts[i] = fmt.Sprintf("HostSNI(`%s`)", host)
+	}
+	return strings.Join(parts, " || ")
+}
+
+// addStreamLabels adiciona routers e services traefik.tcp.* e traefik.udp.* do serviço
+func (sb *ServiceBuilderImpl) addStreamLabels(labels map[string]string, service config.Service, env Environment, project string) {
+	labels["traefik.enable"] = "true"
+	labels["traefik.docker.network"] = project + "_traefik"
+
+	for _, route := range service.TCP {
+		name := fmt.Sprintf("%s-tcp-%d", service.Name, route.ListenPort())
+		router := "traefik.tcp.routers." + name
+
+		labels[router+".rule"] = hostSNIRule(route.SNI)
+		labels[router+".entrypoints"] = streamEntryPointName(protocolTCP, route)
+		labels[router+".service"] = name
+
+		switch route.TLS {
+		case "passthrough":
+			labels[router+".tls"] = "true"
+			labels[router+".tls.passthrough"] = "true"
+		case "terminate":
+			labels[router+".tls"] = "true"
+			if env.CertResolver != "" {
+				labels[router+".tls.certresolver"] = env.CertResolver
+			}
+		}
+
+		labels[fmt.Sprintf("traefik.tcp.services.%s.loadbalancer.server.port", name)] = strconv.Itoa(route.Port)
+	}
+
+	for _, route := range service.UDP {
+		name := fmt.Sprintf("%s-udp-%d", service.Name, route.ListenPort())
+		router := "traefik.udp.routers." + name
+
+		labels[router+".entrypoints"] = streamEntryPointName(protocolUDP, route)
+		labels[router+".service"] = name
+		labels[fmt.Sprintf("traefik.udp.services.%s.loadbalancer.server.port", name)] = strconv.Itoa(route.Port)
+	}
+}
@@ -0,0 +1,205 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/config"
+)
+
+const (
+	// InternalCACert é a cópia pública da CA interna em SelfSignedDir, usada para validar clientes
+	InternalCACert = "ca.crt"
+
+	// clientCAMount é onde os bundles de CA de clientes são montados no container do Traefik
+	clientCAMount = "/etc/traefik/clientca/"
+
+	// defaultClientAuthType valida o certificado de cliente e o torna obrigatório
+	defaultClientAuthType = "RequireAndVerifyClientCert"
+)
+
+// clientCAFile é um bundle de CA de clientes montado no Traefik
+type clientCAFile struct {
+	Source string
+	Target string
+}
+
+// mtlsOptionName retorna o nome do perfil TLS gerado para o mtls de um serviço
+func mtlsOptionName(service config.Service) string {
+	return service.Name + "-mtls"
+}
+
+// clientCAFiles mapeia os bundles de um perfil para os caminhos no container;
+// sem bundles, os clientes são validados pela CA interna
+func clientCAFiles(owner string, files []string) []clientCAFile {
+	if len(files) == 0 {
+		return []clientCAFile{{Target: selfSignedMount + InternalCACert}}
+	}
+	result := make([]clientCAFile, len(files))
+	for i, file := range files {
+		result[i] = clientCAFile{Source: file, Target: fmt.Sprintf("%s%s-%d.crt", clientCAMount, owner, i)}
+	}
+	return result
+}
+
+// UsesInternalCA indica se a stack precisa da CA interna: certificados selfsigned
+// ou certificados de cliente validados sem bundles próprios
+func UsesInternalCA(stack *config.Stack) bool {
+	env := GetEnvironmentFromStack(stack)
+	if !env.TLSEnabled() {
+		return false
+	}
+	if env.SelfSignedEnabled(stack.TLS) {
+		return true
+	}
+	for _, options := range stack.TLS.Options {
+		if options.ClientAuth != nil && len(options.ClientAuth.CAFiles) == 0 {
+			return true
+		}
+	}
+	for _, service := range stack.Services {
+		if service.MTLS != nil && len(service.MTLS.CAFiles) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fileTLSOptions retorna os perfis TLS definidos no arquivo dinâmico
+func fileTLSOptions(stack *config.Stack) map[string]bool {
+	names := map[string]bool{"default": true}
+	for name := range stack.TLS.Options {
+		names[name] = true
+	}
+	for _, service := range stack.Services {
+		if service.MTLS != nil {
+			names[mtlsOptionName(service)] = true
+		}
+	}
+	return names
+}
+
+// routerTLSOptions retorna o perfil TLS dos routers de um serviço, referenciado no provider file
+func routerTLSOptions(service config.Service, env Environment) string {
+	if service.MTLS != nil {
+		return mtlsOptionName(service) + "@file"
+	}
+	traefik := service.GetTraefik()
+	if traefik == nil || traefik.TLS == nil || traefik.TLS.Options == "" {
+		return ""
+	}
+	name := traefik.TLS.Options
+	if !strings.Contains(name, "@") && env.FileTLSOptions[name] {
+		name += "@file"
+	}
+	return name
+}
+
+// buildTLSOptions monta tls.options: o perfil default, os perfis da stack e um perfil por serviço com mtls
+func buildTLSOptions(stack *config.Stack, env Environment) map[string]any {
+	compiled := map[string]map[string]any{
+		"default": tlsOptionConfig("default", stack.TLS.Options["default"], map[string]any{
+			"minVersion": "VersionTLS12",
+			"sniStrict":  env.Hardening,
+		}),
+	}
+	for name, options := range stack.TLS.Options {
+		if name != "default" {
+			compiled[name] = tlsOptionConfig(name, options, map[string]any{"minVersion": "VersionTLS12"})
+		}
+	}
+
+	result := make(map[string]any, len(compiled))
+	for name, options := range compiled {
+		result[name] = options
+	}
+
+	for _, service := range stack.Services {
+		if service.MTLS == nil {
+			continue
+		}
+		base := service.MTLS.Options
+		if base == "" {
+			base = "default"
+		}
+		options := make(map[string]any)
+		for key, value := range compiled[base] {
+			options[key] = value
+		}
+		name := mtlsOptionName(service)
+		clientAuthType := defaultClientAuthType
+		if service.MTLS.Optional {
+			clientAuthType = "VerifyClientCertIfGiven"
+		}
+		options["clientAuth"] = clientAuthConfig(clientCAFiles(name, service.MTLS.CAFiles), clientAuthType)
+		result[name] = options
+	}
+	return result
+}
+
+// tlsOptionConfig converte um perfil do stack.yml para o formato dinâmico do Traefik, sobre os padrões
+func tlsOptionConfig(name string, options config.TLSOptions, defaults map[string]any) map[string]any {
+	result := defaults
+	if options.MinVersion != "" {
+		result["minVersion"] = options.MinVersion
+	}
+	if options.MaxVersion != "" {
+		result["maxVersion"] = options.MaxVersion
+	}
+	if len(options.CipherSuites) > 0 {
+		result["cipherSuites"] = options.CipherSuites
+	}
+	if len(options.CurvePreferences) > 0 {
+		result["curvePreferences"] = options.CurvePreferences
+	}
+	if options.SNIStrict != nil {
+		result["sniStrict"] = *options.SNIStrict
+	}
+	if auth := options.ClientAuth; auth != nil {
+		clientAuthType := auth.ClientAuthType
+		if clientAuthType == "" {
+			clientAuthType = defaultClientAuthType
+		}
+		result["clientAuth"] = clientAuthConfig(clientCAFiles(name, auth.CAFiles), clientAuthType)
+	}
+	return result
+}
+
+func clientAuthConfig(files []clientCAFile, clientAuthType string) map[string]any {
+	caFiles := make([]string, len(files))
+	for i, file := range files {
+		caFiles[i] = file.Target
+	}
+	return map[string]any{
+		"caFiles":        caFiles,
+		"clientAuthType": clientAuthType,
+	}
+}
+
+// clientCAVolumes monta, somente leitura, os bundles de CA de clientes fornecidos na stack
+func clientCAVolumes(stack *config.Stack) []string {
+	var files []clientCAFile
+
+	names := make([]string, 0, len(stack.TLS.Options))
+	for name := range stack.TLS.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if auth := stack.TLS.Options[name].ClientAuth; auth != nil && len(auth.CAFiles) > 0 {
+			files = append(files, clientCAFiles(name, auth.CAFiles)...)
+		}
+	}
+	for _, service := range stack.Services {
+		if service.MTLS != nil && len(service.MTLS.CAFiles) > 0 {
+			files = append(files, clientCAFiles(mtlsOptionName(service), service.MTLS.CAFiles)...)
+		}
+	}
+
+	volumes := make([]string, len(files))
+	for i, file := range files {
+		volumes[i] = file.Source + ":" + file.Target + ":ro"
+	}
+	return volumes
+}
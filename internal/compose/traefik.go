@@ -3,6 +3,7 @@ package compose
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/leandrodaf/harborctl/internal/config"
@@ -28,89 +29,42 @@ func (b *traefikBuilder) Build(ctx context.Context, stack *config.Stack, env Env
 		return b.buildCustomTraefik(stack, env)
 	}
 
-	// Configurações padrão baseadas no ambiente
-	if env.IsLocalhost() {
-		args = []string{
-			"--providers.docker=true",
-			"--providers.docker.exposedbydefault=false",
-			"--entrypoints.web.address=:80",
-			"--entrypoints.websecure.address=:443",
-			"--api.dashboard=true",
-			"--api.insecure=true",
-			"--log.level=INFO",
-			"--providers.docker.network=" + stack.Project + "_traefik",
-			"--global.checknewversion=false",
-			"--global.sendanonymoususage=false",
-		}
-		ports = []string{"80:80", "443:443", "8080:8080"}
-		labels = map[string]string{
-			"traefik.enable": "false",
-		}
-		volumes = []string{"/var/run/docker.sock:/var/run/docker.sock:ro"}
-	} else {
-		args = []string{
-			"--providers.docker=true",
-			"--providers.docker.exposedbydefault=false",
-			"--entrypoints.web.address=:80",
-			"--entrypoints.websecure.address=:443",
-			"--entrypoints.websecure.http.tls=true",
-			"--entrypoints.web.http.redirections.entrypoint.to=websecure",
-			"--entrypoints.web.http.redirections.entrypoint.scheme=https",
-			"--entrypoints.web.http.redirections.entrypoint.permanent=true",
-			"--providers.docker.network=" + stack.Project + "_traefik",
-			"--global.checknewversion=false",
-			"--global.sendanonymoususage=false",
-			"--entrypoints.websecure.transport.respondingtimeouts.readtimeout=60s",
-			"--entrypoints.websecure.transport.respondingtimeouts.writetimeout=60s",
-			"--entrypoints.websecure.transport.respondingtimeouts.idletimeout=180s",
-		}
-		ports = []string{"80:80", "443:443"}
-		labels = map[string]string{
-			"traefik.enable": "false",
-		}
-		volumes = []string{"/var/run/docker.sock:/var/run/docker.sock:ro"}
+	// Configurações padrão baseadas no perfil do ambiente
+	args = b.defaultCommands(stack, env)
+	ports = []string{"80:80", "443:443"}
+	if env.Dashboard {
+		ports = append(ports, "8080:8080")
 	}
 
-	// Adiciona configurações ACME apenas se estiver em modo ACME
-	if stack.TLS.Mode == "acme" && !env.IsLocalhost() {
-		res := stack.TLS.Resolver
-		args = append(args,
-			fmt.Sprintf("--certificatesresolvers.%s.acme.email=%s", res, stack.TLS.Email),
-			fmt.Sprintf("--certificatesresolvers.%s.acme.storage=/letsencrypt/acme.json", res),
-		)
+	// Entrypoints TCP/UDP das rotas de stream dos serviços
+	for _, ep := range streamEntryPoints(stack) {
+		args = append(args, ep.command())
+		ports = append(ports, ep.port())
+	}
+	labels = map[string]string{
+		"traefik.enable": "false",
+	}
+	volumes = []string{"/var/run/docker.sock:/var/run/docker.sock:ro", dynamicConfigVolume()}
+	for _, cert := range domainCertificates(stack) {
+		volumes = append(volumes, cert.volumes()...)
+	}
+	if UsesInternalCA(stack) {
+		volumes = append(volumes, selfSignedVolume())
+	}
+	volumes = append(volumes, clientCAVolumes(stack)...)
 
-		if stack.TLS.DNS != nil && stack.TLS.DNS.Provider != "" {
-			// Usar DNS challenge quando configurado
-			args = append(args,
-				fmt.Sprintf("--certificatesresolvers.%s.acme.dnschallenge=true", res),
-				fmt.Sprintf("--certificatesresolvers.%s.acme.dnschallenge.provider=%s", res, stack.TLS.DNS.Provider),
-			)
-
-			// Adicionar variáveis de ambiente do DNS provider se fornecidas
-			if len(stack.TLS.DNS.Env) > 0 {
-				if environment == nil {
-					environment = make(map[string]string)
-				}
-				for _, envVar := range stack.TLS.DNS.Env {
-					// Assume formato KEY=VALUE
-					parts := strings.SplitN(envVar, "=", 2)
-					if len(parts) == 2 {
-						environment[parts[0]] = parts[1]
-					}
-				}
-			}
-		} else {
-			// Usar HTTP challenge como fallback
-			args = append(args,
-				fmt.Sprintf("--certificatesresolvers.%s.acme.httpchallenge=true", res),
-				fmt.Sprintf("--certificatesresolvers.%s.acme.httpchallenge.entrypoint=web", res),
-			)
-		}
+	// Adiciona configurações ACME apenas se estiver em modo ACME
+	var secrets []string
+	if env.ACMEEnabled(stack.TLS) {
+		acme := acmeResolver(stack)
+		args = append(args, acme.commands...)
+		environment = acme.environment
+		secrets = acme.secrets
 	}
 
 	config := map[string]any{
 		"image":    "traefik:v3.5",
-		"command":  args,
+		"command":  dynamicProviderCommands(args),
 		"ports":    ports,
 		"labels":   labels,
 		"networks": []string{"public", "private", "traefik"},
@@ -121,15 +75,18 @@ func (b *traefikBuilder) Build(ctx context.Context, stack *config.Stack, env Env
 	if len(environment) > 0 {
 		config["environment"] = environment
 	}
+	if len(secrets) > 0 {
+		config["secrets"] = secrets
+	}
 
-	// Adiciona volume para ACME apenas em produção
-	if stack.TLS.Mode == "acme" && !env.IsLocalhost() {
-		volumes = append(volumes, "traefik_acme:/letsencrypt")
+	// Adiciona volume para ACME apenas quando o resolver é configurado
+	if env.ACMEEnabled(stack.TLS) {
+		volumes = append(volumes, acmeVolume)
 		config["volumes"] = volumes
 	}
 
-	// Adiciona configurações de segurança apenas em produção
-	if !env.IsLocalhost() {
+	// Adiciona configurações de segurança conforme o perfil do ambiente
+	if env.Hardening {
 		config["security_opt"] = []string{"no-new-privileges:true"}
 		config["read_only"] = true
 		config["tmpfs"] = []string{"/tmp:rw,noexec,nosuid,size=100m"}
@@ -165,38 +122,8 @@ func (b *traefikBuilder) buildCustomTraefik(stack *config.Stack, env Environment
 	if len(traefikConfig.Commands) > 0 {
 		commands = traefikConfig.Commands
 	} else {
-		// Commands padrão baseados no ambiente
-		if env.IsLocalhost() {
-			commands = []string{
-				"--providers.docker=true",
-				"--providers.docker.exposedbydefault=false",
-				"--entrypoints.web.address=:80",
-				"--entrypoints.websecure.address=:443",
-				"--api.dashboard=true",
-				"--api.insecure=true",
-				"--log.level=INFO",
-				"--providers.docker.network=" + stack.Project + "_traefik",
-				"--global.checknewversion=false",
-				"--global.sendanonymoususage=false",
-			}
-		} else {
-			commands = []string{
-				"--providers.docker=true",
-				"--providers.docker.exposedbydefault=false",
-				"--entrypoints.web.address=:80",
-				"--entrypoints.websecure.address=:443",
-				"--entrypoints.websecure.http.tls=true",
-				"--entrypoints.web.http.redirections.entrypoint.to=websecure",
-				"--entrypoints.web.http.redirections.entrypoint.scheme=https",
-				"--entrypoints.web.http.redirections.entrypoint.permanent=true",
-				"--providers.docker.network=" + stack.Project + "_traefik",
-				"--global.checknewversion=false",
-				"--global.sendanonymoususage=false",
-				"--entrypoints.websecure.transport.respondingtimeouts.readtimeout=60s",
-				"--entrypoints.websecure.transport.respondingtimeouts.writetimeout=60s",
-				"--entrypoints.websecure.transport.respondingtimeouts.idletimeout=180s",
-			}
-		}
+		// Commands padrão baseados no perfil do ambiente
+		commands = b.defaultCommands(stack, env)
 	}
 
 	// Adicionar configurações de entry points customizados
@@ -217,11 +144,6 @@ func (b *traefikBuilder) buildCustomTraefik(stack *config.Stack, env Environment
 		}
 	}
 
-	// Adicionar configurações de middlewares customizados
-	for name, middleware := range traefikConfig.Middlewares {
-		commands = append(commands, b.buildMiddlewareCommands(name, middleware)...)
-	}
-
 	// Adicionar configurações de plugins
 	for name, plugin := range traefikConfig.Plugins {
 		commands = append(commands, fmt.Sprintf("--experimental.plugins.%s.modulename=%s", name, plugin.ModuleName))
@@ -280,10 +202,23 @@ func (b *traefikBuilder) buildCustomTraefik(stack *config.Stack, env Environment
 		}
 	}
 
+	// Resolver ACME, a menos que os commands já declarem um resolver com o mesmo nome
+	var acme acmeSettings
+	if env.ACMEEnabled(stack.TLS) && !declaresResolver(commands, stack.TLS.Resolver) {
+		acme = acmeResolver(stack)
+		commands = append(commands, acme.commands...)
+	}
+
 	// Ports
 	ports := []string{"80:80", "443:443", "8080:8080"}
 	if len(traefikConfig.Ports) > 0 {
-		ports = traefikConfig.Ports
+		ports = append([]string{}, traefikConfig.Ports...)
+	}
+
+	// Entrypoints TCP/UDP das rotas de stream dos serviços
+	for _, ep := range streamEntryPoints(stack) {
+		commands = append(commands, ep.command())
+		ports = append(ports, ep.port())
 	}
 
 	// Labels
@@ -295,14 +230,24 @@ func (b *traefikBuilder) buildCustomTraefik(stack *config.Stack, env Environment
 	}
 
 	// Volumes
-	volumes := []string{"/var/run/docker.sock:/var/run/docker.sock:ro"}
+	volumes := []string{"/var/run/docker.sock:/var/run/docker.sock:ro", dynamicConfigVolume()}
+	for _, cert := range domainCertificates(stack) {
+		volumes = append(volumes, cert.volumes()...)
+	}
+	if UsesInternalCA(stack) {
+		volumes = append(volumes, selfSignedVolume())
+	}
+	volumes = append(volumes, clientCAVolumes(stack)...)
 	if len(traefikConfig.Volumes) > 0 {
 		volumes = append(volumes, traefikConfig.Volumes...)
 	}
+	if len(acme.commands) > 0 && !slices.Contains(volumes, acmeVolume) {
+		volumes = append(volumes, acmeVolume)
+	}
 
 	config := map[string]any{
 		"image":    image,
-		"command":  commands,
+		"command":  dynamicProviderCommands(commands),
 		"ports":    ports,
 		"labels":   labels,
 		"networks": []string{"public", "private", "traefik"},
@@ -311,12 +256,22 @@ func (b *traefikBuilder) buildCustomTraefik(stack *config.Stack, env Environment
 	}
 
 	// Environment variables
-	if len(traefikConfig.Environment) > 0 {
-		config["environment"] = traefikConfig.Environment
+	environment := make(map[string]string, len(traefikConfig.Environment)+len(acme.environment))
+	for k, v := range acme.environment {
+		environment[k] = v
+	}
+	for k, v := range traefikConfig.Environment {
+		environment[k] = v
+	}
+	if len(environment) > 0 {
+		config["environment"] = environment
+	}
+	if len(acme.secrets) > 0 {
+		config["secrets"] = acme.secrets
 	}
 
-	// Adiciona configurações de segurança apenas em produção
-	if !env.IsLocalhost() {
+	// Adiciona configurações de segurança conforme o perfil do ambiente
+	if env.Hardening {
 		config["security_opt"] = []string{"no-new-privileges:true"}
 		config["read_only"] = true
 		config["tmpfs"] = []string{"/tmp:rw,noexec,nosuid,size=100m"}
@@ -325,39 +280,56 @@ func (b *traefikBuilder) buildCustomTraefik(stack *config.Stack, env Environment
 	return config
 }
 
-// buildMiddlewareCommands constrói comandos para middlewares customizados
-func (b *traefikBuilder) buildMiddlewareCommands(name string, middleware config.TraefikMiddleware) []string {
-	var commands []string
-
-	if middleware.AddPrefix != nil {
-		commands = append(commands, fmt.Sprintf("--http.middlewares.%s.addprefix.prefix=%s", name, middleware.AddPrefix.Prefix))
+// defaultCommands monta os argumentos padrão do Traefik a partir do perfil do ambiente
+func (b *traefikBuilder) defaultCommands(stack *config.Stack, env Environment) []string {
+	commands := []string{
+		"--providers.docker=true",
+		"--providers.docker.exposedbydefault=false",
+		"--entrypoints.web.address=:80",
+		"--entrypoints.websecure.address=:443",
 	}
 
-	if middleware.StripPrefix != nil {
-		for _, prefix := range middleware.StripPrefix.Prefixes {
-			commands = append(commands, fmt.Sprintf("--http.middlewares.%s.stripprefix.prefixes=%s", name, prefix))
-		}
-		if middleware.StripPrefix.ForceSlash {
-			commands = append(commands, fmt.Sprintf("--http.middlewares.%s.stripprefix.forceslash=true", name))
-		}
+	// HTTPS com redirecionamento de HTTP
+	if env.TLSEnabled() {
+		commands = append(commands,
+			"--entrypoints.websecure.http.tls=true",
+			"--entrypoints.web.http.redirections.entrypoint.to=websecure",
+			"--entrypoints.web.http.redirections.entrypoint.scheme=https",
+			"--entrypoints.web.http.redirections.entrypoint.permanent=true",
+		)
 	}
 
-	if middleware.ReplacePathRegex != nil {
-		commands = append(commands, fmt.Sprintf("--http.middlewares.%s.replacepathregex.regex=%s", name, middleware.ReplacePathRegex.Regex))
-		commands = append(commands, fmt.Sprintf("--http.middlewares.%s.replacepathregex.replacement=%s", name, middleware.ReplacePathRegex.Replacement))
+	if env.Dashboard {
+		commands = append(commands, "--api.dashboard=true", "--api.insecure=true")
+	}
+	if env.IsLocalhost() {
+		commands = append(commands, "--log.level=INFO")
 	}
 
-	if middleware.RateLimit != nil {
-		if middleware.RateLimit.Average > 0 {
-			commands = append(commands, fmt.Sprintf("--http.middlewares.%s.ratelimit.average=%d", name, middleware.RateLimit.Average))
-		}
-		if middleware.RateLimit.Period != "" {
-			commands = append(commands, fmt.Sprintf("--http.middlewares.%s.ratelimit.period=%s", name, middleware.RateLimit.Period))
-		}
-		if middleware.RateLimit.Burst > 0 {
-			commands = append(commands, fmt.Sprintf("--http.middlewares.%s.ratelimit.burst=%d", name, middleware.RateLimit.Burst))
-		}
+	commands = append(commands,
+		"--providers.docker.network="+stack.Project+"_traefik",
+		"--global.checknewversion=false",
+		"--global.sendanonymoususage=false",
+	)
+
+	// Timeouts do entrypoint seguro
+	if !env.IsLocalhost() {
+		commands = append(commands,
+			"--entrypoints.websecure.transport.respondingtimeouts.readtimeout=60s",
+			"--entrypoints.websecure.transport.respondingtimeouts.writetimeout=60s",
+			"--entrypoints.websecure.transport.respondingtimeouts.idletimeout=180s",
+		)
 	}
 
 	return commands
 }
+
+// declaresResolver indica se os commands já configuram o cert resolver
+func declaresResolver(commands []string, resolver string) bool {
+	for _, cmd := range commands {
+		if strings.HasPrefix(cmd, "--certificatesresolvers."+resolver+".") {
+			return true
+		}
+	}
+	return false
+}
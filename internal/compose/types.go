@@ -14,29 +14,83 @@ type ComposeFile struct {
 	Secrets  map[string]map[string]any `yaml:"secrets,omitempty"`
 }
 
-// Environment define o tipo de ambiente
-type Environment string
+// Environment descreve o perfil de ambiente usado na geração do compose
+type Environment struct {
+	Name        string
+	Preset      string            // local ou production: padrões não cobertos pelo perfil
+	TLSMode     string            // vazio segue tls.mode da stack
+	Hardening   bool              // configurações de segurança dos containers
+	Middlewares []string          // middlewares padrão dos serviços roteados
+	Dashboard   bool              // dashboard do Traefik exposto
+	Resources   *config.Resources // limites para serviços sem resources
 
-const (
-	EnvironmentLocal      Environment = "local"
-	EnvironmentProduction Environment = "production"
+	FileMiddlewares map[string]bool // middlewares definidos no arquivo dinâmico do Traefik
+	FileTLSOptions  map[string]bool // perfis TLS definidos no arquivo dinâmico do Traefik
+	CertResolver    string          // resolver ACME dos routers; vazio sem ACME
+}
+
+var (
+	// EnvironmentLocal é o preset de desenvolvimento: HTTP, sem hardening, dashboard exposto
+	EnvironmentLocal = Environment{
+		Name:      config.PresetLocal,
+		Preset:    config.PresetLocal,
+		TLSMode:   "disabled",
+		Dashboard: true,
+	}
+
+	// EnvironmentProduction é o preset de produção: TLS, hardening e middlewares de segurança
+	EnvironmentProduction = Environment{
+		Name:        config.PresetProduction,
+		Preset:      config.PresetProduction,
+		Hardening:   true,
+		Middlewares: []string{"security-headers", "rate-limit", "request-size"},
+	}
 )
 
-// IsLocalhost verifica se o ambiente é local
+// IsLocalhost verifica se o ambiente usa o preset local
 func (env Environment) IsLocalhost() bool {
-	return env == EnvironmentLocal
+	return env.Preset == config.PresetLocal
 }
 
-// GetEnvironmentFromStack obtém o ambiente do stack ou detecta pelo domínio como fallback
+// TLSEnabled indica se os routers usam websecure com TLS
+func (env Environment) TLSEnabled() bool {
+	return env.TLSMode != "disabled"
+}
+
+// ACMEEnabled indica se o resolver ACME deve ser configurado
+func (env Environment) ACMEEnabled(tls config.TLS) bool {
+	mode := env.TLSMode
+	if mode == "" {
+		mode = tls.Mode
+	}
+	return mode == "acme"
+}
+
+// SelfSignedEnabled indica se os certificados são emitidos pela CA local do harborctl
+func (env Environment) SelfSignedEnabled(tls config.TLS) bool {
+	mode := env.TLSMode
+	if mode == "" {
+		mode = tls.Mode
+	}
+	return mode == "selfsigned"
+}
+
+// GetEnvironmentFromStack resolve o perfil do stack, o preset pelo nome ou detecta pelo domínio como fallback
 func GetEnvironmentFromStack(stack *config.Stack) Environment {
+	env := stackEnvironment(stack)
+	env.FileMiddlewares = fileMiddlewares(stack)
+	env.FileTLSOptions = fileTLSOptions(stack)
+	if env.ACMEEnabled(stack.TLS) {
+		env.CertResolver = stack.TLS.Resolver
+	}
+	return env
+}
+
+func stackEnvironment(stack *config.Stack) Environment {
 	// Prioriza o valor explícito do environment no stack
 	if stack.Environment != "" {
-		env := strings.ToLower(stack.Environment)
-		if env == "local" || env == "development" || env == "dev" {
-			return EnvironmentLocal
-		}
-		if env == "production" || env == "prod" {
-			return EnvironmentProduction
+		if env, ok := resolveProfile(stack, strings.ToLower(stack.Environment), map[string]bool{}); ok {
+			return env
 		}
 	}
 
@@ -48,3 +102,66 @@ func GetEnvironmentFromStack(stack *config.Stack) Environment {
 	}
 	return EnvironmentProduction
 }
+
+func presetEnvironment(preset string) Environment {
+	if preset == config.PresetLocal {
+		return EnvironmentLocal
+	}
+	return EnvironmentProduction
+}
+
+// resolveProfile aplica um perfil declarado sobre o perfil que ele estende
+func resolveProfile(stack *config.Stack, name string, visiting map[string]bool) (Environment, bool) {
+	preset, isPreset := config.PresetName(name)
+
+	profile, declared := stack.Profiles[name]
+	if !declared {
+		if !isPreset {
+			return Environment{}, false
+		}
+		return presetEnvironment(preset), true
+	}
+
+	if visiting[name] {
+		return Environment{}, false // ciclo, reportado pelo validate
+	}
+	visiting[name] = true
+
+	// Perfis com nome de preset o personalizam; os demais estendem production por padrão
+	parent := strings.ToLower(profile.Extends)
+	if parent == "" {
+		parent = config.PresetProduction
+		if isPreset {
+			parent = preset
+		}
+	}
+
+	var env Environment
+	if parentPreset, ok := config.PresetName(parent); ok && (parent == name || parentPreset == preset) {
+		env = presetEnvironment(parentPreset)
+	} else {
+		var ok bool
+		if env, ok = resolveProfile(stack, parent, visiting); !ok {
+			return Environment{}, false
+		}
+	}
+
+	env.Name = name
+	if profile.TLSMode != "" {
+		env.TLSMode = profile.TLSMode
+	}
+	if profile.Hardening != nil {
+		env.Hardening = *profile.Hardening
+	}
+	if profile.Middlewares != nil {
+		env.Middlewares = profile.Middlewares
+	}
+	if profile.Dashboard != nil {
+		env.Dashboard = *profile.Dashboard
+	}
+	if profile.Resources != nil {
+		env.Resources = profile.Resources
+	}
+
+	return env, true
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	envVarFormat     = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	secretNameFormat = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+)
+
+// checkACME valida as opções do resolver ACME: CA, EAB, domínios explícitos e DNS challenge
+func checkACME(stack *Stack, report reportFunc) {
+	tls := stack.TLS
+
+	if tls.Staging && tls.CAServer != "" {
+		report("tls.staging", "tls.staging and tls.caServer are mutually exclusive", "staging selects the Let's Encrypt staging CA, remove one of them")
+	}
+	if tls.CAServer != "" {
+		if u, err := url.Parse(tls.CAServer); err != nil || u.Scheme != "https" || u.Host == "" {
+			report("tls.caServer", fmt.Sprintf("invalid caServer %q", tls.CAServer), "use the https URL of the CA's ACME directory")
+		}
+	}
+
+	if eab := tls.EAB; eab != nil {
+		if eab.KID == "" {
+			report("tls.eab.kid", "tls.eab needs 'kid'", "copy the key identifier issued by the CA")
+		}
+		if eab.HMAC == "" {
+			report("tls.eab.hmac", "tls.eab needs 'hmac'", "copy the HMAC key issued by the CA, preferably as ${VARIABLE}")
+		}
+		if tls.CAServer == "" {
+			report("tls.eab", "tls.eab requires tls.caServer", "Let's Encrypt does not use EAB, set caServer to the CA that issued the credentials")
+		}
+	}
+
+	dnsChallenge := ResolverChallenges(stack)[tls.Resolver] == ChallengeDNS
+	for i, d := range tls.Domains {
+		path := fmt.Sprintf("tls.domains[%d]", i)
+		hosts := d.SANs
+		if d.Main == "" {
+			report(path+".main", "tls domain needs 'main'", "set the certificate's main domain (ex: example.com)")
+		} else {
+			hosts = append([]string{d.Main}, d.SANs...)
+		}
+		for _, host := range hosts {
+			if !hostnameFormat.MatchString(host) {
+				report(path, fmt.Sprintf("invalid tls domain %q", host), "use a hostname without scheme or port (ex: *.example.com)")
+			} else if strings.HasPrefix(host, "*.") && tls.Mode == "acme" && !dnsChallenge {
+				report(path, fmt.Sprintf("wildcard %s needs the DNS challenge", host), "configure 'tls.dnsChallenge'")
+			}
+		}
+	}
+
+	if tls.DNS != nil {
+		checkDNSChallenge(tls.DNS, report)
+	}
+}
+
+func checkDNSChallenge(dns *DNSChallenge, report reportFunc) {
+	path := "tls.dnsChallenge"
+	if dns.Provider == "" && (len(dns.Credentials) > 0 || len(dns.Resolvers) > 0 || dns.PropagationDelay != "" || dns.DisablePropagationCheck) {
+		report(path+".provider", "dnsChallenge options require a provider", "set the lego provider name (ex: cloudflare)")
+	}
+
+	plain := make(map[string]bool, len(dns.Env))
+	for _, entry := range dns.Env {
+		if key, _, ok := strings.Cut(entry, "="); ok {
+			plain[key] = true
+		}
+	}
+
+	for _, variable := range sortedKeys(dns.Credentials) {
+		credential := dns.Credentials[variable]
+		credPath := path + ".credentials." + variable
+		if !envVarFormat.MatchString(variable) {
+			report(credPath, fmt.Sprintf("invalid credential variable %q", variable), "use the provider's variable name (ex: CF_DNS_API_TOKEN)")
+		}
+		if strings.HasSuffix(variable, "_FILE") {
+			report(credPath, fmt.Sprintf("credential %s already ends in _FILE", variable), "use the plain variable name, harborctl appends _FILE")
+		}
+		if (credential.File == "") == (credential.Secret == "") {
+			report(credPath, fmt.Sprintf("credential %s needs exactly one of 'file' or 'secret'", variable), "read it from a host file or an existing Docker secret")
+		}
+		if credential.Secret != "" && !secretNameFormat.MatchString(credential.Secret) {
+			report(credPath+".secret", fmt.Sprintf("invalid secret name %q", credential.Secret), "use letters, digits and - _ .")
+		}
+		if plain[variable] {
+			report(credPath, fmt.Sprintf("%s is also set in dnsChallenge.env", variable), "remove the plaintext value from env")
+		}
+	}
+
+	for i, resolver := range dns.Resolvers {
+		if host, port, err := net.SplitHostPort(resolver); err != nil || host == "" || port == "" {
+			report(fmt.Sprintf("%s.resolvers[%d]", path, i), fmt.Sprintf("invalid DNS resolver %q", resolver), "use host:port (ex: 1.1.1.1:53)")
+		}
+	}
+	if dns.PropagationDelay != "" && !validDuration(dns.PropagationDelay) {
+		report(path+".propagationDelay", fmt.Sprintf("invalid propagationDelay %q", dns.PropagationDelay), "use a duration like 30s or 2m")
+	}
+}
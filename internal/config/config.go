@@ -5,21 +5,52 @@ type Stack struct {
 	Version       int                `yaml:"version"`
 	Project       string             `yaml:"project"`
 	Domain        string             `yaml:"domain"`
-	Environment   string             `yaml:"environment"` // local | production
+	Environment   string             `yaml:"environment"` // local | production | perfil declarado
+	Profiles      map[string]Profile `yaml:"profiles,omitempty"`
 	TLS           TLS                `yaml:"tls"`
 	Traefik       *TraefikConfig     `yaml:"traefik,omitempty"`
 	Observability Observability      `yaml:"observability"`
 	Networks      map[string]Network `yaml:"networks"`
 	Volumes       []Volume           `yaml:"volumes"`
 	Services      []Service          `yaml:"services"`
+
+	source *sourceMap // posições dos campos no arquivo de origem (preenchido por Load)
 }
 
 // TLS configura SSL/TLS
 type TLS struct {
-	Mode     string        `yaml:"mode"` // acme | selfsigned | disabled
-	Email    string        `yaml:"email"`
-	Resolver string        `yaml:"resolver"`
-	DNS      *DNSChallenge `yaml:"dnsChallenge,omitempty"`
+	Mode     string                `yaml:"mode"` // acme | selfsigned | disabled
+	Email    string                `yaml:"email"`
+	Resolver string                `yaml:"resolver"`
+	Staging  bool                  `yaml:"staging,omitempty"`  // CA de staging do Let's Encrypt
+	CAServer string                `yaml:"caServer,omitempty"` // diretório ACME de outra CA
+	EAB      *ACMEEAB              `yaml:"eab,omitempty"`
+	Domains  []Domain              `yaml:"domains,omitempty"` // certificados pedidos explicitamente (curingas)
+	DNS      *DNSChallenge         `yaml:"dnsChallenge,omitempty"`
+	Options  map[string]TLSOptions `yaml:"options,omitempty"` // perfis de opções TLS do Traefik
+}
+
+// TLSOptions é um perfil de opções TLS do Traefik, referenciado por traefik.tls.options e mtls.options;
+// o perfil "default" vale para todos os routers sem outro perfil
+type TLSOptions struct {
+	MinVersion       string         `yaml:"minVersion,omitempty"` // VersionTLS10 .. VersionTLS13
+	MaxVersion       string         `yaml:"maxVersion,omitempty"`
+	CipherSuites     []string       `yaml:"cipherSuites,omitempty"`
+	CurvePreferences []string       `yaml:"curvePreferences,omitempty"`
+	SNIStrict        *bool          `yaml:"sniStrict,omitempty"`
+	ClientAuth       *TLSClientAuth `yaml:"clientAuth,omitempty"`
+}
+
+// TLSClientAuth exige certificados de cliente assinados pelas CAs informadas
+type TLSClientAuth struct {
+	CAFiles        []string `yaml:"caFiles,omitempty"`        // vazio: CA interna do harborctl
+	ClientAuthType string   `yaml:"clientAuthType,omitempty"` // padrão: RequireAndVerifyClientCert
+}
+
+// ACMEEAB é o External Account Binding exigido por CAs como ZeroSSL e Google
+type ACMEEAB struct {
+	KID  string `yaml:"kid"`
+	HMAC string `yaml:"hmac"` // chave HMAC em base64
 }
 
 // TraefikConfig configura o Traefik
@@ -38,6 +69,34 @@ type TraefikConfig struct {
 	Log         *TraefikLog                  `yaml:"log,omitempty"`
 	AccessLog   *TraefikAccessLog            `yaml:"accessLog,omitempty"`
 	Metrics     *TraefikMetrics              `yaml:"metrics,omitempty"`
+	Backends    map[string]TraefikBackend    `yaml:"backends,omitempty"`
+}
+
+// RuleMatch descreve uma regra do Traefik em campos: valores de um campo são
+// alternativos (||) e campos diferentes precisam casar juntos (&&)
+type RuleMatch struct {
+	Host         []string          `yaml:"host,omitempty"`
+	HostRegexp   []string          `yaml:"hostRegexp,omitempty"`
+	Path         []string          `yaml:"path,omitempty"`
+	PathPrefix   []string          `yaml:"pathPrefix,omitempty"`
+	PathRegexp   []string          `yaml:"pathRegexp,omitempty"`
+	Method       []string          `yaml:"method,omitempty"`
+	Header       map[string]string `yaml:"header,omitempty"`
+	HeaderRegexp map[string]string `yaml:"headerRegexp,omitempty"`
+	Query        map[string]string `yaml:"query,omitempty"`
+	ClientIP     []string          `yaml:"clientIP,omitempty"`
+	Any          []RuleMatch       `yaml:"any,omitempty"` // pelo menos um dos blocos
+	Not          *RuleMatch        `yaml:"not,omitempty"` // nega o bloco
+}
+
+// TraefikBackend roteia para um endereço fora do Docker (gerado no arquivo dinâmico)
+type TraefikBackend struct {
+	URLs           []string   `yaml:"urls"`
+	Subdomain      string     `yaml:"subdomain,omitempty"`
+	Rule           string     `yaml:"rule,omitempty"`  // sobrescreve Host(subdomain.domain)
+	Match          *RuleMatch `yaml:"match,omitempty"` // regra estruturada, alternativa a rule
+	Middlewares    []string   `yaml:"middlewares,omitempty"`
+	PassHostHeader *bool      `yaml:"passHostHeader,omitempty"`
 }
 
 // TraefikMiddleware define um middleware customizado
@@ -368,8 +427,19 @@ type InfluxDBMetrics struct {
 
 // DNSChallenge configura DNS challenge
 type DNSChallenge struct {
-	Provider string   `yaml:"provider"`
-	Env      []string `yaml:"env"`
+	Provider                string                   `yaml:"provider"`
+	Env                     []string                 `yaml:"env"`
+	Credentials             map[string]DNSCredential `yaml:"credentials,omitempty"` // variável do provider -> origem
+	Resolvers               []string                 `yaml:"resolvers,omitempty"`
+	PropagationDelay        string                   `yaml:"propagationDelay,omitempty"`
+	DisablePropagationCheck bool                     `yaml:"disablePropagationCheck,omitempty"`
+}
+
+// DNSCredential é uma credencial do provider DNS lida de um arquivo ou de uma Docker secret,
+// entregue ao Traefik como <VARIÁVEL>_FILE
+type DNSCredential struct {
+	File   string `yaml:"file,omitempty"`   // arquivo no host, vira uma secret do compose
+	Secret string `yaml:"secret,omitempty"` // secret externa já criada no Docker
 }
 
 // Observability configura monitoramento
@@ -433,6 +503,13 @@ type Service struct {
 	Image         string            `yaml:"image,omitempty"`
 	Build         *BuildSpec        `yaml:"build,omitempty"`
 	Expose        int               `yaml:"expose"`
+	TCP           []StreamRoute     `yaml:"tcp,omitempty"`        // portas TCP roteadas pelo Traefik
+	UDP           []StreamRoute     `yaml:"udp,omitempty"`        // portas UDP roteadas pelo Traefik
+	Routes        []Route           `yaml:"routes,omitempty"`     // routers HTTP (padrão: Host(subdomain.domain))
+	Domains       []ServiceDomain   `yaml:"domains,omitempty"`    // domínios próprios além de subdomain.domain
+	MTLS          *ServiceMTLS      `yaml:"mtls,omitempty"`       // exige certificado de cliente nos routers
+	Mirror        *ServiceMirror    `yaml:"mirror,omitempty"`     // versão shadow que recebe cópias das requisições
+	DependsOn     []Dependency      `yaml:"depends_on,omitempty"` // serviços que sobem antes, com a condição esperada
 	Replicas      int               `yaml:"replicas,omitempty"`
 	Env           map[string]string `yaml:"env,omitempty"`
 	EnvFile       []string          `yaml:"env_file,omitempty"`
@@ -441,78 +518,77 @@ type Service struct {
 	Resources     *Resources        `yaml:"resources,omitempty"`
 	HealthCheck   *HealthCheck      `yaml:"health_check,omitempty"`
 	Deploy        *DeployConfig     `yaml:"deploy,omitempty"`
-	TraefikRaw    interface{}       `yaml:"traefik,omitempty"`
+	Traefik       *ServiceTraefik   `yaml:"traefik,omitempty"` // true/false ou configuração detalhada
 	BasicAuth     *BasicAuth        `yaml:"basic_auth,omitempty"`
 	NetworkAccess *NetworkAccess    `yaml:"network_access,omitempty"`
 }
 
-// GetTraefik retorna a configuração do Traefik com compatibilidade para ambos os formatos
-func (s *Service) GetTraefik() *ServiceTraefik {
-	if s.TraefikRaw == nil {
-		return nil
+// ServiceMirror implanta uma versão shadow do serviço e copia para ela parte das requisições
+// pelo serviço de mirroring do Traefik; as respostas da shadow são descartadas
+type ServiceMirror struct {
+	Image       string            `yaml:"image"`                   // versão testada
+	Percent     int               `yaml:"percent,omitempty"`       // porcentagem das requisições copiadas (padrão: 10)
+	Env         map[string]string `yaml:"env,omitempty"`           // sobrescreve o env do serviço (ex: banco de testes)
+	MaxBodySize int64             `yaml:"max_body_size,omitempty"` // corpo máximo copiado em bytes (padrão: sem limite)
+}
+
+// ServiceMTLS exige certificados de cliente nos routers HTTP do serviço
+type ServiceMTLS struct {
+	CAFiles  []string `yaml:"ca_files,omitempty"` // vazio: CA interna do harborctl
+	Optional bool     `yaml:"optional,omitempty"` // aceita clientes sem certificado (VerifyClientCertIfGiven)
+	Options  string   `yaml:"options,omitempty"`  // perfil base em tls.options (padrão: default)
+}
+
+// StreamRoute expõe uma porta TCP ou UDP do container por um entrypoint do Traefik
+type StreamRoute struct {
+	Port       int      `yaml:"port"`                 // porta do container
+	Listen     int      `yaml:"listen,omitempty"`     // porta publicada pelo Traefik (padrão: port)
+	EntryPoint string   `yaml:"entrypoint,omitempty"` // entrypoint existente em vez de um novo (ex: websecure)
+	SNI        []string `yaml:"sni,omitempty"`        // TCP: hosts do HostSNI (padrão: *)
+	TLS        string   `yaml:"tls,omitempty"`        // TCP: none | passthrough | terminate
+}
+
+// ServiceDomain é um domínio próprio do serviço, com certificado independente do domínio da stack
+type ServiceDomain struct {
+	Host         string   `yaml:"host"`                    // ex: shop.example.org, example.org, *.example.org
+	RedirectFrom []string `yaml:"redirect_from,omitempty"` // hosts redirecionados para host (ex: www.example.org)
+	CertResolver string   `yaml:"cert_resolver,omitempty"` // padrão: tls.resolver
+	CertFile     string   `yaml:"cert_file,omitempty"`     // certificado próprio em vez de ACME
+	KeyFile      string   `yaml:"key_file,omitempty"`
+}
+
+// Route é um router HTTP do serviço com seu próprio service no Traefik
+type Route struct {
+	Name        string            `yaml:"name,omitempty"`         // sufixo do router (padrão: posição na lista)
+	Hosts       []string          `yaml:"hosts,omitempty"`        // hosts completos, inclusive domínios próprios (padrão: subdomain.domain)
+	Paths       []string          `yaml:"paths,omitempty"`        // prefixos de caminho
+	StripPrefix bool              `yaml:"strip_prefix,omitempty"` // remove o prefixo antes de encaminhar
+	Methods     []string          `yaml:"methods,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+	Port        int               `yaml:"port,omitempty"` // porta do container (padrão: expose)
+	EntryPoints []string          `yaml:"entrypoints,omitempty"`
+	Middlewares []string          `yaml:"middlewares,omitempty"`
+	Priority    int               `yaml:"priority,omitempty"`
+}
+
+// ListenPort retorna a porta publicada pelo Traefik
+func (r StreamRoute) ListenPort() int {
+	if r.Listen > 0 {
+		return r.Listen
 	}
+	return r.Port
+}
 
-	switch v := s.TraefikRaw.(type) {
-	case bool:
-		// Formato antigo: traefik: true/false
-		if v {
-			return &ServiceTraefik{Enabled: true}
-		}
-		return &ServiceTraefik{Enabled: false}
-	case map[string]interface{}:
-		// Formato novo: estrutura complexa
-		traefik := &ServiceTraefik{}
-
-		if enabled, ok := v["enabled"].(bool); ok {
-			traefik.Enabled = enabled
-		}
-
-		if rule, ok := v["rule"].(string); ok {
-			traefik.Rule = rule
-		}
-
-		if priority, ok := v["priority"].(int); ok {
-			traefik.Priority = priority
-		}
-
-		if entrypoints, ok := v["entrypoints"].([]interface{}); ok {
-			for _, ep := range entrypoints {
-				if epStr, ok := ep.(string); ok {
-					traefik.EntryPoints = append(traefik.EntryPoints, epStr)
-				}
-			}
-		}
-
-		if middlewares, ok := v["middlewares"].([]interface{}); ok {
-			for _, mw := range middlewares {
-				if mwStr, ok := mw.(string); ok {
-					traefik.Middlewares = append(traefik.Middlewares, mwStr)
-				}
-			}
-		}
-
-		if labels, ok := v["labels"].(map[string]interface{}); ok {
-			traefik.Labels = make(map[string]string)
-			for k, label := range labels {
-				if labelStr, ok := label.(string); ok {
-					traefik.Labels[k] = labelStr
-				}
-			}
-		}
-
-		return traefik
-	case *ServiceTraefik:
-		// Formato já parseado
-		return v
-	default:
-		return nil
-	}
+// GetTraefik retorna a configuração do Traefik do serviço (nil quando ausente)
+func (s *Service) GetTraefik() *ServiceTraefik {
+	return s.Traefik
 }
 
 // ServiceTraefik define configurações específicas do Traefik para um serviço
 type ServiceTraefik struct {
 	Enabled      bool              `yaml:"enabled,omitempty"`
 	Rule         string            `yaml:"rule,omitempty"`         // Regra customizada (sobrescreve padrão Host())
+	Match        *RuleMatch        `yaml:"match,omitempty"`        // Regra estruturada, compilada para rule
 	EntryPoints  []string          `yaml:"entrypoints,omitempty"`  // Entry points customizados
 	Middlewares  []string          `yaml:"middlewares,omitempty"`  // Lista de middlewares a aplicar
 	Priority     int               `yaml:"priority,omitempty"`     // Prioridade da rota
@@ -534,7 +610,7 @@ type ServiceTLS struct {
 type ServiceLB struct {
 	Sticky             *StickyConfig       `yaml:"sticky,omitempty"`
 	HealthCheck        *LBHealthCheck      `yaml:"healthCheck,omitempty"`
-	PassHostHeader     bool                `yaml:"passHostHeader,omitempty"`
+	PassHostHeader     *bool               `yaml:"passHostHeader,omitempty"`
 	ResponseForwarding *ResponseForwarding `yaml:"responseForwarding,omitempty"`
 	ServersTransport   string              `yaml:"serversTransport,omitempty"`
 }
@@ -611,16 +687,76 @@ type BasicAuth struct {
 
 // HealthCheck representa as configurações de health check
 type HealthCheck struct {
-	Enabled  bool   `yaml:"enabled"`
-	Path     string `yaml:"path,omitempty"`
-	Interval string `yaml:"interval,omitempty"`
-	Timeout  string `yaml:"timeout,omitempty"`
-	Retries  int    `yaml:"retries,omitempty"`
+	Enabled     bool              `yaml:"enabled"`
+	Type        string            `yaml:"type,omitempty"` // http (padrão) | tcp | exec | grpc | none
+	Tool        string            `yaml:"tool,omitempty"` // http: curl (padrão) | wget | probe; tcp: nc (padrão) | probe
+	Path        string            `yaml:"path,omitempty"`
+	Port        int               `yaml:"port,omitempty"`    // padrão: expose
+	Method      string            `yaml:"method,omitempty"`  // http
+	Status      int               `yaml:"status,omitempty"`  // http: status esperado (padrão: qualquer 2xx/3xx)
+	Headers     map[string]string `yaml:"headers,omitempty"` // http
+	Command     []string          `yaml:"command,omitempty"` // exec: executado sem shell
+	Service     string            `yaml:"service,omitempty"` // grpc: serviço consultado (padrão: o servidor)
+	Interval    string            `yaml:"interval,omitempty"`
+	Timeout     string            `yaml:"timeout,omitempty"`
+	Retries     int               `yaml:"retries,omitempty"`
+	StartPeriod string            `yaml:"start_period,omitempty"` // padrão: 60s
+}
+
+// ProbeType retorna o tipo do health check, http quando omitido
+func (h *HealthCheck) ProbeType() string {
+	if h.Type == "" {
+		return "http"
+	}
+	return h.Type
+}
+
+// ProbeTool retorna a ferramenta que executa o health check dentro do container
+func (h *HealthCheck) ProbeTool() string {
+	switch {
+	case h.Tool != "":
+		return h.Tool
+	case h.ProbeType() == "http":
+		return "curl"
+	case h.ProbeType() == "tcp":
+		return "nc"
+	case h.ProbeType() == "grpc":
+		return "probe"
+	}
+	return ""
+}
+
+// UsesProbe indica se o binário do harborctl precisa ser montado no container
+func (h *HealthCheck) UsesProbe() bool {
+	return h.Enabled && h.ProbeTool() == "probe"
 }
 
 // DeployConfig representa as configurações de deployment
 type DeployConfig struct {
-	Strategy string `yaml:"strategy,omitempty"` // "rolling" ou "recreate"
+	Strategy      string       `yaml:"strategy,omitempty"`       // "rolling", "recreate", "blue-green" ou "canary"
+	HealthTimeout string       `yaml:"health_timeout,omitempty"` // espera máxima pela nova versão saudável (padrão: 5m)
+	Drain         string       `yaml:"drain,omitempty"`          // espera após trocar o tráfego antes de parar a versão antiga (padrão: 10s)
+	Steps         []CanaryStep `yaml:"steps,omitempty"`          // passos do canary (padrão: 10% e 50% por 1m)
+	Parallelism   int          `yaml:"parallelism,omitempty"`    // containers trocados por vez no rolling (padrão: 1)
+	Delay         string       `yaml:"delay,omitempty"`          // espera entre lotes do rolling (padrão: 10s)
+}
+
+// CanaryStep é uma fatia do tráfego enviada à nova versão durante um tempo de observação
+type CanaryStep struct {
+	Weight   int    `yaml:"weight"`   // porcentagem do tráfego na nova versão (1-99)
+	Duration string `yaml:"duration"` // tempo de observação antes do próximo passo (ex: 2m)
+}
+
+// RollingUpdate indica se o harborctl troca os containers do serviço em lotes, um lote saudável
+// antes de parar o próximo lote antigo; vale para serviços com réplicas e estratégia rolling (padrão)
+func (s *Service) RollingUpdate() bool {
+	return s.Replicas > 1 && (s.Deploy == nil || s.Deploy.Strategy == "" || s.Deploy.Strategy == "rolling")
+}
+
+// Progressive indica se o serviço é implantado em duas cores (blue-green ou canary),
+// com o tráfego trocado pelo Traefik em vez de recriar o container
+func (s *Service) Progressive() bool {
+	return s.Deploy != nil && (s.Deploy.Strategy == "blue-green" || s.Deploy.Strategy == "canary")
 }
 
 // NetworkAccess define o acesso às redes
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Condições de depends_on, com o mesmo significado do docker compose
+const (
+	ConditionStarted               = "started"
+	ConditionHealthy               = "healthy"
+	ConditionCompletedSuccessfully = "completed_successfully"
+)
+
+// Dependency é um serviço do stack que precisa atingir uma condição antes do serviço subir
+type Dependency struct {
+	Service   string `yaml:"service"`
+	Condition string `yaml:"condition,omitempty"` // started (padrão) | healthy | completed_successfully
+}
+
+// dependencyFields evita recursão ao decodificar/serializar Dependency
+type dependencyFields Dependency
+
+// UnmarshalYAML aceita o formato curto (- db) e o bloco com condição
+func (d *Dependency) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*d = Dependency{Service: value.Value}
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: depends_on entries must be a service name or a mapping", value.Line)
+	}
+
+	var fields dependencyFields
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*d = Dependency(fields)
+	return nil
+}
+
+// MarshalYAML mantém o formato curto quando a condição é a padrão
+func (d Dependency) MarshalYAML() (interface{}, error) {
+	if d.Condition == "" {
+		return d.Service, nil
+	}
+	return dependencyFields(d), nil
+}
+
+// DependencyCondition retorna a condição da dependência, started quando omitida
+func (d Dependency) DependencyCondition() string {
+	if d.Condition == "" {
+		return ConditionStarted
+	}
+	return d.Condition
+}
+
+// ComposeCondition retorna a condição no formato do docker compose (service_<condição>)
+func (d Dependency) ComposeCondition() string {
+	return "service_" + d.DependencyCondition()
+}
+
+// OneShotServices retorna os serviços esperados com completed_successfully: tarefas que
+// rodam até o fim (ex: migrações), sem porta e sem reinício ao terminar com sucesso
+func (s *Stack) OneShotServices() map[string]bool {
+	oneShot := make(map[string]bool)
+	for _, sv := range s.Services {
+		for _, dep := range sv.DependsOn {
+			if dep.DependencyCondition() == ConditionCompletedSuccessfully {
+				oneShot[dep.Service] = true
+			}
+		}
+	}
+	return oneShot
+}
+
+// DependencyOrder ordena os serviços para que cada um venha depois das suas dependências,
+// mantendo a ordem de declaração entre serviços independentes. Falha se houver ciclo
+func (s *Stack) DependencyOrder() ([]string, error) {
+	known := make(map[string]*Service, len(s.Services))
+	for i := range s.Services {
+		known[s.Services[i].Name] = &s.Services[i]
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	marks := make(map[string]int, len(s.Services))
+	order := make([]string, 0, len(s.Services))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch marks[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(cycleStart(path, name), name), " -> "))
+		}
+		marks[name] = visiting
+		for _, dep := range known[name].DependsOn {
+			if _, ok := known[dep.Service]; !ok {
+				continue
+			}
+			if err := visit(dep.Service, append(path, name)); err != nil {
+				return err
+			}
+		}
+		marks[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, sv := range s.Services {
+		if err := visit(sv.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// cycleStart corta o caminho no ponto em que o ciclo começa
+func cycleStart(path []string, name string) []string {
+	for i, step := range path {
+		if step == name {
+			return path[i:]
+		}
+	}
+	return path
+}
+
+// checkDependencies valida serviços e condições de depends_on e a ausência de ciclos
+func checkDependencies(stack *Stack, report reportFunc) {
+	known := make(map[string]*Service, len(stack.Services))
+	for i := range stack.Services {
+		known[stack.Services[i].Name] = &stack.Services[i]
+	}
+
+	for i, sv := range stack.Services {
+		seen := make(map[string]bool, len(sv.DependsOn))
+		for j, dep := range sv.DependsOn {
+			path := servicePath(i, fmt.Sprintf("depends_on[%d]", j))
+
+			switch dep.Condition {
+			case "", ConditionStarted, ConditionHealthy, ConditionCompletedSuccessfully:
+			default:
+				report(path+".condition", fmt.Sprintf("%s: unknown depends_on condition %q", sv.Name, dep.Condition), "use started, healthy or completed_successfully")
+			}
+
+			target, ok := known[dep.Service]
+			switch {
+			case dep.Service == "":
+				report(path, fmt.Sprintf("%s: depends_on entry without service", sv.Name), "set the name of a service in this stack")
+				continue
+			case dep.Service == sv.Name:
+				report(path, fmt.Sprintf("%s: service depends on itself", sv.Name), "remove the entry")
+				continue
+			case !ok:
+				report(path, fmt.Sprintf("%s: depends on unknown service %s", sv.Name, dep.Service), "use the name of a service in this stack")
+				continue
+			case seen[dep.Service]:
+				report(path, fmt.Sprintf("%s: duplicate dependency on %s", sv.Name, dep.Service), "keep a single entry per service")
+			}
+			seen[dep.Service] = true
+
+			// As cores do blue-green e canary se alternam, o compose não tem um nome fixo para esperar
+			if target.Progressive() {
+				report(path, fmt.Sprintf("%s: cannot depend on %s, it uses %s", sv.Name, dep.Service, target.Deploy.Strategy), "remove the dependency, or deploy "+dep.Service+" with the rolling strategy")
+			}
+			if dep.Condition == ConditionHealthy && target.HealthCheck != nil && target.HealthCheck.ProbeType() == "none" {
+				report(path+".condition", fmt.Sprintf("%s: %s has its health check disabled and never becomes healthy", sv.Name, dep.Service), "enable its health_check or use condition started")
+			}
+		}
+	}
+
+	if _, err := stack.DependencyOrder(); err != nil {
+		report("services", err.Error(), "remove one of the dependencies in the cycle")
+	}
+}
+
+// checkHealthyDependencies avisa quando uma dependência healthy conta só com o HEALTHCHECK da imagem
+func checkHealthyDependencies(stack *Stack, report reportFunc) {
+	known := make(map[string]*Service, len(stack.Services))
+	for i := range stack.Services {
+		known[stack.Services[i].Name] = &stack.Services[i]
+	}
+
+	for i, sv := range stack.Services {
+		for j, dep := range sv.DependsOn {
+			target, ok := known[dep.Service]
+			if !ok || dep.Condition != ConditionHealthy {
+				continue
+			}
+			if target.HealthCheck == nil || (!target.HealthCheck.Enabled && target.HealthCheck.ProbeType() != "none") {
+				report(servicePath(i, fmt.Sprintf("depends_on[%d]", j)), fmt.Sprintf("%s: waits for %s to be healthy, but %s has no health_check", sv.Name, dep.Service, dep.Service), "enable health_check on "+dep.Service+", unless its image defines a HEALTHCHECK")
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyOrder(t *testing.T) {
+	service := func(name string, deps ...string) Service {
+		sv := Service{Name: name}
+		for _, dep := range deps {
+			sv.DependsOn = append(sv.DependsOn, Dependency{Service: dep})
+		}
+		return sv
+	}
+
+	tests := []struct {
+		name     string
+		services []Service
+		want     []string
+		cycle    string
+	}{
+		{
+			name:     "declaration order without dependencies",
+			services: []Service{service("b"), service("a"), service("c")},
+			want:     []string{"b", "a", "c"},
+		},
+		{
+			name:     "dependencies first",
+			services: []Service{service("web", "api"), service("api", "db", "cache"), service("cache"), service("db")},
+			want:     []string{"db", "cache", "api", "web"},
+		},
+		{
+			name:     "unknown services are ignored",
+			services: []Service{service("api", "external"), service("db")},
+			want:     []string{"api", "db"},
+		},
+		{
+			name:     "two services",
+			services: []Service{service("a", "b"), service("b", "a")},
+			cycle:    "dependency cycle: a -> b -> a",
+		},
+		{
+			name:     "cycle past an acyclic prefix",
+			services: []Service{service("web", "api"), service("api", "db"), service("db", "api")},
+			cycle:    "dependency cycle: api -> db -> api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := &Stack{Services: tt.services}
+			order, err := stack.DependencyOrder()
+			if tt.cycle != "" {
+				if err == nil || err.Error() != tt.cycle {
+					t.Fatalf("DependencyOrder() error = %v, want %q", err, tt.cycle)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(order, tt.want) {
+				t.Fatalf("DependencyOrder() = %v, %v, want %v", order, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsDependencyCycle(t *testing.T) {
+	data := strings.Replace(rulesFixture, "    resources: {memory: 256m, cpus: \"0.5\"}\n",
+		"    resources: {memory: 256m, cpus: \"0.5\"}\n    depends_on: [db]\n  - name: db\n    image: postgres:16\n    expose: 5432\n    depends_on: [api]\n", 1)
+
+	report := checkFixture(t, data)
+	for _, f := range report.Findings {
+		if f.Path == "services" && strings.Contains(f.Message, "dependency cycle: api -> db -> api") {
+			return
+		}
+	}
+	t.Fatalf("no dependency cycle finding in %+v", report.Findings)
+}
@@ -0,0 +1,64 @@
+package config
+
+import "fmt"
+
+var deployStrategies = map[string]bool{"rolling": true, "recreate": true, "blue-green": true, "canary": true}
+
+// checkDeploy valida as estratégias de deploy, os lotes do rolling e os passos do canary
+func checkDeploy(stack *Stack, report reportFunc) {
+	for i, sv := range stack.Services {
+		deploy := sv.Deploy
+		if deploy == nil {
+			continue
+		}
+		path := servicePath(i, "deploy")
+
+		if deploy.Strategy != "" && !deployStrategies[deploy.Strategy] {
+			report(path+".strategy", fmt.Sprintf("%s: unknown deploy strategy %q", sv.Name, deploy.Strategy), "use rolling, recreate, blue-green or canary")
+			continue
+		}
+		if deploy.HealthTimeout != "" && !validDuration(deploy.HealthTimeout) {
+			report(path+".health_timeout", fmt.Sprintf("%s: invalid health_timeout %q", sv.Name, deploy.HealthTimeout), "use a duration like 5m")
+		}
+		if deploy.Drain != "" && !validDuration(deploy.Drain) {
+			report(path+".drain", fmt.Sprintf("%s: invalid drain %q", sv.Name, deploy.Drain), "use a duration like 10s")
+		}
+		if deploy.Parallelism < 0 {
+			report(path+".parallelism", fmt.Sprintf("%s: invalid parallelism %d", sv.Name, deploy.Parallelism), "use the number of containers replaced at a time")
+		}
+		if deploy.Delay != "" && !validDuration(deploy.Delay) {
+			report(path+".delay", fmt.Sprintf("%s: invalid delay %q", sv.Name, deploy.Delay), "use a duration like 10s")
+		}
+		if (deploy.Parallelism > 0 || deploy.Delay != "") && deploy.Strategy != "" && deploy.Strategy != "rolling" {
+			report(path, fmt.Sprintf("%s: parallelism and delay are only used by rolling updates", sv.Name), "set 'strategy: rolling' or remove them")
+		}
+		if len(deploy.Steps) > 0 && deploy.Strategy != "canary" {
+			report(path+".steps", fmt.Sprintf("%s: steps are only used by the canary strategy", sv.Name), "set 'strategy: canary' or remove steps")
+		}
+
+		last := 0
+		for j, step := range deploy.Steps {
+			stepPath := fmt.Sprintf("%s.steps[%d]", path, j)
+			if step.Weight < 1 || step.Weight > 99 {
+				report(stepPath+".weight", fmt.Sprintf("%s: canary weight %d out of range", sv.Name, step.Weight), "use a percentage between 1 and 99, the last step always sends 100%")
+			} else if step.Weight <= last {
+				report(stepPath+".weight", fmt.Sprintf("%s: canary weight %d does not increase", sv.Name, step.Weight), "order steps by increasing weight")
+			}
+			last = step.Weight
+			if !validDuration(step.Duration) {
+				report(stepPath+".duration", fmt.Sprintf("%s: invalid canary duration %q", sv.Name, step.Duration), "use a duration like 2m")
+			}
+		}
+
+		if !sv.Progressive() {
+			continue
+		}
+		traefik := sv.GetTraefik()
+		if traefik == nil || !traefik.Enabled {
+			report(path+".strategy", fmt.Sprintf("%s: %s needs traefik", sv.Name, deploy.Strategy), "set 'traefik: true', traffic is shifted by Traefik weighted services")
+		}
+		if len(sv.TCP) > 0 || len(sv.UDP) > 0 {
+			report(path+".strategy", fmt.Sprintf("%s: %s only shifts http traffic", sv.Name, deploy.Strategy), "use rolling or recreate for services with tcp/udp routes")
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leandrodaf/harborctl/pkg/textdiff"
+)
+
+// Document é um arquivo de configuração editável que preserva comentários,
+// ordem das chaves e formatação. Caminhos seguem o formato dos erros de validação
+// (ex: tls.email, services[0].expose) e aceitam seleção por nome (services[name=api]).
+type Document struct {
+	root     *yaml.Node
+	original []byte
+	changed  bool // algum Set ou Delete alterou o documento
+}
+
+// ParseDocument carrega um documento YAML para edição
+func ParseDocument(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if documentRoot(&root).Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top-level value must be a mapping")
+	}
+	return &Document{root: &root, original: data}, nil
+}
+
+// Get retorna o nó no caminho, ou nil se não existir
+func (d *Document) Get(path string) *yaml.Node {
+	steps, err := parseDocumentPath(path)
+	if err != nil {
+		return nil
+	}
+	node := documentRoot(d.root)
+	for _, step := range steps {
+		if node = step.child(node); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Set altera o valor no caminho, criando mapas e itens nomeados intermediários
+func (d *Document) Set(path string, value interface{}) error {
+	node, ok := value.(*yaml.Node)
+	if !ok {
+		node = scalarNode(value)
+	}
+	return d.SetNode(path, node)
+}
+
+// SetNode altera o valor no caminho mantendo os comentários do valor anterior
+func (d *Document) SetNode(path string, value *yaml.Node) error {
+	steps, err := parseDocumentPath(path)
+	if err != nil {
+		return err
+	}
+
+	parent := documentRoot(d.root)
+	for i, step := range steps[:len(steps)-1] {
+		child := step.child(parent)
+		if child == nil {
+			if child, err = step.create(parent, steps[i+1]); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		parent = child
+	}
+
+	if err := steps[len(steps)-1].set(parent, value); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	d.changed = true
+	return nil
+}
+
+// Delete remove o valor no caminho; retorna false se ele não existir
+func (d *Document) Delete(path string) bool {
+	steps, err := parseDocumentPath(path)
+	if err != nil {
+		return false
+	}
+
+	parent := documentRoot(d.root)
+	for _, step := range steps[:len(steps)-1] {
+		if parent = step.child(parent); parent == nil {
+			return false
+		}
+	}
+	if !steps[len(steps)-1].remove(parent) {
+		return false
+	}
+	d.changed = true
+	return true
+}
+
+// Bytes serializa o documento com a indentação, linhas em branco e espaçamento de comentários do original.
+// Sem alterações, o original é devolvido byte a byte
+func (d *Document) Bytes() ([]byte, error) {
+	if !d.changed {
+		return d.original, nil
+	}
+	data, err := encodeDocument(d.root, d.original)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(textdiff.RestoreFormatting(string(d.original), string(data))), nil
+}
+
+// Update aplica no documento apenas os campos de stack que diferem de before,
+// o estado resolvido de onde stack foi carregada. Os dois lados passam pelos defaults
+// da validação, para que valores padrão não sejam escritos nem apagados do arquivo.
+// origins indica os nós de before vindos de includes: alterá-los é recusado, já que o
+// documento só pode reescrever o próprio arquivo
+func (d *Document) Update(before *yaml.Node, origins map[*yaml.Node]string, stack *Stack) error {
+	original, err := withDefaults(documentRoot(before))
+	if err != nil {
+		return err
+	}
+	var encoded yaml.Node
+	if err := encoded.Encode(stack); err != nil {
+		return err
+	}
+	after, err := withDefaults(&encoded)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	included := func(path string) bool {
+		if file := includedOrigin(before, origins, path); file != "" {
+			errs = append(errs, fmt.Errorf("%s is defined in included file %s; edit it there", path, file))
+			return true
+		}
+		return false
+	}
+	diffNodes(original, after, "",
+		func(path string, value *yaml.Node) {
+			if included(path) {
+				return
+			}
+			if err := d.SetNode(path, value); err != nil {
+				errs = append(errs, err)
+			}
+		},
+		func(path string) {
+			if !included(path) {
+				d.Delete(path)
+			}
+		},
+	)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// withDefaults decodifica node como Stack, aplica os defaults e o codifica de novo
+func withDefaults(node *yaml.Node) (*yaml.Node, error) {
+	var stack Stack
+	if err := node.Decode(&stack); err != nil {
+		return nil, err
+	}
+	stack.applyDefaults()
+
+	var out yaml.Node
+	if err := out.Encode(&stack); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// includedOrigin retorna o arquivo incluído que define path em resolved, olhando o nó mais
+// profundo existente no caminho; "" quando ele pertence ao próprio arquivo
+func includedOrigin(resolved *yaml.Node, origins map[*yaml.Node]string, path string) string {
+	steps, err := parseDocumentPath(path)
+	if err != nil {
+		return ""
+	}
+	node := documentRoot(resolved)
+	for _, step := range steps {
+		child := step.child(node)
+		if child == nil {
+			break
+		}
+		node = child
+	}
+	return origins[node]
+}
+
+// diffNodes percorre before e after chamando set/del para cada caminho alterado
+func diffNodes(before, after *yaml.Node, path string, set func(string, *yaml.Node), del func(string)) {
+	switch {
+	case before.Kind == yaml.MappingNode && after.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(after.Content); i += 2 {
+			key, value := after.Content[i].Value, after.Content[i+1]
+			childPath := joinPath(path, key)
+			if old := mappingValue(before, key); old != nil {
+				diffNodes(old, value, childPath, set, del)
+			} else if !zeroNode(value) {
+				set(childPath, withoutZero(value))
+			}
+		}
+		for i := 0; i+1 < len(before.Content); i += 2 {
+			key, value := before.Content[i].Value, before.Content[i+1]
+			if mappingValue(after, key) == nil && !zeroNode(value) {
+				del(joinPath(path, key))
+			}
+		}
+	case before.Kind == yaml.SequenceNode && after.Kind == yaml.SequenceNode &&
+		len(before.Content) > 0 && namedItems(before) && namedItems(after):
+		for _, item := range after.Content {
+			name := mappingValue(item, "name").Value
+			itemPath := fmt.Sprintf("%s[name=%s]", path, name)
+			if old := namedItem(before, name); old != nil {
+				diffNodes(old, item, itemPath, set, del)
+			} else {
+				set(itemPath, withoutZero(item))
+			}
+		}
+		for _, item := range before.Content {
+			name := mappingValue(item, "name").Value
+			if namedItem(after, name) == nil {
+				del(fmt.Sprintf("%s[name=%s]", path, name))
+			}
+		}
+	default:
+		if zeroNode(before) && zeroNode(after) {
+			return
+		}
+		if !equalNodes(before, after) {
+			set(path, after)
+		}
+	}
+}
+
+// equalNodes compara dois nós pelo valor, ignorando estilo e comentários
+func equalNodes(a, b *yaml.Node) bool {
+	if a.Kind == yaml.AliasNode {
+		a = a.Alias
+	}
+	if b.Kind == yaml.AliasNode {
+		b = b.Alias
+	}
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(a.Content); i += 2 {
+			other := mappingValue(b, a.Content[i].Value)
+			if other == nil || !equalNodes(a.Content[i+1], other) {
+				return false
+			}
+		}
+		return true
+	default:
+		for i := range a.Content {
+			if !equalNodes(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// zeroNode indica valores que a serialização omitiria (vazio, false, 0, null)
+func zeroNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch node.Value {
+		case "", "false", "0", "null", "~":
+			return true
+		}
+		return false
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
+
+// withoutZero remove dos mapas os campos com valor zero, que a serialização da struct
+// emite quando a tag não tem omitempty (ex: expose: 0) e o arquivo nunca declarou
+func withoutZero(node *yaml.Node) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		pruned := *node
+		pruned.Content = nil
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if zeroNode(node.Content[i+1]) {
+				continue
+			}
+			pruned.Content = append(pruned.Content, node.Content[i], withoutZero(node.Content[i+1]))
+		}
+		return &pruned
+	case yaml.SequenceNode:
+		pruned := *node
+		pruned.Content = make([]*yaml.Node, len(node.Content))
+		for i, item := range node.Content {
+			pruned.Content[i] = withoutZero(item)
+		}
+		return &pruned
+	}
+	return node
+}
+
+func namedItem(seq *yaml.Node, name string) *yaml.Node {
+	for _, item := range seq.Content {
+		if n := mappingValue(item, "name"); n != nil && n.Value == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// pathStep é um segmento de caminho: chave, índice ou item nomeado
+type pathStep struct {
+	key   string
+	index int
+	name  string
+	kind  int
+}
+
+const (
+	stepKey = iota
+	stepIndex
+	stepName
+)
+
+// parseDocumentPath converte "services[name=api].env.DEBUG" em passos
+func parseDocumentPath(path string) ([]pathStep, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var steps []pathStep
+	for _, segment := range strings.Split(path, ".") {
+		key := segment
+		var selectors []string
+		if idx := strings.IndexByte(segment, '['); idx >= 0 {
+			key = segment[:idx]
+			rest := segment[idx:]
+			for rest != "" {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("invalid path %q", path)
+				}
+				selectors = append(selectors, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+
+		if key != "" {
+			steps = append(steps, pathStep{kind: stepKey, key: key})
+		}
+		for _, sel := range selectors {
+			if name, ok := strings.CutPrefix(sel, "name="); ok {
+				steps = append(steps, pathStep{kind: stepName, name: name})
+				continue
+			}
+			index, err := strconv.Atoi(sel)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", sel, path)
+			}
+			steps = append(steps, pathStep{kind: stepIndex, index: index})
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+	return steps, nil
+}
+
+func (s pathStep) child(node *yaml.Node) *yaml.Node {
+	switch s.kind {
+	case stepKey:
+		return mappingValue(node, s.key)
+	case stepIndex:
+		if node.Kind == yaml.SequenceNode && s.index < len(node.Content) {
+			return node.Content[s.index]
+		}
+	case stepName:
+		if node.Kind == yaml.SequenceNode {
+			return namedItem(node, s.name)
+		}
+	}
+	return nil
+}
+
+// create adiciona o filho ausente com o tipo esperado pelo próximo passo
+func (s pathStep) create(node *yaml.Node, next pathStep) (*yaml.Node, error) {
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if next.kind != stepKey {
+		child = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	switch s.kind {
+	case stepKey:
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a mapping", s.key)
+		}
+		setMappingValue(node, s.key, child)
+		return mappingValue(node, s.key), nil
+	case stepName:
+		if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("cannot select %q: not a list", s.name)
+		}
+		item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(item, "name", scalarNode(s.name))
+		node.Content = append(node.Content, item)
+		return item, nil
+	}
+	return nil, fmt.Errorf("index %d out of range", s.index)
+}
+
+func (s pathStep) set(node *yaml.Node, value *yaml.Node) error {
+	switch s.kind {
+	case stepKey:
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", s.key)
+		}
+		setMappingValue(node, s.key, value)
+		return nil
+	case stepIndex:
+		if node.Kind != yaml.SequenceNode || s.index > len(node.Content) {
+			return fmt.Errorf("index %d out of range", s.index)
+		}
+		if s.index == len(node.Content) {
+			node.Content = append(node.Content, value)
+			return nil
+		}
+		keepComments(node.Content[s.index], value)
+		node.Content[s.index] = value
+		return nil
+	case stepName:
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("cannot select %q: not a list", s.name)
+		}
+		for i, item := range node.Content {
+			if n := mappingValue(item, "name"); n != nil && n.Value == s.name {
+				keepComments(item, value)
+				node.Content[i] = value
+				return nil
+			}
+		}
+		node.Content = append(node.Content, value)
+		return nil
+	}
+	return nil
+}
+
+func (s pathStep) remove(node *yaml.Node) bool {
+	switch s.kind {
+	case stepKey:
+		return deleteMappingKey(node, s.key)
+	case stepIndex:
+		if node.Kind == yaml.SequenceNode && s.index < len(node.Content) {
+			node.Content = append(node.Content[:s.index], node.Content[s.index+1:]...)
+			return true
+		}
+	case stepName:
+		if node.Kind != yaml.SequenceNode {
+			return false
+		}
+		for i, item := range node.Content {
+			if n := mappingValue(item, "name"); n != nil && n.Value == s.name {
+				node.Content = append(node.Content[:i], node.Content[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func keepComments(old, value *yaml.Node) {
+	value.HeadComment = old.HeadComment
+	value.LineComment = old.LineComment
+	value.FootComment = old.FootComment
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+const documentFixture = `# Stack de exemplo
+version: 1
+project: demo   # nome do projeto
+domain: example.com
+
+tls:
+  mode: acme
+  email: ops@example.com
+
+# Serviços da aplicação
+services:
+  - name: api
+    image: api:1 # versão atual
+    replicas: 2
+    env:
+      TEAM: "core #1"
+    x-custom: keep   # campo desconhecido
+  - name: web
+    image: web:1
+
+networks:
+  public: {}
+  private: {internal: true}
+`
+
+func editDocument(t *testing.T, data string, edit func(doc *Document) error) string {
+	t.Helper()
+	doc, err := ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	if err := edit(doc); err != nil {
+		t.Fatalf("edit error = %v", err)
+	}
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	return string(out)
+}
+
+func TestDocumentUnchangedIsByteIdentical(t *testing.T) {
+	fixtures := []string{
+		documentFixture,
+		"project: demo\n",
+		"# só comentário\nproject: demo # fim\n\n\n# rodapé\n",
+		"project:    demo\nlist: [a, b]\nquoted: 'x # y'\n",
+	}
+	for _, fixture := range fixtures {
+		got := editDocument(t, fixture, func(*Document) error { return nil })
+		if got != fixture {
+			t.Fatalf("round trip changed the file:\n%s\nwant\n%s", got, fixture)
+		}
+	}
+}
+
+func TestDocumentEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(doc *Document) error
+		want string // documentFixture com as substituições abaixo
+		from []string
+		to   []string
+	}{
+		{
+			name: "set scalar keeps inline comment",
+			edit: func(doc *Document) error { return doc.Set("services[name=api].image", "api:2") },
+			from: []string{"image: api:1 # versão atual"},
+			to:   []string{"image: api:2 # versão atual"},
+		},
+		{
+			name: "set by name on another item",
+			edit: func(doc *Document) error { return doc.Set("services[name=web].replicas", 3) },
+			from: []string{"    image: web:1\n"},
+			to:   []string{"    image: web:1\n    replicas: 3\n"},
+		},
+		{
+			name: "set new top-level key goes to the end",
+			edit: func(doc *Document) error { return doc.Set("environment", "production") },
+			from: []string{"  private: {internal: true}\n"},
+			to:   []string{"  private: {internal: true}\nenvironment: production\n"},
+		},
+		{
+			name: "set creates a named item",
+			edit: func(doc *Document) error { return doc.Set("services[name=worker].image", "worker:1") },
+			from: []string{"    image: web:1\n"},
+			to:   []string{"    image: web:1\n  - name: worker\n    image: worker:1\n"},
+		},
+		{
+			name: "delete named item",
+			edit: func(doc *Document) error {
+				if !doc.Delete("services[name=web]") {
+					t.Fatal("Delete() = false")
+				}
+				return nil
+			},
+			from: []string{"  - name: web\n    image: web:1\n"},
+			to:   []string{""},
+		},
+		{
+			name: "delete key keeps neighbours and their comments",
+			edit: func(doc *Document) error {
+				if !doc.Delete("services[name=api].replicas") {
+					t.Fatal("Delete() = false")
+				}
+				return nil
+			},
+			from: []string{"    replicas: 2\n"},
+			to:   []string{""},
+		},
+		{
+			name: "quoted value with hash",
+			edit: func(doc *Document) error { return doc.Set("services[name=api].env.TEAM", "core #2") },
+			from: []string{`TEAM: "core #1"`},
+			to:   []string{`TEAM: "core #2"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := documentFixture
+			for i := range tt.from {
+				if !strings.Contains(want, tt.from[i]) {
+					t.Fatalf("fixture has no %q", tt.from[i])
+				}
+				want = strings.Replace(want, tt.from[i], tt.to[i], 1)
+			}
+			if got := editDocument(t, documentFixture, tt.edit); got != want {
+				t.Fatalf("edited document:\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDocumentDeleteMissing(t *testing.T) {
+	doc, err := ParseDocument([]byte(documentFixture))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	for _, path := range []string{"nope", "services[name=nope]", "services[name=api].nope", "services[9]"} {
+		if doc.Delete(path) {
+			t.Errorf("Delete(%q) = true, want false", path)
+		}
+	}
+}
+
+func TestDocumentUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(stack *Stack)
+		from   string
+		to     string
+	}{
+		{
+			name:   "changed field only",
+			change: func(stack *Stack) { stack.Services[0].Replicas = 4 },
+			from:   "    replicas: 2\n",
+			to:     "    replicas: 4\n",
+		},
+		{
+			name: "added service",
+			change: func(stack *Stack) {
+				stack.Services = append(stack.Services, Service{Name: "worker", Image: "worker:1"})
+			},
+			from: "    image: web:1\n",
+			to:   "    image: web:1\n  - name: worker\n    image: worker:1\n",
+		},
+		{
+			name:   "removed service",
+			change: func(stack *Stack) { stack.Services = stack.Services[:1] },
+			from:   "  - name: web\n    image: web:1\n",
+			to:     "",
+		},
+		{
+			name:   "no change",
+			change: func(stack *Stack) {},
+		},
+	}
+
+	// Update compara structs: o campo desconhecido sai da fixture
+	fixture := strings.Replace(documentFixture, "    x-custom: keep   # campo desconhecido\n", "", 1)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack, before := loadFixture(t, fixture)
+			tt.change(stack)
+
+			got := editDocument(t, fixture, func(doc *Document) error {
+				return doc.Update(before.root, nil, stack)
+			})
+			want := strings.Replace(fixture, tt.from, tt.to, 1)
+			if got != want {
+				t.Fatalf("updated document:\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// loadFixture decodifica data como o manager faz antes de um SaveBaseConfig
+func loadFixture(t *testing.T, data string) (*Stack, *Document) {
+	t.Helper()
+	doc, err := ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	var stack Stack
+	if err := documentRoot(doc.root).Decode(&stack); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	stack.applyDefaults()
+	return &stack, doc
+}
+
+func TestEditKeepsUnknownFields(t *testing.T) {
+	got := editDocument(t, documentFixture, func(doc *Document) error {
+		return doc.Set("services[name=api].replicas", 5)
+	})
+	if !strings.Contains(got, "    x-custom: keep   # campo desconhecido\n") {
+		t.Fatalf("unknown field lost:\n%s", got)
+	}
+}
+
+func TestSaveBaseConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	write("services.yml", "services:\n  - name: shared\n    image: shared:1\n")
+	path := write("server-base.yml", "include: services.yml\n"+strings.Replace(
+		strings.Replace(documentFixture, "    x-custom: keep   # campo desconhecido\n", "", 1),
+		"version: 1\n", "", 1))
+
+	filesystem := fs.NewFileSystem()
+	m := NewManager(fs.NewConfigLoader(filesystem), filesystem, NewValidator())
+	ctx := context.Background()
+
+	t.Run("field of the file", func(t *testing.T) {
+		stack, err := m.Load(ctx, path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		original, _ := os.ReadFile(path)
+		for i := range stack.Services {
+			if stack.Services[i].Name == "api" {
+				stack.Services[i].Replicas = 3
+			}
+		}
+		if err := m.SaveBaseConfig(ctx, path, stack); err != nil {
+			t.Fatalf("SaveBaseConfig() error = %v", err)
+		}
+		got, _ := os.ReadFile(path)
+		want := strings.Replace(string(original), "    replicas: 2\n", "    replicas: 3\n", 1)
+		if string(got) != want {
+			t.Fatalf("saved file:\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("field of an include", func(t *testing.T) {
+		stack, err := m.Load(ctx, path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		original, _ := os.ReadFile(path)
+		for i := range stack.Services {
+			if stack.Services[i].Name == "shared" {
+				stack.Services[i].Image = "shared:2"
+			}
+		}
+		err = m.SaveBaseConfig(ctx, path, stack)
+		if err == nil || !strings.Contains(err.Error(), "included file") {
+			t.Fatalf("SaveBaseConfig() error = %v, want an included file error", err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != string(original) {
+			t.Fatalf("file changed after a rejected save:\n%s", got)
+		}
+	})
+
+	t.Run("defaults are not written", func(t *testing.T) {
+		stack, err := m.Load(ctx, path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		original, _ := os.ReadFile(path)
+		if err := m.SaveBaseConfig(ctx, path, stack); err != nil {
+			t.Fatalf("SaveBaseConfig() error = %v", err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != string(original) {
+			t.Fatalf("unchanged stack rewrote the file:\n%s\nwant\n%s", got, original)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Tipos de challenge ACME
+const (
+	ChallengeHTTP = "http"
+	ChallengeTLS  = "tls"
+	ChallengeDNS  = "dns"
+)
+
+var challengeCommand = regexp.MustCompile(`^--certificatesresolvers\.([^.=]+)\.acme\.(http|tls|dns)challenge(=true)?$`)
+
+// Slug retorna o host em formato aceito em nomes de routers (ex: www-example-org)
+func (d ServiceDomain) Slug() string {
+	host := strings.Replace(d.Host, "*.", "wildcard.", 1)
+	return strings.ReplaceAll(strings.ToLower(host), ".", "-")
+}
+
+// Wildcard indica se o host é um curinga (*.example.org)
+func (d ServiceDomain) Wildcard() bool {
+	return strings.HasPrefix(d.Host, "*.")
+}
+
+// ResolverChallenges retorna o tipo de challenge de cada cert resolver do Traefik gerado
+func ResolverChallenges(stack *Stack) map[string]string {
+	challenges := make(map[string]string)
+	if stack.TLS.Mode == "acme" {
+		challenges[stack.TLS.Resolver] = ChallengeHTTP
+		if stack.TLS.DNS != nil && stack.TLS.DNS.Provider != "" {
+			challenges[stack.TLS.Resolver] = ChallengeDNS
+		}
+	}
+	if stack.Traefik != nil {
+		for _, cmd := range stack.Traefik.Commands {
+			if m := challengeCommand.FindStringSubmatch(cmd); m != nil {
+				challenges[m[1]] = m[2]
+			}
+		}
+	}
+	return challenges
+}
+
+// checkServiceDomains valida os domínios próprios de um serviço e a emissão dos certificados
+func checkServiceDomains(stack *Stack, i int, sv Service, report reportFunc) {
+	if len(sv.Domains) == 0 {
+		return
+	}
+	if traefik := sv.GetTraefik(); traefik == nil || !traefik.Enabled {
+		report(servicePath(i, "domains"), fmt.Sprintf("%s: domains require traefik", sv.Name), "set 'traefik: true'")
+	}
+
+	resolvers := knownResolvers(stack)
+	challenges := ResolverChallenges(stack)
+	slugs := make(map[string]string)
+
+	for j, d := range sv.Domains {
+		path := servicePath(i, fmt.Sprintf("domains[%d]", j))
+
+		if d.Host == "" {
+			report(path+".host", fmt.Sprintf("%s: domain needs 'host'", sv.Name), "set the hostname (ex: shop.example.org)")
+			continue
+		}
+		if !hostnameFormat.MatchString(d.Host) {
+			report(path+".host", fmt.Sprintf("%s: invalid domain %q", sv.Name, d.Host), "use a hostname without scheme or port (ex: example.org)")
+		}
+		if other, ok := slugs[d.Slug()]; ok {
+			report(path+".host", fmt.Sprintf("%s: domains %s and %s map to the same router name", sv.Name, other, d.Host), "list each host once")
+		}
+		slugs[d.Slug()] = d.Host
+
+		for k, from := range d.RedirectFrom {
+			if strings.HasPrefix(from, "*.") || !hostnameFormat.MatchString(from) || strings.EqualFold(from, d.Host) {
+				report(fmt.Sprintf("%s.redirect_from[%d]", path, k), fmt.Sprintf("%s: invalid redirect host %q", sv.Name, from), "use another exact hostname (ex: www.example.org)")
+			}
+		}
+
+		if (d.CertFile == "") != (d.KeyFile == "") {
+			report(path, fmt.Sprintf("%s: %s needs both cert_file and key_file", sv.Name, d.Host), "set both files or neither")
+		}
+		if d.CertFile != "" && d.CertResolver != "" {
+			report(path+".cert_resolver", fmt.Sprintf("%s: %s sets both cert_resolver and cert_file", sv.Name, d.Host), "use a cert resolver or provided files, not both")
+			continue
+		}
+		if d.CertFile != "" {
+			continue
+		}
+
+		resolver := d.CertResolver
+		if resolver == "" {
+			if stack.TLS.Mode != "acme" {
+				continue // certificado autoassinado ou sem TLS
+			}
+			resolver = stack.TLS.Resolver
+		} else if !resolvers[resolver] {
+			report(path+".cert_resolver", fmt.Sprintf("%s: unknown cert resolver %q", sv.Name, resolver), fmt.Sprintf("the generated Traefik defines %q (tls.resolver)", stack.TLS.Resolver))
+			continue
+		}
+
+		challenge, ok := challenges[resolver]
+		if d.Wildcard() && ok && challenge != ChallengeDNS {
+			report(path+".host", fmt.Sprintf("%s: wildcard %s needs the DNS challenge, resolver %s uses %s", sv.Name, d.Host, resolver, challenge),
+				"configure 'tls.dnsChallenge' or provide cert_file/key_file")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+var healthTools = map[string]map[string]bool{
+	"http": {"curl": true, "wget": true, "probe": true},
+	"tcp":  {"nc": true, "probe": true},
+	"grpc": {"probe": true},
+}
+
+// checkHealthChecks valida os tipos de health check e as opções de cada um
+func checkHealthChecks(stack *Stack, report reportFunc) {
+	for i, sv := range stack.Services {
+		hc := sv.HealthCheck
+		if hc == nil {
+			continue
+		}
+		path := servicePath(i, "health_check")
+		kind := hc.ProbeType()
+
+		switch kind {
+		case "http", "tcp", "grpc", "exec", "none":
+		default:
+			report(path+".type", fmt.Sprintf("%s: unknown health check type %q", sv.Name, hc.Type), "use http, tcp, exec, grpc or none")
+			continue
+		}
+
+		if hc.Tool != "" && !healthTools[kind][hc.Tool] {
+			report(path+".tool", fmt.Sprintf("%s: tool %q cannot run %s health checks", sv.Name, hc.Tool, kind), toolHint(kind))
+		}
+		if hc.Port < 0 || hc.Port > 65535 {
+			report(path+".port", fmt.Sprintf("%s: invalid health check port %d", sv.Name, hc.Port), "use 1-65535")
+		}
+		if (kind == "tcp" || kind == "grpc") && hc.Port == 0 && sv.Expose <= 0 {
+			report(path+".port", fmt.Sprintf("%s: %s health check needs a port", sv.Name, kind), "set 'expose' or 'health_check.port'")
+		}
+
+		// Opções que só valem para um tipo
+		if kind != "http" && (hc.Path != "" || hc.Method != "" || hc.Status != 0 || len(hc.Headers) > 0) {
+			report(path, fmt.Sprintf("%s: path, method, status and headers only apply to http health checks", sv.Name), "remove them or set 'type: http'")
+		}
+		if kind != "exec" && len(hc.Command) > 0 {
+			report(path+".command", fmt.Sprintf("%s: command only applies to exec health checks", sv.Name), "set 'type: exec' or remove command")
+		}
+		if kind == "exec" && len(hc.Command) == 0 {
+			report(path+".command", fmt.Sprintf("%s: exec health check needs 'command'", sv.Name), `use a list, ex: ["pg_isready", "-U", "app"]`)
+		}
+		if kind != "grpc" && hc.Service != "" {
+			report(path+".service", fmt.Sprintf("%s: service only applies to grpc health checks", sv.Name), "set 'type: grpc' or remove service")
+		}
+
+		if kind == "http" {
+			if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+				report(path+".path", fmt.Sprintf("%s: health check path %q must start with /", sv.Name, hc.Path), "ex: /health")
+			}
+			if hc.Method != "" && !healthCheckMethods[strings.ToUpper(hc.Method)] {
+				report(path+".method", fmt.Sprintf("%s: invalid health check method %q", sv.Name, hc.Method), "use GET, HEAD, POST, ...")
+			}
+			if hc.Status != 0 && (hc.Status < 100 || hc.Status > 599) {
+				report(path+".status", fmt.Sprintf("%s: invalid expected status %d", sv.Name, hc.Status), "use an HTTP status between 100 and 599")
+			}
+			if hc.ProbeTool() == "wget" && (hc.Method != "" || hc.Status != 0) {
+				report(path+".tool", fmt.Sprintf("%s: wget health checks only support GET and 2xx/3xx", sv.Name), "use 'tool: curl' or 'tool: probe' for method and status")
+			}
+		}
+
+		durations := []struct{ field, value string }{
+			{"interval", hc.Interval}, {"timeout", hc.Timeout}, {"start_period", hc.StartPeriod},
+		}
+		for _, d := range durations {
+			if d.value != "" && !validDuration(d.value) {
+				report(path+"."+d.field, fmt.Sprintf("%s: invalid health check %s %q", sv.Name, d.field, d.value), "use a duration like 30s")
+			}
+		}
+	}
+}
+
+func toolHint(kind string) string {
+	switch kind {
+	case "http":
+		return "use curl, wget or probe"
+	case "tcp":
+		return "use nc or probe"
+	case "grpc":
+		return "grpc health checks always use probe"
+	}
+	return "remove tool, exec and none run no tool"
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+// includeKey é a diretiva que importa fragmentos de outros arquivos
+const includeKey = "include"
+
+// documentLoader lê um arquivo de configuração resolvendo variáveis e includes
+type documentLoader struct {
+	ctx     context.Context
+	loader  fs.ConfigLoader
+	lookup  LookupEnvFunc
+	origins map[*yaml.Node]string // arquivo de origem de cada nó incluído
+	chain   []string              // includes em andamento, para detectar ciclos
+	keys    []string              // caminhos absolutos de chain
+}
+
+func newDocumentLoader(ctx context.Context, loader fs.ConfigLoader, lookup LookupEnvFunc) *documentLoader {
+	return &documentLoader{
+		ctx:     ctx,
+		loader:  loader,
+		lookup:  lookup,
+		origins: make(map[*yaml.Node]string),
+	}
+}
+
+// load retorna o documento de path com includes mesclados e variáveis expandidas
+func (d *documentLoader) load(path string) (*yaml.Node, error) {
+	data, err := d.loader.Load(d.ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, parseError(path, err)
+	}
+	if doc.Kind == 0 {
+		// Arquivo vazio
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	root := documentRoot(&doc)
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d:%d: top-level value must be a mapping", path, root.Line, root.Column)
+	}
+
+	ip := &interpolator{file: path, lookup: d.lookup}
+	ip.walk(&doc)
+	if len(ip.errs) > 0 {
+		return nil, ip.errs
+	}
+	if err := normalizeProfiles(path, root); err != nil {
+		return nil, err
+	}
+
+	if len(d.chain) > 0 {
+		d.markOrigin(root, path)
+	}
+
+	includes, err := d.includes(path, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(includes) == 0 {
+		return &doc, nil
+	}
+
+	d.chain = append(d.chain, path)
+	d.keys = append(d.keys, canonicalPath(path))
+	defer func() {
+		d.chain = d.chain[:len(d.chain)-1]
+		d.keys = d.keys[:len(d.keys)-1]
+	}()
+
+	var base *yaml.Node
+	for _, inc := range includes {
+		target := inc.Value
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		target = filepath.Clean(target)
+
+		for _, key := range d.keys {
+			if key == canonicalPath(target) {
+				cycle := strings.Join(append(append([]string{}, d.chain...), target), " -> ")
+				return nil, fmt.Errorf("%s:%d:%d: include cycle: %s", path, inc.Line, inc.Column, cycle)
+			}
+		}
+
+		included, err := d.load(target)
+		if err != nil {
+			return nil, err
+		}
+		base = mergeNodes(base, documentRoot(included), overrideOrder)
+	}
+
+	doc.Content[0] = mergeNodes(base, root, overrideOrder)
+	return &doc, nil
+}
+
+// includes lê e remove a diretiva include: (string ou lista de strings)
+func (d *documentLoader) includes(path string, root *yaml.Node) ([]*yaml.Node, error) {
+	node := mappingValue(root, includeKey)
+	if node == nil {
+		return nil, nil
+	}
+	deleteMappingKey(root, includeKey)
+
+	var entries []*yaml.Node
+	switch node.Kind {
+	case yaml.ScalarNode:
+		entries = []*yaml.Node{node}
+	case yaml.SequenceNode:
+		entries = node.Content
+	default:
+		return nil, fmt.Errorf("%s:%d:%d: include must be a file path or a list of file paths", path, node.Line, node.Column)
+	}
+
+	for _, e := range entries {
+		if e.Kind != yaml.ScalarNode || e.Value == "" {
+			return nil, fmt.Errorf("%s:%d:%d: include entries must be file paths", path, e.Line, e.Column)
+		}
+	}
+	return entries, nil
+}
+
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func (d *documentLoader) markOrigin(node *yaml.Node, path string) {
+	if node == nil {
+		return
+	}
+	if _, ok := d.origins[node]; ok {
+		return
+	}
+	d.origins[node] = path
+	for _, child := range node.Content {
+		d.markOrigin(child, path)
+	}
+}
+
+// mergeOrder define qual lado determina a ordem das chaves no resultado
+type mergeOrder int
+
+const (
+	// overrideOrder mantém a ordem do arquivo que inclui (include:)
+	overrideOrder mergeOrder = iota
+	// baseOrder mantém a ordem do arquivo base (overlays de ambiente)
+	baseOrder
+)
+
+// mergeNodes mescla override sobre base: mapas são mesclados recursivamente,
+// listas de itens com "name" são mescladas por nome e demais valores são substituídos.
+// Valores marcados com !replace substituem o da base sem mesclagem.
+func mergeNodes(base, override *yaml.Node, order mergeOrder) *yaml.Node {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	if override.Tag == replaceTag {
+		return override
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && override.Kind == yaml.MappingNode:
+		return mergeMappings(base, override, order)
+	case base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode:
+		if namedItems(base) && namedItems(override) {
+			return mergeNamedSequence(base, override, order)
+		}
+	}
+
+	return override
+}
+
+// mergeMappings mescla chave a chave; as chaves exclusivas do outro lado vão para o fim
+func mergeMappings(base, override *yaml.Node, order mergeOrder) *yaml.Node {
+	if order == overrideOrder {
+		for i := 0; i+1 < len(base.Content); i += 2 {
+			key, value := base.Content[i], base.Content[i+1]
+			found := false
+			for j := 0; j+1 < len(override.Content); j += 2 {
+				if override.Content[j].Value == key.Value {
+					override.Content[j+1] = mergeNodes(value, override.Content[j+1], order)
+					found = true
+					break
+				}
+			}
+			if !found {
+				override.Content = append(override.Content, key, value)
+			}
+		}
+		return override
+	}
+
+	var leading string
+	if len(override.Content) > 0 {
+		leading = override.Content[0].HeadComment
+	}
+
+	merged := make([]*yaml.Node, 0, len(base.Content)+len(override.Content))
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key, value := base.Content[i], base.Content[i+1]
+		for j := 0; j+1 < len(override.Content); j += 2 {
+			if override.Content[j].Value == key.Value {
+				// A chave de override carrega os comentários de quem sobrescreveu
+				key, value = override.Content[j], mergeNodes(value, override.Content[j+1], order)
+				break
+			}
+		}
+		seen[key.Value] = true
+		merged = append(merged, key, value)
+	}
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		if !seen[override.Content[i].Value] {
+			merged = append(merged, override.Content[i], override.Content[i+1])
+		}
+	}
+
+	// O comentário de abertura de override continua no topo do mapa
+	if leading != "" && len(merged) > 0 && merged[0] != override.Content[0] {
+		override.Content[0].HeadComment = ""
+		if merged[0].HeadComment == "" {
+			merged[0].HeadComment = leading
+		} else {
+			merged[0].HeadComment = leading + "\n\n" + merged[0].HeadComment
+		}
+	}
+
+	override.Content = merged
+	return override
+}
+
+// namedItems indica se todos os itens da lista são mapas com uma chave name
+func namedItems(seq *yaml.Node) bool {
+	for _, item := range seq.Content {
+		name := mappingValue(item, "name")
+		if name == nil || name.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeNamedSequence mantém a ordem da base, mescla itens de mesmo nome e acrescenta os novos
+func mergeNamedSequence(base, override *yaml.Node, order mergeOrder) *yaml.Node {
+	index := make(map[string]*yaml.Node, len(override.Content))
+	for _, item := range override.Content {
+		index[mappingValue(item, "name").Value] = item
+	}
+
+	merged := make([]*yaml.Node, 0, len(base.Content)+len(override.Content))
+	seen := make(map[string]bool)
+	for _, item := range base.Content {
+		name := mappingValue(item, "name").Value
+		if o, ok := index[name]; ok {
+			merged = append(merged, mergeNodes(item, o, order))
+			seen[name] = true
+			continue
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range override.Content {
+		if !seen[mappingValue(item, "name").Value] {
+			merged = append(merged, item)
+		}
+	}
+
+	override.Content = merged
+	return override
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LookupEnvFunc resolve uma variável de ambiente
+type LookupEnvFunc func(name string) (string, bool)
+
+// interpolator expande ${VAR}, ${VAR:-default} e ${VAR:?erro} nos valores escalares.
+// "$$" é um escape para "$" literal.
+type interpolator struct {
+	file   string
+	lookup LookupEnvFunc
+	errs   FieldErrors
+}
+
+func (ip *interpolator) walk(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			ip.walk(child)
+		}
+	case yaml.MappingNode:
+		// Apenas valores são expandidos; chaves fazem parte do schema
+		for i := 1; i < len(node.Content); i += 2 {
+			ip.walk(node.Content[i])
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return
+		}
+		value, err := ip.expand(node.Value)
+		if err != nil {
+			ip.errs = append(ip.errs, &FieldError{
+				Pos:     Position{File: ip.file, Line: node.Line, Column: node.Column},
+				Message: err.Error(),
+			})
+			return
+		}
+		if value == node.Value {
+			return
+		}
+		node.Value = value
+		// Escalares sem aspas voltam a ter o tipo resolvido (ex: replicas: ${N:-2} vira int)
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	}
+}
+
+func (ip *interpolator) expand(s string) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			value, err := ip.resolve(s[i+2 : i+2+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end + 2
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// resolve avalia o conteúdo entre ${ e }
+func (ip *interpolator) resolve(expr string) (string, error) {
+	name, op, arg := expr, "", ""
+	if idx := strings.Index(expr, ":"); idx >= 0 {
+		name, op = expr[:idx], expr[idx:]
+		if len(op) < 2 || (op[1] != '-' && op[1] != '?') {
+			return "", fmt.Errorf("invalid variable expression ${%s} (use ${VAR}, ${VAR:-default} or ${VAR:?error})", expr)
+		}
+		op, arg = op[:2], op[2:]
+	}
+
+	if !validVariableName(name) {
+		return "", fmt.Errorf("invalid variable name %q", name)
+	}
+
+	value, ok := ip.lookup(name)
+	switch op {
+	case ":-":
+		if !ok || value == "" {
+			return arg, nil
+		}
+	case ":?":
+		if !ok || value == "" {
+			if arg == "" {
+				arg = "required variable is not set"
+			}
+			return "", fmt.Errorf("${%s}: %s", name, arg)
+		}
+	}
+
+	return value, nil
+}
+
+func validVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func testLookup(vars map[string]string) LookupEnvFunc {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func TestInterpolatorExpand(t *testing.T) {
+	ip := &interpolator{lookup: testLookup(map[string]string{
+		"TAG":   "1.2",
+		"EMPTY": "",
+		"_X1":   "x",
+	})}
+
+	tests := []struct {
+		in   string
+		want string
+		err  string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "api:${TAG}", want: "api:1.2"},
+		{in: "${TAG}-${_X1}", want: "1.2-x"},
+		{in: "${MISSING}", want: ""},
+		{in: "$TAG", want: "$TAG"}, // só a forma com chaves é expandida
+		{in: "price: 5$", want: "price: 5$"},
+
+		// :- usa o padrão quando a variável falta ou está vazia
+		{in: "${MISSING:-2}", want: "2"},
+		{in: "${EMPTY:-fallback}", want: "fallback"},
+		{in: "${TAG:-fallback}", want: "1.2"},
+		{in: "${MISSING:-}", want: ""},
+		{in: "${MISSING:-a:b}", want: "a:b"},
+
+		// :? falha quando a variável falta ou está vazia
+		{in: "${TAG:?set TAG}", want: "1.2"},
+		{in: "${MISSING:?set MISSING}", err: "${MISSING}: set MISSING"},
+		{in: "${EMPTY:?}", err: "${EMPTY}: required variable is not set"},
+
+		// $$ é um $ literal e não abre referência
+		{in: "$$", want: "$"},
+		{in: "$${TAG}", want: "${TAG}"},
+		{in: "$$$${TAG}", want: "$${TAG}"},
+		{in: "$$${TAG}", want: "$1.2"},
+
+		// nomes e expressões inválidos
+		{in: "${}", err: `invalid variable name ""`},
+		{in: "${1ABC}", err: `invalid variable name "1ABC"`},
+		{in: "${A-B}", err: `invalid variable name "A-B"`},
+		{in: "${TAG:+x}", err: "invalid variable expression ${TAG:+x}"},
+		{in: "${TAG:}", err: "invalid variable expression ${TAG:}"},
+		{in: "${TAG-x}", err: `invalid variable name "TAG-x"`},
+		{in: "api:${TAG", err: "unterminated variable reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ip.expand(tt.in)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expand(%q) error = %v, want %q", tt.in, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expand(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolatorWalk(t *testing.T) {
+	var doc yaml.Node
+	data := "${KEY}: 1\nreplicas: ${N:-2}\nquoted: \"${N:-2}\"\nimage: api:${TAG:?}\n"
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	ip := &interpolator{file: "stack.yml", lookup: testLookup(nil)}
+	ip.walk(&doc)
+
+	// O erro aponta para o valor; os demais valores seguem expandidos
+	if len(ip.errs) != 1 || ip.errs[0].Pos != (Position{File: "stack.yml", Line: 4, Column: 8}) {
+		t.Fatalf("errs = %v, want one error at stack.yml:4:8", ip.errs)
+	}
+
+	root := documentRoot(&doc)
+	if key := root.Content[0].Value; key != "${KEY}" {
+		t.Fatalf("key = %q, want keys left as written", key)
+	}
+	if replicas := mappingValue(root, "replicas"); replicas.Value != "2" || replicas.ShortTag() != "!!int" {
+		t.Fatalf("replicas = %q (%s), want 2 as an int", replicas.Value, replicas.ShortTag())
+	}
+	if quoted := mappingValue(root, "quoted"); quoted.Value != "2" || quoted.ShortTag() != "!!str" {
+		t.Fatalf("quoted = %q (%s), want 2 as a string", quoted.Value, quoted.ShortTag())
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	_, err := loadFiles(t, "",
+		[2]string{"stack.yml", "project: demo\ninclude: services.yml\n"},
+		[2]string{"services.yml", "include: [networks.yml]\nservices: []\n"},
+		[2]string{"networks.yml", "networks: {}\ninclude: services.yml\n"},
+	)
+	if err == nil {
+		t.Fatal("Load() error = nil, want an include cycle")
+	}
+
+	// O erro aponta o include que fecha o ciclo e mostra a cadeia inteira
+	msg := err.Error()
+	for _, want := range []string{"networks.yml:2:10: include cycle: ", "stack.yml -> ", "services.yml -> ", "networks.yml -> "} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("Load() error = %q, want it to contain %q", msg, want)
+		}
+	}
+	if !strings.HasSuffix(msg, string(filepath.Separator)+"services.yml") {
+		t.Fatalf("Load() error = %q, want the chain to end at services.yml", msg)
+	}
+}
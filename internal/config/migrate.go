@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion é a versão do schema gerada e entendida por esta versão do harborctl.
+// Só muda junto com uma migração, quando um formato antigo deixa de ser aceito
+const CurrentVersion = 1
+
+// Migration atualiza um documento de uma versão para a seguinte
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(root *yaml.Node) error
+}
+
+// migrations registra as migrações em ordem; cada uma leva From -> From+1.
+// Vazio enquanto nenhuma mudança incompatível exigir reescrever arquivos
+var migrations = []Migration{}
+
+// MigrationResult descreve o que foi aplicado em um documento
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Applied     []Migration
+}
+
+// Changed indica se alguma migração foi aplicada
+func (r *MigrationResult) Changed() bool {
+	return len(r.Applied) > 0
+}
+
+// documentVersion lê a versão declarada (ausente conta como 1)
+func documentVersion(root *yaml.Node) (int, *yaml.Node, error) {
+	node := mappingValue(root, "version")
+	if node == nil || node.Value == "" {
+		return 1, nil, nil
+	}
+	version, err := strconv.Atoi(node.Value)
+	if err != nil {
+		return 0, node, fmt.Errorf("%d:%d: version must be an integer, got %q", node.Line, node.Column, node.Value)
+	}
+	return version, node, nil
+}
+
+// migrateDocument aplica as migrações necessárias no documento, em memória
+func migrateDocument(doc *yaml.Node) (*MigrationResult, error) {
+	return applyMigrations(doc, migrations, CurrentVersion)
+}
+
+// applyMigrations leva o documento até current encadeando steps; separado de
+// migrateDocument para que os testes usem um registro próprio
+func applyMigrations(doc *yaml.Node, steps []Migration, current int) (*MigrationResult, error) {
+	root := documentRoot(doc)
+	if root.Kind != yaml.MappingNode {
+		return &MigrationResult{FromVersion: current, ToVersion: current}, nil
+	}
+
+	version, node, err := documentVersion(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > current {
+		pos := ""
+		if node != nil {
+			pos = fmt.Sprintf("%d:%d: ", node.Line, node.Column)
+		}
+		return nil, fmt.Errorf("%sschema version %d is newer than supported version %d; upgrade harborctl", pos, version, current)
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("invalid schema version %d", version)
+	}
+
+	result := &MigrationResult{FromVersion: version, ToVersion: version}
+	for _, m := range steps {
+		if m.From != result.ToVersion {
+			continue
+		}
+		if err := m.Apply(root); err != nil {
+			return nil, fmt.Errorf("migration v%d -> v%d failed: %w", m.From, m.From+1, err)
+		}
+		result.ToVersion = m.From + 1
+		result.Applied = append(result.Applied, m)
+	}
+
+	if result.ToVersion != current {
+		return nil, fmt.Errorf("no migration path from version %d to %d", result.ToVersion, current)
+	}
+
+	if result.Changed() {
+		setMappingValue(root, "version", scalarNode(result.ToVersion))
+	}
+
+	return result, nil
+}
+
+// requireCurrentVersion recusa documentos fora da versão atual; edições não migram o
+// arquivo, para alterar só os campos pedidos
+func requireCurrentVersion(doc *yaml.Node) error {
+	root := documentRoot(doc)
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	version, node, err := documentVersion(root)
+	if err != nil {
+		return err
+	}
+	pos := ""
+	if node != nil {
+		pos = fmt.Sprintf("%d:%d: ", node.Line, node.Column)
+	}
+	switch {
+	case version > CurrentVersion:
+		return fmt.Errorf("%sschema version %d is newer than supported version %d; upgrade harborctl", pos, version, CurrentVersion)
+	case version < CurrentVersion:
+		return fmt.Errorf("%sschema version %d is older than %d; run 'harborctl migrate' first", pos, version, CurrentVersion)
+	}
+	return nil
+}
+
+// MigrateFile atualiza o conteúdo de um stack.yml/server-base.yml preservando comentários
+func MigrateFile(data []byte) ([]byte, *MigrationResult, error) {
+	return migrateFile(data, migrations, CurrentVersion)
+}
+
+func migrateFile(data []byte, steps []Migration, current int) ([]byte, *MigrationResult, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	result, err := applyMigrations(doc.root, steps, current)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !result.Changed() {
+		return data, result, nil
+	}
+
+	doc.changed = true
+	out, err := doc.Bytes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	return out, result, nil
+}
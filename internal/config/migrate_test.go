@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leandrodaf/harborctl/pkg/textdiff"
+	"gopkg.in/yaml.v3"
+)
+
+// renameKey troca só o nome da chave, mantendo valor e comentários no lugar
+func renameKey(from, to string) func(root *yaml.Node) error {
+	return func(root *yaml.Node) error {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == from {
+				root.Content[i].Value = to
+				return nil
+			}
+		}
+		return fmt.Errorf("%s not found", from)
+	}
+}
+
+// testMigrations registra v1 -> v2 -> v3; a segunda depende da primeira ter rodado
+func testMigrations(applied *[]int) []Migration {
+	step := func(from int, apply func(root *yaml.Node) error) Migration {
+		return Migration{
+			From:        from,
+			Description: fmt.Sprintf("step %d", from),
+			Apply: func(root *yaml.Node) error {
+				*applied = append(*applied, from)
+				return apply(root)
+			},
+		}
+	}
+	return []Migration{
+		step(1, renameKey("domain", "host")),
+		step(2, renameKey("host", "hostname")),
+	}
+}
+
+const migrateFixture = `# Stack antiga
+version: 1
+project: demo
+
+# domínio público
+domain: example.com   # produção
+services:
+  - name: api
+    image: api:1
+`
+
+func TestMigrateRefusesFutureVersion(t *testing.T) {
+	var applied []int
+	data := strings.Replace(migrateFixture, "version: 1", "version: 4", 1)
+
+	_, _, err := migrateFile([]byte(data), testMigrations(&applied), 3)
+	if err == nil || !strings.Contains(err.Error(), "2:10: schema version 4 is newer than supported version 3") {
+		t.Fatalf("migrateFile() error = %v, want a newer version error at 2:10", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("migrations ran on a future version: %v", applied)
+	}
+
+	// O registro real recusa do mesmo jeito
+	data = strings.Replace(migrateFixture, "version: 1", fmt.Sprintf("version: %d", CurrentVersion+1), 1)
+	if _, _, err := MigrateFile([]byte(data)); err == nil || !strings.Contains(err.Error(), "upgrade harborctl") {
+		t.Fatalf("MigrateFile() error = %v, want an upgrade harborctl error", err)
+	}
+}
+
+func TestMigrateRunsStepsInOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		applied  []int
+		fromWant int
+	}{
+		{"from v1", "version: 1", []int{1, 2}, 1},
+		{"missing version counts as v1", "", []int{1, 2}, 1},
+		{"from v2", "version: 2", []int{2}, 2},
+		{"already current", "version: 3", nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := strings.Replace(migrateFixture, "version: 1\n", tt.version+"\n", 1)
+			if tt.version == "version: 2" {
+				data = strings.Replace(data, "domain:", "host:", 1)
+			}
+			if tt.version == "version: 3" {
+				data = strings.Replace(data, "domain:", "hostname:", 1)
+			}
+
+			var applied []int
+			out, result, err := migrateFile([]byte(data), testMigrations(&applied), 3)
+			if err != nil {
+				t.Fatalf("migrateFile() error = %v", err)
+			}
+			if fmt.Sprint(applied) != fmt.Sprint(tt.applied) {
+				t.Fatalf("applied steps = %v, want %v", applied, tt.applied)
+			}
+			if result.FromVersion != tt.fromWant || result.ToVersion != 3 {
+				t.Fatalf("result = v%d -> v%d, want v%d -> v3", result.FromVersion, result.ToVersion, tt.fromWant)
+			}
+			if len(result.Applied) != len(tt.applied) {
+				t.Fatalf("result.Applied = %d steps, want %d", len(result.Applied), len(tt.applied))
+			}
+			if !strings.Contains(string(out), "hostname: example.com") {
+				t.Fatalf("migrated file has no hostname:\n%s", out)
+			}
+			if !result.Changed() && string(out) != data {
+				t.Fatalf("current file was rewritten:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestMigrateMissingStep(t *testing.T) {
+	var applied []int
+	steps := testMigrations(&applied)[:1]
+
+	if _, _, err := migrateFile([]byte(migrateFixture), steps, 3); err == nil || !strings.Contains(err.Error(), "no migration path from version 2 to 3") {
+		t.Fatalf("migrateFile() error = %v, want a missing path error", err)
+	}
+}
+
+func TestMigrateFileKeepsComments(t *testing.T) {
+	var applied []int
+	out, _, err := migrateFile([]byte(migrateFixture), testMigrations(&applied), 3)
+	if err != nil {
+		t.Fatalf("migrateFile() error = %v", err)
+	}
+
+	// O comentário continua na mesma coluna
+	want := strings.NewReplacer("version: 1", "version: 3", "domain: example.com   #", "hostname: example.com #").Replace(migrateFixture)
+	if string(out) != want {
+		t.Fatalf("migrated file:\n%s\nwant\n%s", out, want)
+	}
+
+	// O diff mostrado pelo comando migrate só traz as linhas alteradas
+	diff := textdiff.Unified("stack.yml", "stack.yml (migrated)", migrateFixture, string(out))
+	for _, line := range []string{"-version: 1", "+version: 3", "-domain: example.com   # produção", "+hostname: example.com # produção"} {
+		if !strings.Contains(diff, line+"\n") {
+			t.Errorf("diff has no %q:\n%s", line, diff)
+		}
+	}
+	if strings.Contains(diff, "-# domínio público") || strings.Contains(diff, "-project: demo") {
+		t.Errorf("diff touches unchanged lines:\n%s", diff)
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import "fmt"
+
+// checkMirror valida as versões shadow dos serviços
+func checkMirror(stack *Stack, report reportFunc) {
+	names := make(map[string]bool, len(stack.Services))
+	for _, sv := range stack.Services {
+		names[sv.Name] = true
+	}
+
+	for i, sv := range stack.Services {
+		mirror := sv.Mirror
+		if mirror == nil {
+			continue
+		}
+		path := servicePath(i, "mirror")
+
+		if traefik := sv.GetTraefik(); traefik == nil || !traefik.Enabled {
+			report(path, fmt.Sprintf("%s: mirror requires traefik", sv.Name), "set 'traefik: true', requests are copied by Traefik")
+		}
+		if sv.Expose <= 0 && len(sv.Routes) == 0 {
+			report(path, fmt.Sprintf("%s: mirror needs an http port", sv.Name), "set 'expose'")
+		}
+		if mirror.Image == "" {
+			report(path+".image", fmt.Sprintf("%s: mirror needs 'image'", sv.Name), "set the image of the version under test")
+		}
+		if mirror.Percent < 0 || mirror.Percent > 100 {
+			report(path+".percent", fmt.Sprintf("%s: mirror percent %d out of range", sv.Name, mirror.Percent), "use a percentage between 1 and 100")
+		}
+		if mirror.MaxBodySize < 0 {
+			report(path+".max_body_size", fmt.Sprintf("%s: invalid max_body_size %d", sv.Name, mirror.MaxBodySize), "use a size in bytes, or omit it for no limit")
+		}
+		if sv.Progressive() {
+			report(path, fmt.Sprintf("%s: mirror cannot be combined with %s", sv.Name, sv.Deploy.Strategy), "promote or discard the shadow before switching strategy")
+		}
+		if shadow := sv.Name + "-shadow"; names[shadow] {
+			report(path, fmt.Sprintf("%s: shadow service name %s is already used", sv.Name, shadow), "rename the other service")
+		}
+	}
+}
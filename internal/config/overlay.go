@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// replaceTag força a substituição de um mapa ou lista em vez da mesclagem
+const replaceTag = "!replace"
+
+var envNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// OverlayPath retorna o arquivo de overlay de um ambiente (stack.yml -> stack.staging.yml)
+func OverlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// validateEnvName garante que o nome do ambiente pode compor um nome de arquivo
+func validateEnvName(env string) error {
+	if !envNamePattern.MatchString(env) {
+		return fmt.Errorf("invalid environment name %q (use lowercase letters, digits, '-' and '_')", env)
+	}
+	return nil
+}
+
+// stripMergeTags remove as tags de mesclagem depois que os overlays foram aplicados
+func stripMergeTags(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	if node.Tag == replaceTag {
+		node.Tag = ""
+	}
+	for _, child := range node.Content {
+		stripMergeTags(child)
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeYAML mescla override sobre base e devolve o YAML resultante, sem as tags de mesclagem
+func mergeYAML(t *testing.T, base, override string, order mergeOrder) string {
+	t.Helper()
+	var b, o yaml.Node
+	if err := yaml.Unmarshal([]byte(base), &b); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(override), &o); err != nil {
+		t.Fatal(err)
+	}
+	merged := mergeNodes(documentRoot(&b), documentRoot(&o), order)
+	stripMergeTags(merged)
+	out, err := encodeDocument(merged, []byte(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestMergeNodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		override string
+		order    mergeOrder
+		want     string
+	}{
+		{
+			name:     "mappings merge recursively in base order",
+			base:     "a: 1\nb: {x: 1, y: 2}\nc: 3\n",
+			override: "d: 4\nb: {y: 20, z: 30}\n",
+			order:    baseOrder,
+			want:     "a: 1\nb: {x: 1, y: 20, z: 30}\nc: 3\nd: 4\n",
+		},
+		{
+			name:     "mappings merge recursively in override order",
+			base:     "a: 1\nb: {x: 1, y: 2}\n",
+			override: "b: {y: 20}\nc: 3\n",
+			order:    overrideOrder,
+			want:     "b: {y: 20, x: 1}\nc: 3\na: 1\n",
+		},
+		{
+			name: "named lists merge by name",
+			base: `services:
+  - {name: api, image: api-1, replicas: 2}
+  - {name: web, image: web-1}
+`,
+			override: `services:
+  - {name: worker, image: worker-1}
+  - {name: api, image: api-2}
+`,
+			order: baseOrder,
+			want: `services:
+  - {name: api, image: api-2, replicas: 2}
+  - {name: web, image: web-1}
+  - {name: worker, image: worker-1}
+`,
+		},
+		{
+			name: "nested maps of a named item merge too",
+			base: `services:
+  - name: api
+    env: {A: "1", B: "2"}
+`,
+			override: `services:
+  - name: api
+    env: {B: "20"}
+`,
+			order: baseOrder,
+			want: `services:
+  - name: api
+    env: {A: "1", B: "20"}
+`,
+		},
+		{
+			name:     "lists without names are replaced",
+			base:     "ports: [80, 443]\n",
+			override: "ports: [8080]\n",
+			order:    baseOrder,
+			want:     "ports: [8080]\n",
+		},
+		{
+			name: "replace tag overrides a named list",
+			base: `services:
+  - {name: api, image: api-1}
+  - {name: web, image: web-1}
+`,
+			override: `services: !replace
+  - {name: api, image: api-2}
+`,
+			order: baseOrder,
+			want: `services:
+  - {name: api, image: api-2}
+`,
+		},
+		{
+			name:     "replace tag overrides a mapping",
+			base:     "env: {A: \"1\", B: \"2\"}\n",
+			override: "env: !replace {C: \"3\"}\n",
+			order:    baseOrder,
+			want:     "env: {C: \"3\"}\n",
+		},
+		{
+			name: "replace tag inside a named item",
+			base: `services:
+  - name: api
+    env: {A: "1"}
+    replicas: 2
+`,
+			override: `services:
+  - name: api
+    env: !replace {B: "2"}
+`,
+			order: baseOrder,
+			want: `services:
+  - name: api
+    env: {B: "2"}
+    replicas: 2
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeYAML(t, tt.base, tt.override, tt.order); got != tt.want {
+				t.Fatalf("merged:\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlayMergesServicesByName(t *testing.T) {
+	stack, err := loadFiles(t, "staging",
+		[2]string{"stack.yml", `version: 1
+project: demo
+services:
+  - name: api
+    image: api:1
+    replicas: 2
+    env: {LOG: info, REGION: eu}
+  - name: web
+    image: web:1
+`},
+		[2]string{"stack.staging.yml", `services:
+  - name: api
+    image: api:2-rc
+    env: !replace {LOG: debug}
+  - name: debug
+    image: busybox:1
+`},
+	)
+	if err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+
+	var names []string
+	for _, service := range stack.Services {
+		names = append(names, service.Name)
+	}
+	if len(names) != 3 || names[0] != "api" || names[1] != "web" || names[2] != "debug" {
+		t.Fatalf("services = %v, want [api web debug]", names)
+	}
+	api := stack.Services[0]
+	if api.Image != "api:2-rc" || api.Replicas != 2 {
+		t.Fatalf("api = image %s, replicas %d, want api:2-rc with the base replicas", api.Image, api.Replicas)
+	}
+	if len(api.Env) != 1 || api.Env["LOG"] != "debug" {
+		t.Fatalf("api.env = %v, want only LOG=debug", api.Env)
+	}
+}
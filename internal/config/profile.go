@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Presets de ambiente embutidos
+const (
+	PresetLocal      = "local"
+	PresetProduction = "production"
+)
+
+// Profile define um ambiente nomeado (staging, qa, preview...) a partir de um preset.
+// Campos vazios herdam do perfil estendido.
+type Profile struct {
+	Extends     string     `yaml:"extends,omitempty"`     // local, production ou outro perfil (padrão: production)
+	TLSMode     string     `yaml:"tls_mode,omitempty"`    // acme | selfsigned | disabled
+	Hardening   *bool      `yaml:"hardening,omitempty"`   // security_opt, cap_drop, usuário não-root...
+	Middlewares []string   `yaml:"middlewares,omitempty"` // middlewares padrão dos serviços roteados
+	Dashboard   *bool      `yaml:"dashboard,omitempty"`   // expõe o dashboard do Traefik na porta 8080
+	Resources   *Resources `yaml:"resources,omitempty"`   // limites para serviços sem resources
+}
+
+// PresetName normaliza aliases de presets (dev -> local, prod -> production)
+func PresetName(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "local", "development", "dev":
+		return PresetLocal, true
+	case "production", "prod":
+		return PresetProduction, true
+	}
+	return "", false
+}
+
+// HasEnvironment indica se o nome é um preset ou um perfil declarado
+func (s *Stack) HasEnvironment(name string) bool {
+	if _, ok := s.Profiles[strings.ToLower(name)]; ok {
+		return true
+	}
+	_, ok := PresetName(name)
+	return ok
+}
+
+// normalizeProfiles deixa os nomes em profiles: minúsculos, como environment e extends são
+// comparados, e recusa nomes que só diferem na caixa
+func normalizeProfiles(path string, root *yaml.Node) error {
+	profiles := mappingValue(root, "profiles")
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	type declared struct {
+		name string
+		line int
+	}
+	seen := make(map[string]declared, len(profiles.Content)/2)
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		key := profiles.Content[i]
+		name := strings.ToLower(key.Value)
+		if first, ok := seen[name]; ok {
+			return fmt.Errorf("%s:%d:%d: profile %q duplicates %q (line %d); profile names are case-insensitive", path, key.Line, key.Column, key.Value, first.name, first.line)
+		}
+		seen[name] = declared{name: key.Value, line: key.Line}
+		key.Value = name
+	}
+	return nil
+}
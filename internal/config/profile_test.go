@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leandrodaf/harborctl/pkg/fs"
+)
+
+// loadFiles grava files num diretório temporário e carrega o primeiro com o overlay de env
+func loadFiles(t *testing.T, env string, files ...[2]string) (*Stack, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file[0]), []byte(file[1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filesystem := fs.NewFileSystem()
+	m := NewManager(fs.NewConfigLoader(filesystem), filesystem, NewValidator())
+	return m.LoadEnv(context.Background(), filepath.Join(dir, files[0][0]), env)
+}
+
+func TestProfileNamesAreNormalized(t *testing.T) {
+	stack, err := loadFiles(t, "", [2]string{"stack.yml", `project: demo
+environment: Staging
+profiles:
+  Staging: {extends: QA, tls_mode: selfsigned}
+  QA: {extends: Local}
+`})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := sortedProfileNames(stack); !reflect.DeepEqual(got, []string{"qa", "staging"}) {
+		t.Fatalf("profiles = %v, want [qa staging]", got)
+	}
+	if !stack.HasEnvironment("STAGING") || !stack.HasEnvironment("qa") {
+		t.Fatal("HasEnvironment() = false for a declared profile")
+	}
+	if profile := stack.Profiles["staging"]; profile.TLSMode != "selfsigned" {
+		t.Fatalf("profiles.staging = %+v, want tls_mode selfsigned", profile)
+	}
+}
+
+func TestProfileNamesMergeAcrossOverlay(t *testing.T) {
+	stack, err := loadFiles(t, "prod",
+		[2]string{"stack.yml", "project: demo\nprofiles:\n  Preview: {tls_mode: disabled}\n"},
+		[2]string{"stack.prod.yml", "profiles:\n  preview: {tls_mode: selfsigned}\n"},
+	)
+	if err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	if len(stack.Profiles) != 1 || stack.Profiles["preview"].TLSMode != "selfsigned" {
+		t.Fatalf("profiles = %+v, want the overlay to override preview", stack.Profiles)
+	}
+}
+
+func TestProfileNamesCollision(t *testing.T) {
+	_, err := loadFiles(t, "", [2]string{"stack.yml", `project: demo
+profiles:
+  staging: {}
+  Staging: {}
+`})
+	if err == nil || !strings.Contains(err.Error(), `stack.yml:4:3: profile "Staging" duplicates "staging" (line 3)`) {
+		t.Fatalf("Load() error = %v, want a case-insensitive duplicate at 4:3", err)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"sort"
+)
+
+// Severity classifica um achado da validação
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// rank ordena severidades da mais grave para a menos grave
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Finding é um problema encontrado por uma regra de validação
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"`
+	Position Position `json:"-"`
+	Location string   `json:"location,omitempty"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint,omitempty"`
+}
+
+// Report agrega todos os achados de uma validação
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Add adiciona um achado ao relatório
+func (r *Report) Add(f Finding) {
+	if f.Location == "" {
+		f.Location = f.Position.String()
+	}
+	r.Findings = append(r.Findings, f)
+}
+
+// Count retorna quantos achados têm a severidade informada
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors indica se algum achado é do tipo erro
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+// Sort ordena por severidade e depois pela posição no arquivo
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Severity.rank() != b.Severity.rank() {
+			return a.Severity.rank() < b.Severity.rank()
+		}
+		if a.Position.Line != b.Position.Line {
+			return a.Position.Line < b.Position.Line
+		}
+		return a.Position.Column < b.Position.Column
+	})
+}
+
+// Err converte os achados de erro em um único error (nil se não houver erros)
+func (r *Report) Err() error {
+	var errs FieldErrors
+	for _, f := range r.Findings {
+		if f.Severity != SeverityError {
+			continue
+		}
+		errs = append(errs, &FieldError{
+			Pos:     f.Position,
+			Path:    f.Path,
+			Message: f.Message,
+		})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RouteName retorna o nome do router e do service da rota i no Traefik
+func (s *Service) RouteName(i int) string {
+	if s.Routes[i].Name != "" {
+		return s.Name + "-" + s.Routes[i].Name
+	}
+	return fmt.Sprintf("%s-%d", s.Name, i)
+}
+
+// RouteHosts retorna os hosts da rota, ou o subdomínio do serviço quando não informados
+func (s *Service) RouteHosts(route Route, domain string) []string {
+	if len(route.Hosts) > 0 {
+		return route.Hosts
+	}
+	return []string{s.Subdomain + "." + domain}
+}
+
+var (
+	routeNameFormat  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	headerNameFormat = regexp.MustCompile(`^[!#$%&'*+.^_|~0-9A-Za-z-]+$`)
+)
+
+var routeMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true, "CONNECT": true, "TRACE": true,
+}
+
+// checkRoutes valida as rotas HTTP de um serviço
+func checkRoutes(stack *Stack, i int, sv Service, report reportFunc) {
+	if len(sv.Routes) == 0 {
+		return
+	}
+	if traefik := sv.GetTraefik(); traefik == nil || !traefik.Enabled {
+		report(servicePath(i, "routes"), fmt.Sprintf("%s: routes require traefik", sv.Name), "set 'traefik: true'")
+	}
+	if sv.Traefik != nil && sv.Traefik.CustomRule() != "" {
+		report(servicePath(i, "routes"), fmt.Sprintf("%s: traefik.rule and traefik.match cannot be combined with routes", sv.Name), "describe each match as a route")
+	}
+
+	entryPoints := knownEntryPoints(stack)
+	names := make(map[string]bool)
+	for j, route := range sv.Routes {
+		path := servicePath(i, fmt.Sprintf("routes[%d]", j))
+
+		if route.Name != "" && !routeNameFormat.MatchString(route.Name) {
+			report(path+".name", fmt.Sprintf("%s: invalid route name %q", sv.Name, route.Name), "use lowercase letters, digits, '-' and '_'")
+		}
+		if name := sv.RouteName(j); names[name] {
+			report(path+".name", fmt.Sprintf("%s: duplicate route %q", sv.Name, name), "give each route a distinct name")
+		} else {
+			names[name] = true
+		}
+
+		if len(route.Hosts) == 0 && sv.Subdomain == "" && len(sv.Domains) == 0 {
+			report(path+".hosts", fmt.Sprintf("%s: route has no hosts and the service has no subdomain or domains", sv.Name), "add 'hosts: [app.example.com]' or set 'subdomain'")
+		}
+		for k, host := range route.Hosts {
+			if strings.HasPrefix(host, "*.") || !hostnameFormat.MatchString(host) {
+				report(fmt.Sprintf("%s.hosts[%d]", path, k), fmt.Sprintf("%s: invalid route host %q", sv.Name, host), "use a full hostname without scheme or port (ex: example.com)")
+			}
+		}
+		for k, prefix := range route.Paths {
+			if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, " `") {
+				report(fmt.Sprintf("%s.paths[%d]", path, k), fmt.Sprintf("%s: invalid path prefix %q", sv.Name, prefix), "use an absolute path (ex: /api)")
+			}
+		}
+		if route.StripPrefix && len(route.Paths) == 0 {
+			report(path+".strip_prefix", fmt.Sprintf("%s: strip_prefix needs paths", sv.Name), "add 'paths' or remove 'strip_prefix'")
+		}
+		for k, method := range route.Methods {
+			if !routeMethods[strings.ToUpper(method)] {
+				report(fmt.Sprintf("%s.methods[%d]", path, k), fmt.Sprintf("%s: invalid method %q", sv.Name, method), "use an HTTP method like GET or POST")
+			}
+		}
+		for _, header := range sortedKeys(route.Headers) {
+			if !headerNameFormat.MatchString(header) || strings.Contains(route.Headers[header], "`") {
+				report(path+".headers."+header, fmt.Sprintf("%s: invalid header match %q", sv.Name, header), "use a plain header name and a value without backticks")
+			}
+		}
+
+		if route.Port < 0 || route.Port > 65535 {
+			report(path+".port", fmt.Sprintf("%s: invalid route port %d", sv.Name, route.Port), "use a port between 1 and 65535")
+		} else if route.Port == 0 && sv.Expose <= 0 {
+			report(path+".port", fmt.Sprintf("%s: route has no port and the service has no expose", sv.Name), "set 'port' on the route or 'expose' on the service")
+		}
+		for k, ep := range route.EntryPoints {
+			if !entryPoints[ep] {
+				report(fmt.Sprintf("%s.entrypoints[%d]", path, k), fmt.Sprintf("%s: unknown entrypoint %q", sv.Name, ep), "use web, websecure or one declared in 'traefik.entrypoints'")
+			}
+		}
+		for k, mw := range route.Middlewares {
+			if !traefikNameFormat.MatchString(mw) {
+				report(fmt.Sprintf("%s.middlewares[%d]", path, k), fmt.Sprintf("%s: invalid middleware name %q", sv.Name, mw), "use a middleware name, optionally with @provider (ex: auth@file)")
+			}
+		}
+		if route.Priority < 0 {
+			report(path+".priority", fmt.Sprintf("%s: priority cannot be negative", sv.Name), "use 0 for the default or a positive number")
+		}
+	}
+}
+
+// routeMatch é o que um router HTTP casa, usado para detectar conflitos entre rotas
+type routeMatch struct {
+	path        string
+	router      string
+	hosts       []string
+	paths       []string
+	methods     string
+	headers     string
+	entryPoints []string
+	priority    int
+}
+
+// routeMatches lista os routers HTTP da stack que podem ser comparados (regras customizadas ficam de fora)
+func routeMatches(stack *Stack) []routeMatch {
+	var matches []routeMatch
+
+	defaultEntryPoints := []string{"web", "websecure"}
+	for i, sv := range stack.Services {
+		traefik := sv.GetTraefik()
+		if traefik == nil || !traefik.Enabled || traefik.CustomRule() != "" {
+			continue
+		}
+		entryPoints := defaultEntryPoints
+		if len(traefik.EntryPoints) > 0 {
+			entryPoints = traefik.EntryPoints
+		}
+
+		// Domínios próprios respondem nas rotas sem hosts; redirects casam qualquer caminho
+		var domainHosts []string
+		for j, d := range sv.Domains {
+			domainHosts = append(domainHosts, d.Host)
+			if len(d.RedirectFrom) > 0 {
+				matches = append(matches, routeMatch{
+					path: servicePath(i, fmt.Sprintf("domains[%d].redirect_from", j)), router: sv.Name + "-" + d.Slug() + "-redirect",
+					hosts: d.RedirectFrom, paths: []string{"/"},
+					entryPoints: entryPoints, priority: traefik.Priority,
+				})
+			}
+		}
+
+		if len(sv.Routes) == 0 {
+			hosts := domainHosts
+			if sv.Subdomain != "" {
+				hosts = append([]string{sv.Subdomain + "." + stack.Domain}, domainHosts...)
+			}
+			if len(hosts) > 0 {
+				matches = append(matches, routeMatch{
+					path: servicePath(i, "subdomain"), router: sv.Name,
+					hosts: hosts, paths: []string{"/"},
+					entryPoints: entryPoints, priority: traefik.Priority,
+				})
+			}
+			continue
+		}
+
+		for j, route := range sv.Routes {
+			if len(route.Hosts) == 0 && sv.Subdomain == "" && len(domainHosts) == 0 {
+				continue // sem host, já reportado pelo SVC012
+			}
+			match := routeMatch{
+				path:        servicePath(i, fmt.Sprintf("routes[%d]", j)),
+				router:      sv.RouteName(j),
+				hosts:       sv.RouteHosts(route, stack.Domain),
+				paths:       route.Paths,
+				entryPoints: entryPoints,
+				priority:    route.Priority,
+			}
+			if len(route.Hosts) == 0 {
+				match.hosts = domainHosts
+				if sv.Subdomain != "" {
+					match.hosts = append([]string{sv.Subdomain + "." + stack.Domain}, domainHosts...)
+				}
+			}
+			if len(match.paths) == 0 {
+				match.paths = []string{"/"}
+			}
+			if len(route.EntryPoints) > 0 {
+				match.entryPoints = route.EntryPoints
+			}
+			if match.priority == 0 {
+				match.priority = traefik.Priority
+			}
+
+			methods := make([]string, len(route.Methods))
+			for k, method := range route.Methods {
+				methods[k] = strings.ToUpper(method)
+			}
+			sort.Strings(methods)
+			match.methods = strings.Join(methods, ",")
+
+			headers := make([]string, 0, len(route.Headers))
+			for _, name := range sortedKeys(route.Headers) {
+				headers = append(headers, strings.ToLower(name)+"="+route.Headers[name])
+			}
+			match.headers = strings.Join(headers, ",")
+
+			matches = append(matches, match)
+		}
+	}
+
+	if stack.Traefik != nil {
+		for _, name := range sortedKeys(stack.Traefik.Backends) {
+			backend := stack.Traefik.Backends[name]
+			if backend.CustomRule() != "" || backend.Subdomain == "" {
+				continue
+			}
+			matches = append(matches, routeMatch{
+				path: "traefik.backends." + name, router: name,
+				hosts: []string{backend.Subdomain + "." + stack.Domain}, paths: []string{"/"},
+				entryPoints: defaultEntryPoints,
+			})
+		}
+	}
+
+	return matches
+}
+
+// overlap retorna o primeiro valor presente nas duas listas (sem diferenciar maiúsculas)
+func overlap(a, b []string) string {
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				return x
+			}
+		}
+	}
+	return ""
+}
+
+// checkRouteConflicts detecta routers que casam exatamente as mesmas requisições com a mesma prioridade
+func checkRouteConflicts(stack *Stack, report reportFunc) {
+	matches := routeMatches(stack)
+	for a := range matches {
+		for b := 0; b < a; b++ {
+			first, second := matches[b], matches[a]
+			if first.priority != second.priority || first.methods != second.methods || first.headers != second.headers {
+				continue
+			}
+			if overlap(first.entryPoints, second.entryPoints) == "" {
+				continue
+			}
+			host := overlap(first.hosts, second.hosts)
+			prefix := overlap(first.paths, second.paths)
+			if host == "" || prefix == "" {
+				continue
+			}
+			report(second.path, fmt.Sprintf("route %s conflicts with %s on %s%s", second.router, first.router, host, prefix),
+				"use different hosts or paths, or set a 'priority' to pick the winner")
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/pkg/traefikrule"
+)
+
+// Rule compila o bloco para a sintaxe de regras do Traefik v3
+func (m RuleMatch) Rule() string {
+	return strings.Join(m.parts(), " && ")
+}
+
+// parts retorna as condições do bloco, combinadas com &&
+func (m RuleMatch) parts() []string {
+	var parts []string
+
+	add := func(matcher string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		items := make([]string, len(values))
+		for i, value := range values {
+			items[i] = fmt.Sprintf("%s(`%s`)", matcher, value)
+		}
+		parts = append(parts, group(items))
+	}
+	addPairs := func(matcher string, pairs map[string]string) {
+		keys := make([]string, 0, len(pairs))
+		for key := range pairs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%s(`%s`, `%s`)", matcher, key, pairs[key]))
+		}
+	}
+
+	add("Host", m.Host)
+	add("HostRegexp", m.HostRegexp)
+	add("Path", m.Path)
+	add("PathPrefix", m.PathPrefix)
+	add("PathRegexp", m.PathRegexp)
+	methods := make([]string, len(m.Method))
+	for i, method := range m.Method {
+		methods[i] = strings.ToUpper(method)
+	}
+	add("Method", methods)
+	addPairs("Header", m.Header)
+	addPairs("HeaderRegexp", m.HeaderRegexp)
+	addPairs("Query", m.Query)
+	add("ClientIP", m.ClientIP)
+
+	if len(m.Any) > 0 {
+		alternatives := make([]string, len(m.Any))
+		for i, sub := range m.Any {
+			alternatives[i] = sub.expression()
+		}
+		parts = append(parts, group(alternatives))
+	}
+	if m.Not != nil {
+		parts = append(parts, "!"+m.Not.expression())
+	}
+
+	return parts
+}
+
+// expression retorna o bloco como um único operando, entre parênteses quando tem várias condições
+func (m RuleMatch) expression() string {
+	parts := m.parts()
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " && ") + ")"
+}
+
+// group junta alternativas com ||, entre parênteses quando há mais de uma
+func group(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return "(" + strings.Join(items, " || ") + ")"
+}
+
+// CustomRule retorna a regra customizada do serviço (rule ou match compilado), ou "" para a padrão
+func (t *ServiceTraefik) CustomRule() string {
+	if t.Rule != "" {
+		return t.Rule
+	}
+	if t.Match != nil {
+		return t.Match.Rule()
+	}
+	return ""
+}
+
+// CustomRule retorna a regra do backend (rule ou match compilado), ou "" para Host(subdomain.domain)
+func (b TraefikBackend) CustomRule() string {
+	if b.Rule != "" {
+		return b.Rule
+	}
+	if b.Match != nil {
+		return b.Match.Rule()
+	}
+	return ""
+}
+
+// checkRule valida a sintaxe de uma regra do Traefik
+func checkRule(name, rule, path string, report reportFunc) {
+	if _, err := traefikrule.Parse(rule); err != nil {
+		report(path, fmt.Sprintf("%s: invalid rule %q: %v", name, rule, err), "see https://doc.traefik.io/traefik/routing/routers/#rule")
+	}
+}
+
+// checkRuleSource valida rule ou match, que são mutuamente exclusivos
+func checkRuleSource(name, rule string, match *RuleMatch, path string, report reportFunc) {
+	switch {
+	case rule != "" && match != nil:
+		report(path+".match", fmt.Sprintf("%s: rule and match are mutually exclusive", name), "keep only one of them")
+	case rule != "":
+		checkRule(name, rule, path+".rule", report)
+	case match != nil:
+		compiled := match.Rule()
+		if compiled == "" || hasEmptyMatch(*match) {
+			report(path+".match", fmt.Sprintf("%s: match has no matchers", name), "add host, pathPrefix, method, header...")
+			return
+		}
+		if strings.Contains(compiled, "``") || strings.Count(compiled, "`")%2 != 0 {
+			report(path+".match", fmt.Sprintf("%s: match values cannot be empty or contain backticks", name), "remove the backticks")
+			return
+		}
+		checkRule(name, compiled, path+".match", report)
+	}
+}
+
+// hasEmptyMatch indica blocos any/not sem nenhum matcher
+func hasEmptyMatch(m RuleMatch) bool {
+	for _, sub := range m.Any {
+		if sub.Rule() == "" || hasEmptyMatch(sub) {
+			return true
+		}
+	}
+	return m.Not != nil && (m.Not.Rule() == "" || hasEmptyMatch(*m.Not))
+}
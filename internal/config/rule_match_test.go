@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leandrodaf/harborctl/pkg/traefikrule"
+)
+
+func TestRuleMatchRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    RuleMatch
+		want     string
+		matchers []string
+	}{
+		{
+			name:     "single host",
+			match:    RuleMatch{Host: []string{"api.example.com"}},
+			want:     "Host(`api.example.com`)",
+			matchers: []string{"Host"},
+		},
+		{
+			name:     "alternatives of one matcher are grouped",
+			match:    RuleMatch{Host: []string{"a.com", "b.com"}, PathPrefix: []string{"/api"}},
+			want:     "(Host(`a.com`) || Host(`b.com`)) && PathPrefix(`/api`)",
+			matchers: []string{"Host", "Host", "PathPrefix"},
+		},
+		{
+			name:     "methods are upper-cased",
+			match:    RuleMatch{Method: []string{"get", "Post"}},
+			want:     "(Method(`GET`) || Method(`POST`))",
+			matchers: []string{"Method", "Method"},
+		},
+		{
+			name:     "pairs are sorted by key",
+			match:    RuleMatch{Header: map[string]string{"X-B": "2", "X-A": "1"}, Query: map[string]string{"v": "2"}},
+			want:     "Header(`X-A`, `1`) && Header(`X-B`, `2`) && Query(`v`, `2`)",
+			matchers: []string{"Header", "Header", "Query"},
+		},
+		{
+			name: "any and not",
+			match: RuleMatch{
+				Host: []string{"a.com"},
+				Any: []RuleMatch{
+					{PathPrefix: []string{"/api"}, Method: []string{"GET"}},
+					{ClientIP: []string{"10.0.0.0/8"}},
+				},
+				Not: &RuleMatch{HeaderRegexp: map[string]string{"User-Agent": "bot.*"}},
+			},
+			want:     "Host(`a.com`) && ((PathPrefix(`/api`) && Method(`GET`)) || ClientIP(`10.0.0.0/8`)) && !HeaderRegexp(`User-Agent`, `bot.*`)",
+			matchers: []string{"Host", "PathPrefix", "Method", "ClientIP", "HeaderRegexp"},
+		},
+		{
+			name:     "not with several conditions is parenthesized",
+			match:    RuleMatch{Not: &RuleMatch{Path: []string{"/health"}, Method: []string{"GET"}}},
+			want:     "!(Path(`/health`) && Method(`GET`))",
+			matchers: []string{"Path", "Method"},
+		},
+		{
+			name:     "not with alternatives keeps them grouped",
+			match:    RuleMatch{Not: &RuleMatch{PathRegexp: []string{"^/a", "^/b"}}},
+			want:     "!(PathRegexp(`^/a`) || PathRegexp(`^/b`))",
+			matchers: []string{"PathRegexp", "PathRegexp"},
+		},
+		{
+			name:  "empty",
+			match: RuleMatch{},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.match.Rule()
+			if got != tt.want {
+				t.Fatalf("Rule() = %q, want %q", got, tt.want)
+			}
+			if got == "" {
+				return
+			}
+
+			matchers, err := traefikrule.Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(Rule()) error = %v", err)
+			}
+			names := make([]string, len(matchers))
+			for i, m := range matchers {
+				names[i] = m.Name
+			}
+			if !reflect.DeepEqual(names, tt.matchers) {
+				t.Fatalf("Parse(Rule()) matchers = %v, want %v", names, tt.matchers)
+			}
+		})
+	}
+}
+
+func TestCheckRuleSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		match *RuleMatch
+		want  string // "" quando válido
+	}{
+		{name: "valid rule", rule: "Host(`a.com`) && PathPrefix(`/api`)"},
+		{name: "valid match", match: &RuleMatch{Host: []string{"a.com"}}},
+		{name: "invalid rule", rule: "Host(`a.com`", want: "invalid rule"},
+		{name: "both", rule: "Host(`a.com`)", match: &RuleMatch{Host: []string{"a.com"}}, want: "mutually exclusive"},
+		{name: "empty match", match: &RuleMatch{}, want: "match has no matchers"},
+		{name: "empty any block", match: &RuleMatch{Host: []string{"a.com"}, Any: []RuleMatch{{}}}, want: "match has no matchers"},
+		{name: "empty not block", match: &RuleMatch{Host: []string{"a.com"}, Not: &RuleMatch{}}, want: "match has no matchers"},
+		{name: "empty value", match: &RuleMatch{Host: []string{""}}, want: "cannot be empty or contain backticks"},
+		{name: "backtick in value", match: &RuleMatch{PathPrefix: []string{"/a`b"}}, want: "cannot be empty or contain backticks"},
+		{name: "invalid compiled value", match: &RuleMatch{PathPrefix: []string{"api"}}, want: "path must start with '/'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			checkRuleSource("api", tt.rule, tt.match, "services[0].traefik", func(path, msg, hint string) {
+				messages = append(messages, msg)
+			})
+
+			if tt.want == "" {
+				if len(messages) > 0 {
+					t.Fatalf("checkRuleSource reported %q, want no problems", messages)
+				}
+				return
+			}
+			if len(messages) != 1 || !strings.Contains(messages[0], tt.want) {
+				t.Fatalf("checkRuleSource reported %q, want one problem containing %q", messages, tt.want)
+			}
+		})
+	}
+}
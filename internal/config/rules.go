@@ -0,0 +1,626 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/leandrodaf/harborctl/internal/security"
+)
+
+// Categorias de regras
+const (
+	CategorySchema       = "schema"
+	CategorySecurity     = "security"
+	CategoryBestPractice = "best-practice"
+)
+
+// reportFunc registra um achado para o campo em path
+type reportFunc func(path, message, hint string)
+
+// Rule é uma regra de validação da stack
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Category    string
+	Description string
+	Check       func(stack *Stack, report reportFunc)
+}
+
+// DefaultRules retorna todas as regras aplicadas por harborctl validate
+func DefaultRules() []Rule {
+	var rules []Rule
+	rules = append(rules, schemaRules()...)
+	rules = append(rules, securityRules(NewSecureValidator())...)
+	rules = append(rules, bestPracticeRules()...)
+	return rules
+}
+
+// runRules executa as regras e monta o relatório
+func runRules(stack *Stack, rules []Rule) *Report {
+	report := &Report{}
+	for _, rule := range rules {
+		rule := rule
+		rule.Check(stack, func(path, message, hint string) {
+			report.Add(Finding{
+				Rule:     rule.ID,
+				Severity: rule.Severity,
+				Path:     path,
+				Position: stack.Position(path),
+				Message:  message,
+				Hint:     hint,
+			})
+		})
+	}
+	report.Sort()
+	return report
+}
+
+func sortedProfileNames(stack *Stack) []string {
+	names := make([]string, 0, len(stack.Profiles))
+	for name := range stack.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func servicePath(i int, field string) string {
+	if field == "" {
+		return fmt.Sprintf("services[%d]", i)
+	}
+	return fmt.Sprintf("services[%d].%s", i, field)
+}
+
+var (
+	memoryFormat = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?[bkmg]?$`)
+	cpuFormat    = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+)
+
+// schemaRules verificam estrutura e campos obrigatórios
+func schemaRules() []Rule {
+	return []Rule{
+		{
+			ID: "STK001", Severity: SeverityError, Category: CategorySchema,
+			Description: "version must be supported",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Version != CurrentVersion {
+					report("version", fmt.Sprintf("version must be %d", CurrentVersion), "run 'harborctl migrate' to upgrade the file")
+				}
+			},
+		},
+		{
+			ID: "STK002", Severity: SeverityError, Category: CategorySchema,
+			Description: "project is required",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Project == "" {
+					report("project", "project is required", "set 'project: <name>'")
+				}
+			},
+		},
+		{
+			ID: "STK003", Severity: SeverityError, Category: CategorySchema,
+			Description: "domain is required",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Domain == "" {
+					report("domain", "domain is required", "set 'domain: example.com' (or 'localhost' for local)")
+				}
+			},
+		},
+		{
+			ID: "TLS001", Severity: SeverityError, Category: CategorySchema,
+			Description: "tls.mode must be acme, selfsigned or disabled",
+			Check: func(stack *Stack, report reportFunc) {
+				switch stack.TLS.Mode {
+				case "acme", "selfsigned", "disabled":
+				default:
+					report("tls.mode", fmt.Sprintf("invalid tls.mode: %q", stack.TLS.Mode), "use one of: acme, selfsigned, disabled")
+				}
+			},
+		},
+		{
+			ID: "TLS002", Severity: SeverityError, Category: CategorySchema,
+			Description: "acme requires an email",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.TLS.Mode == "acme" && stack.TLS.Email == "" {
+					report("tls.email", "tls.email required with acme", "set 'tls.email' to the ACME account email")
+				}
+			},
+		},
+		{
+			ID: "NET001", Severity: SeverityError, Category: CategorySchema,
+			Description: "public and private networks are required",
+			Check: func(stack *Stack, report reportFunc) {
+				for _, name := range []string{"public", "private"} {
+					if _, ok := stack.Networks[name]; !ok {
+						report("networks", fmt.Sprintf("network '%s' is required", name),
+							fmt.Sprintf("add 'networks.%s: {internal: %t}'", name, name == "private"))
+					}
+				}
+			},
+		},
+		{
+			ID: "ENV001", Severity: SeverityWarning, Category: CategorySchema,
+			Description: "environment is a built-in preset or a declared profile",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Environment != "" && !stack.HasEnvironment(stack.Environment) {
+					report("environment", fmt.Sprintf("unknown environment %q, detected from the domain instead", stack.Environment),
+						fmt.Sprintf("use local or production, or declare 'profiles.%s'", strings.ToLower(stack.Environment)))
+				}
+			},
+		},
+		{
+			ID: "ENV002", Severity: SeverityError, Category: CategorySchema,
+			Description: "profiles extend a preset or another profile without cycles",
+			Check: func(stack *Stack, report reportFunc) {
+				for _, name := range sortedProfileNames(stack) {
+					parent := strings.ToLower(stack.Profiles[name].Extends)
+					if parent == "" || parent == name {
+						continue
+					}
+					if !stack.HasEnvironment(parent) {
+						report("profiles."+name+".extends", fmt.Sprintf("profile %q extends unknown environment %q", name, parent), "extend local, production or a declared profile")
+						continue
+					}
+
+					chain := []string{name}
+					seen := map[string]bool{name: true}
+					for current := parent; current != ""; {
+						chain = append(chain, current)
+						if seen[current] {
+							if current == name {
+								report("profiles."+name+".extends", "profile inheritance cycle: "+strings.Join(chain, " -> "), "make one of these profiles extend local or production")
+							}
+							break
+						}
+						seen[current] = true
+
+						next, ok := stack.Profiles[current]
+						if !ok || strings.ToLower(next.Extends) == current {
+							break
+						}
+						current = strings.ToLower(next.Extends)
+					}
+				}
+			},
+		},
+		{
+			ID: "ENV003", Severity: SeverityError, Category: CategorySchema,
+			Description: "profile settings are valid",
+			Check: func(stack *Stack, report reportFunc) {
+				for _, name := range sortedProfileNames(stack) {
+					profile := stack.Profiles[name]
+					path := "profiles." + name
+					switch profile.TLSMode {
+					case "", "acme", "selfsigned", "disabled":
+					default:
+						report(path+".tls_mode", fmt.Sprintf("invalid tls_mode: %q", profile.TLSMode), "use one of: acme, selfsigned, disabled")
+					}
+					if profile.Resources != nil {
+						if profile.Resources.Memory != "" && !memoryFormat.MatchString(strings.ToLower(profile.Resources.Memory)) {
+							report(path+".resources.memory", fmt.Sprintf("invalid memory format: %s", profile.Resources.Memory), "use a size like 512m or 1g")
+						}
+						if profile.Resources.CPUs != "" && !cpuFormat.MatchString(profile.Resources.CPUs) {
+							report(path+".resources.cpus", fmt.Sprintf("invalid cpus format: %s", profile.Resources.CPUs), "use a number like 0.5 or 2")
+						}
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC001", Severity: SeverityError, Category: CategorySchema,
+			Description: "service names are required and unique",
+			Check: func(stack *Stack, report reportFunc) {
+				seen := make(map[string]bool)
+				for i, sv := range stack.Services {
+					if sv.Name == "" {
+						report(servicePath(i, "name"), "service.name is required", "give the service a unique name")
+						continue
+					}
+					if seen[sv.Name] {
+						report(servicePath(i, "name"), fmt.Sprintf("duplicate service: %s", sv.Name), "rename one of the services")
+					}
+					seen[sv.Name] = true
+				}
+			},
+		},
+		{
+			ID: "SVC002", Severity: SeverityError, Category: CategorySchema,
+			Description: "service defines exactly one of image or build",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					if sv.Image == "" && sv.Build == nil {
+						report(servicePath(i, ""), fmt.Sprintf("%s: define image OR build", sv.Name), "add 'image: <ref>' or a 'build:' block")
+					}
+					if sv.Image != "" && sv.Build != nil {
+						report(servicePath(i, "build"), fmt.Sprintf("%s: use image OR build (not both)", sv.Name), "remove either 'image' or 'build'")
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC003", Severity: SeverityError, Category: CategorySchema,
+			Description: "service exposes a port (expose, routes, tcp or udp), except one-shot tasks",
+			Check: func(stack *Stack, report reportFunc) {
+				oneShot := stack.OneShotServices()
+				for i, sv := range stack.Services {
+					if sv.Expose <= 0 && len(sv.TCP)+len(sv.UDP) == 0 && len(sv.Routes) == 0 && !oneShot[sv.Name] {
+						report(servicePath(i, "expose"), fmt.Sprintf("%s: expose must be > 0", sv.Name), "set 'expose' to the port the container listens on")
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC004", Severity: SeverityError, Category: CategorySchema,
+			Description: "routed services need a subdomain",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					traefik := sv.GetTraefik()
+					if traefik != nil && traefik.Enabled && sv.Subdomain == "" && len(sv.Routes) == 0 && len(sv.Domains) == 0 {
+						report(servicePath(i, "traefik"), fmt.Sprintf("%s: subdomain is required when traefik is enabled", sv.Name), "add 'subdomain: <name>'")
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC005", Severity: SeverityError, Category: CategorySchema,
+			Description: "replicas cannot be negative",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					if sv.Replicas < 0 {
+						report(servicePath(i, "replicas"), fmt.Sprintf("%s: replicas cannot be negative", sv.Name), "use 0 or a positive number")
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC006", Severity: SeverityError, Category: CategorySchema,
+			Description: "volumes need source and target",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					for j, m := range sv.Volumes {
+						if m.Source == "" || m.Target == "" {
+							report(servicePath(i, fmt.Sprintf("volumes[%d]", j)), fmt.Sprintf("%s: invalid volume (source/target)", sv.Name), "set both 'source' and 'target'")
+						}
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC007", Severity: SeverityError, Category: CategorySchema,
+			Description: "secrets need a name and a file or external",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					for j, secret := range sv.Secrets {
+						path := servicePath(i, fmt.Sprintf("secrets[%d]", j))
+						if secret.Name == "" {
+							report(path, fmt.Sprintf("%s: secret.name is required", sv.Name), "add 'name: <secret>'")
+							continue
+						}
+						if !secret.External && secret.File == "" {
+							report(path, fmt.Sprintf("%s: secret '%s' needs 'file' or 'external=true'", sv.Name, secret.Name), "add 'file: ./path' or 'external: true'")
+						}
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC008", Severity: SeverityError, Category: CategorySchema,
+			Description: "enabled basic auth needs users",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					if sv.BasicAuth != nil && sv.BasicAuth.Enabled && len(sv.BasicAuth.Users) == 0 && sv.BasicAuth.UsersFile == "" &&
+						(sv.BasicAuth.Username == "" || sv.BasicAuth.Password == "") {
+						report(servicePath(i, "basic_auth"), fmt.Sprintf("%s: basic_auth enabled needs 'users' or 'users_file'", sv.Name), "generate a hash with 'harborctl hash-password'")
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC009", Severity: SeverityError, Category: CategorySchema,
+			Description: "resource limits use docker formats",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					if sv.Resources == nil {
+						continue
+					}
+					res := sv.Resources
+					if res.Memory != "" && !memoryFormat.MatchString(strings.ToLower(res.Memory)) {
+						report(servicePath(i, "resources.memory"), fmt.Sprintf("%s: invalid memory: %s", sv.Name, res.Memory), "use a number with unit b, k, m or g (ex: 512m)")
+					}
+					if res.CPUs != "" && !cpuFormat.MatchString(res.CPUs) {
+						report(servicePath(i, "resources.cpus"), fmt.Sprintf("%s: invalid cpus: %s", sv.Name, res.CPUs), "use a decimal number of CPUs (ex: 0.5)")
+					}
+					if res.GPUs != "" && res.GPUs != "all" && !cpuFormat.MatchString(res.GPUs) {
+						report(servicePath(i, "resources.gpus"), fmt.Sprintf("%s: invalid gpus: %s", sv.Name, res.GPUs), "use a number of GPUs or 'all'")
+					}
+				}
+			},
+		},
+		{
+			ID: "TRF001", Severity: SeverityError, Category: CategorySchema,
+			Description: "each traefik middleware defines exactly one type",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Traefik == nil {
+					return
+				}
+				for _, name := range sortedKeys(stack.Traefik.Middlewares) {
+					path := "traefik.middlewares." + name
+					if !traefikNameFormat.MatchString(name) || strings.Contains(name, "@") {
+						report(path, fmt.Sprintf("invalid middleware name %q", name), "use letters, digits, '-', '_' and '.'")
+					}
+					switch types := stack.Traefik.Middlewares[name].Types(); len(types) {
+					case 0:
+						report(path, fmt.Sprintf("middleware %s has no configuration", name), "add one middleware type (ex: headers, rateLimit, stripPrefix)")
+					case 1:
+					default:
+						report(path, fmt.Sprintf("middleware %s defines several types: %s", name, strings.Join(types, ", ")), "split it into one middleware per type and chain them on the router")
+					}
+				}
+			},
+		},
+		{
+			ID: "TRF002", Severity: SeverityError, Category: CategorySchema,
+			Description: "traefik backends have servers and a route",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Traefik == nil {
+					return
+				}
+				for _, name := range sortedKeys(stack.Traefik.Backends) {
+					backend := stack.Traefik.Backends[name]
+					path := "traefik.backends." + name
+					if len(backend.URLs) == 0 {
+						report(path+".urls", fmt.Sprintf("backend %s has no urls", name), "add 'urls: [http://host:port]'")
+					}
+					for j, raw := range backend.URLs {
+						if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "h2c") || u.Host == "" {
+							report(fmt.Sprintf("%s.urls[%d]", path, j), fmt.Sprintf("backend %s: invalid url %q", name, raw), "use an absolute http(s) URL (ex: http://10.0.0.5:8080)")
+						}
+					}
+					if backend.Subdomain == "" && backend.CustomRule() == "" {
+						report(path, fmt.Sprintf("backend %s needs a subdomain or a rule", name), "add 'subdomain: <name>'")
+					}
+					checkRuleSource("backend "+name, backend.Rule, backend.Match, path, report)
+				}
+			},
+		},
+		{
+			ID: "SVC010", Severity: SeverityError, Category: CategorySchema,
+			Description: "per-service traefik settings are valid",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					if sv.Traefik != nil {
+						checkServiceTraefik(stack, sv.Name, sv.Traefik, servicePath(i, "traefik"), report)
+					}
+				}
+			},
+		},
+		{
+			ID: "SVC011", Severity: SeverityError, Category: CategorySchema,
+			Description: "tcp and udp routes are valid",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					checkStreamRoutes(stack, sv, "tcp", sv.TCP, servicePath(i, "tcp"), report)
+					checkStreamRoutes(stack, sv, "udp", sv.UDP, servicePath(i, "udp"), report)
+				}
+			},
+		},
+		{
+			ID: "SVC012", Severity: SeverityError, Category: CategorySchema,
+			Description: "http routes are valid",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					checkRoutes(stack, i, sv, report)
+				}
+			},
+		},
+		{
+			ID: "SVC013", Severity: SeverityError, Category: CategorySchema,
+			Description: "deploy strategies and canary steps are valid",
+			Check:       checkDeploy,
+		},
+		{
+			ID: "SVC014", Severity: SeverityError, Category: CategorySchema,
+			Description: "mirror shadows are valid",
+			Check:       checkMirror,
+		},
+		{
+			ID: "SVC015", Severity: SeverityError, Category: CategorySchema,
+			Description: "health check types and options are valid",
+			Check:       checkHealthChecks,
+		},
+		{
+			ID: "SVC016", Severity: SeverityError, Category: CategorySchema,
+			Description: "depends_on refers to known services, without cycles",
+			Check:       checkDependencies,
+		},
+		{
+			ID: "TLS003", Severity: SeverityError, Category: CategorySchema,
+			Description: "service domains are valid and can get a certificate",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, sv := range stack.Services {
+					checkServiceDomains(stack, i, sv, report)
+				}
+			},
+		},
+		{
+			ID: "TLS004", Severity: SeverityError, Category: CategorySchema,
+			Description: "acme resolver settings are consistent",
+			Check:       checkACME,
+		},
+		{
+			ID: "TLS005", Severity: SeverityError, Category: CategorySchema,
+			Description: "tls option profiles and mtls are valid",
+			Check:       checkTLSOptions,
+		},
+		{
+			ID: "TRF003", Severity: SeverityError, Category: CategorySchema,
+			Description: "http routes do not conflict",
+			Check:       checkRouteConflicts,
+		},
+		{
+			ID: "NET002", Severity: SeverityError, Category: CategorySchema,
+			Description: "tcp and udp ports do not collide",
+			Check:       checkStreamPorts,
+		},
+	}
+}
+
+// securityRules verificam entradas potencialmente perigosas
+func securityRules(sv *SecureValidator) []Rule {
+	return []Rule{
+		{
+			ID: "SEC001", Severity: SeverityError, Category: CategorySecurity,
+			Description: "domain is a valid hostname",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.Domain == "" {
+					return
+				}
+				if err := security.ValidateDomainName(stack.Domain); err != nil {
+					report("domain", fmt.Sprintf("invalid domain: %v", err), "use only letters, digits, '-' and '.'")
+				}
+			},
+		},
+		{
+			ID: "SEC002", Severity: SeverityError, Category: CategorySecurity,
+			Description: "ACME email is well formed",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.TLS.Mode != "acme" || stack.TLS.Email == "" {
+					return
+				}
+				if err := security.ValidateEmail(stack.TLS.Email); err != nil {
+					report("tls.email", fmt.Sprintf("invalid email: %v", err), "use a reachable address, Let's Encrypt sends expiry notices to it")
+				}
+			},
+		},
+		{
+			ID: "SEC003", Severity: SeverityError, Category: CategorySecurity,
+			Description: "service names and subdomains contain safe characters",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, service := range stack.Services {
+					if service.Name != "" {
+						if clean, err := sv.inputSanitizer.SanitizeString(service.Name); err != nil || clean != service.Name {
+							report(servicePath(i, "name"), fmt.Sprintf("service name contains invalid characters: %q", service.Name), "remove spaces and control characters")
+						}
+					}
+					if service.Subdomain != "" {
+						if err := security.ValidateDomainName(service.Subdomain); err != nil {
+							report(servicePath(i, "subdomain"), fmt.Sprintf("invalid subdomain: %v", err), "use only letters, digits and '-'")
+						}
+					}
+				}
+			},
+		},
+		{
+			ID: "SEC004", Severity: SeverityError, Category: CategorySecurity,
+			Description: "build context, dockerfile and args are safe",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, service := range stack.Services {
+					if service.Build == nil {
+						continue
+					}
+					if err := sv.ValidateBuildSpec(*service.Build); err != nil {
+						report(servicePath(i, "build"), fmt.Sprintf("invalid build spec: %v", err), "keep the build context inside the repository")
+					}
+				}
+			},
+		},
+		{
+			ID: "SEC005", Severity: SeverityError, Category: CategorySecurity,
+			Description: "volume mounts avoid sensitive host paths",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, service := range stack.Services {
+					for j, volume := range service.Volumes {
+						if volume.Source == "" || volume.Target == "" {
+							continue // SVC006
+						}
+						if err := sv.ValidateVolumeMount(volume); err != nil {
+							report(servicePath(i, fmt.Sprintf("volumes[%d]", j)), fmt.Sprintf("invalid volume: %v", err), "mount a named volume or a project directory instead")
+						}
+					}
+				}
+			},
+		},
+		{
+			ID: "SEC006", Severity: SeverityError, Category: CategorySecurity,
+			Description: "env files and secrets use safe paths",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, service := range stack.Services {
+					for j, envFile := range service.EnvFile {
+						if err := sv.pathValidator.ValidatePath(envFile); err != nil {
+							report(servicePath(i, fmt.Sprintf("env_file[%d]", j)), fmt.Sprintf("invalid env file: %v", err), "use a relative path inside the project")
+						}
+					}
+					for j, secret := range service.Secrets {
+						if secret.Name == "" {
+							continue // SVC007
+						}
+						if err := sv.ValidateSecret(secret); err != nil {
+							report(servicePath(i, fmt.Sprintf("secrets[%d]", j)), fmt.Sprintf("invalid secret: %v", err), "use a relative path inside the project")
+						}
+					}
+				}
+			},
+		},
+	}
+}
+
+// bestPracticeRules apontam configurações que funcionam mas merecem atenção
+func bestPracticeRules() []Rule {
+	return []Rule{
+		{
+			ID: "BP001", Severity: SeverityWarning, Category: CategoryBestPractice,
+			Description: "images are pinned to a tag",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, service := range stack.Services {
+					if service.Image == "" {
+						continue
+					}
+					ref := service.Image
+					if idx := strings.LastIndex(ref, "/"); idx >= 0 {
+						ref = ref[idx+1:]
+					}
+					if !strings.Contains(ref, ":") && !strings.Contains(ref, "@") || strings.HasSuffix(ref, ":latest") {
+						report(servicePath(i, "image"), fmt.Sprintf("%s: image %q is not pinned to a version", service.Name, service.Image), "use an explicit tag or digest (ex: nginx:1.27)")
+					}
+				}
+			},
+		},
+		{
+			ID: "BP002", Severity: SeverityWarning, Category: CategoryBestPractice,
+			Description: "TLS is enabled outside local environments",
+			Check: func(stack *Stack, report reportFunc) {
+				if stack.TLS.Mode == "disabled" && stack.Environment == "production" {
+					report("tls.mode", "TLS is disabled in a production stack", "use 'tls.mode: acme'")
+				}
+			},
+		},
+		{
+			ID: "BP003", Severity: SeverityInfo, Category: CategoryBestPractice,
+			Description: "services declare resource limits",
+			Check: func(stack *Stack, report reportFunc) {
+				for i, service := range stack.Services {
+					if service.Resources == nil || (service.Resources.Memory == "" && service.Resources.CPUs == "") {
+						report(servicePath(i, ""), fmt.Sprintf("%s: no resource limits defined", service.Name), "add 'resources.memory' and 'resources.cpus'")
+					}
+				}
+			},
+		},
+		{
+			ID: "BP004", Severity: SeverityWarning, Category: CategoryBestPractice,
+			Description: "healthy dependencies have a health check",
+			Check:       checkHealthyDependencies,
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// rulesFixture não gera achados de erro ou aviso; cada caso muda uma linha
+const rulesFixture = `version: 1
+project: demo
+domain: example.com
+environment: production
+tls:
+  mode: acme
+  email: ops@example.com
+networks:
+  public: {}
+  private: {internal: true}
+services:
+  - name: api
+    image: api:1.0
+    expose: 8080
+    subdomain: api
+    resources: {memory: 256m, cpus: "0.5"}
+`
+
+func checkFixture(t *testing.T, data string) *Report {
+	t.Helper()
+	stack, err := loadFiles(t, "", [2]string{"stack.yml", data})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return NewValidator().Check(context.Background(), stack)
+}
+
+func TestRulesFixtureIsClean(t *testing.T) {
+	report := checkFixture(t, rulesFixture)
+	for _, f := range report.Findings {
+		if f.Severity != SeverityInfo {
+			t.Errorf("unexpected finding %s %s: %s", f.Rule, f.Path, f.Message)
+		}
+	}
+}
+
+func TestRuleFamilies(t *testing.T) {
+	tests := []struct {
+		rule string
+		from string
+		to   string
+		path string
+		line int
+	}{
+		{"STK002", "project: demo", `project: ""`, "project", 2},
+		{"TLS001", "mode: acme", "mode: tls", "tls.mode", 6},
+		{"NET001", "  private: {internal: true}\n", "", "networks", 8},
+		{"ENV001", "environment: production", "environment: staging", "environment", 4},
+		{"SVC005", "    expose: 8080\n", "    expose: 8080\n    replicas: -1\n", "services[0].replicas", 15},
+		{"TRF001", "", "traefik:\n  middlewares:\n    empty: {}\n", "traefik.middlewares.empty", 19},
+		{"SEC003", "subdomain: api", `subdomain: "api_x!"`, "services[0].subdomain", 15},
+		{"BP001", "image: api:1.0", "image: api:latest", "services[0].image", 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			data := rulesFixture + tt.to
+			if tt.from != "" {
+				if !strings.Contains(rulesFixture, tt.from) {
+					t.Fatalf("fixture has no %q", tt.from)
+				}
+				data = strings.Replace(rulesFixture, tt.from, tt.to, 1)
+			}
+
+			report := checkFixture(t, data)
+			var found []string
+			for _, f := range report.Findings {
+				if f.Rule != tt.rule {
+					continue
+				}
+				if f.Path == tt.path && f.Position.Line == tt.line && strings.HasSuffix(f.Position.File, "stack.yml") {
+					return
+				}
+				found = append(found, f.Path+" at "+f.Position.String())
+			}
+			t.Fatalf("no %s finding for %s at line %d (found %v)", tt.rule, tt.path, tt.line, found)
+		})
+	}
+}
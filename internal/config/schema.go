@@ -0,0 +1,439 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaID identifica o JSON Schema publicado para stack.yml
+const SchemaID = "https://github.com/leandrodaf/harborctl/schema/stack.schema.json"
+
+// schemaDescriptions documenta campos no formato "Tipo.Campo" (nome do campo Go)
+var schemaDescriptions = map[string]string{
+	"Stack.Version":       "Schema version of this file. Older versions are migrated with 'harborctl migrate'.",
+	"Stack.Project":       "Project name, used as the docker compose project and network prefix.",
+	"Stack.Domain":        "Base domain. Services are routed at <subdomain>.<domain>.",
+	"Stack.Environment":   "Deployment environment: local, production or a name declared in profiles.",
+	"Stack.Profiles":      "Named environment profiles (staging, qa, preview...).",
+	"Stack.TLS":           "Certificate configuration for routed services.",
+	"Stack.Traefik":       "Overrides for the generated Traefik reverse proxy.",
+	"Stack.Observability": "Built-in log viewer (Dozzle) and monitoring (Beszel).",
+	"Stack.Networks":      "Docker networks. 'public' and 'private' are required.",
+	"Stack.Volumes":       "Named volumes created for the stack.",
+	"Stack.Services":      "Application services.",
+
+	"Profile.Extends":     "Preset or profile this one builds on (default: production).",
+	"Profile.TLSMode":     "Overrides tls.mode for this environment.",
+	"Profile.Hardening":   "Apply container hardening (no-new-privileges, cap_drop, non-root user).",
+	"Profile.Middlewares": "Default middlewares for routed services.",
+	"Profile.Dashboard":   "Expose the Traefik dashboard on port 8080.",
+	"Profile.Resources":   "Resource limits for services that declare none.",
+
+	"TLS.Mode":     "How certificates are obtained.",
+	"TLS.Email":    "ACME account email (required with mode acme).",
+	"TLS.Resolver": "Name of the Traefik certificate resolver.",
+	"TLS.Staging":  "Use the Let's Encrypt staging CA (untrusted certificates, high rate limits) while testing.",
+	"TLS.CAServer": "ACME directory URL of another CA (ex: ZeroSSL, an internal step-ca).",
+	"TLS.EAB":      "External Account Binding credentials required by the CA in caServer.",
+	"TLS.Domains":  "Certificates requested up front on the websecure entrypoint, e.g. a wildcard (needs the DNS challenge).",
+	"TLS.DNS":      "Use the ACME DNS-01 challenge instead of HTTP-01.",
+	"TLS.Options":  "Named Traefik TLS option profiles. 'default' applies to every router without another profile.",
+
+	"TLSOptions.MinVersion":       "Lowest accepted TLS version.",
+	"TLSOptions.MaxVersion":       "Highest accepted TLS version.",
+	"TLSOptions.CipherSuites":     "Allowed TLS 1.2 cipher suites (Go names, ex: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256).",
+	"TLSOptions.CurvePreferences": "Elliptic curves in order of preference (ex: X25519, CurveP256).",
+	"TLSOptions.SNIStrict":        "Reject connections whose SNI matches no certificate.",
+	"TLSOptions.ClientAuth":       "Require client certificates (mutual TLS).",
+
+	"TLSClientAuth.CAFiles":        "PEM CA bundles that sign accepted client certificates (default: the harborctl internal CA).",
+	"TLSClientAuth.ClientAuthType": "How client certificates are checked.",
+
+	"ACMEEAB.KID":  "Key identifier issued by the CA.",
+	"ACMEEAB.HMAC": "Base64 HMAC key issued by the CA. Prefer a ${VARIABLE} reference.",
+
+	"DNSChallenge.Provider":                "Traefik/lego DNS provider name (ex: cloudflare).",
+	"DNSChallenge.Env":                     "Provider settings as KEY=VALUE. Use credentials for secrets.",
+	"DNSChallenge.Credentials":             "Provider secrets by variable name, read from a file or a Docker secret (passed as <VARIABLE>_FILE).",
+	"DNSChallenge.Resolvers":               "DNS servers (host:port) used to check propagation.",
+	"DNSChallenge.PropagationDelay":        "Wait before checking propagation (ex: 30s).",
+	"DNSChallenge.DisablePropagationCheck": "Skip the propagation check, e.g. behind split-horizon DNS.",
+
+	"DNSCredential.File":   "File on the host holding the value, mounted as a Docker secret.",
+	"DNSCredential.Secret": "Existing external Docker secret holding the value.",
+
+	"Service.Name":          "Unique service name, also used as container name.",
+	"Service.Subdomain":     "Subdomain routed by Traefik to this service.",
+	"Service.Image":         "Image reference. Mutually exclusive with build.",
+	"Service.Build":         "Build the image from source. Mutually exclusive with image.",
+	"Service.Expose":        "Port the container listens on.",
+	"Service.TCP":           "TCP ports routed through a Traefik entrypoint.",
+	"Service.UDP":           "UDP ports routed through a Traefik entrypoint.",
+	"Service.Domains":       "Custom domains outside the stack domain, each with its own certificate.",
+	"Service.MTLS":          "Require client certificates on the service's HTTP routers (mutual TLS).",
+	"Service.DependsOn":     "Services started first: a name, or {service, condition} to wait until it is healthy or has completed.",
+	"Service.Mirror":        "Shadow version that receives a copy of part of the requests (responses discarded).",
+	"Service.Routes":        "HTTP routes, each compiled into its own Traefik router and service (default: Host(subdomain.domain)).",
+	"Service.Replicas":      "Number of containers to run.",
+	"Service.Env":           "Environment variables.",
+	"Service.EnvFile":       "Files with environment variables.",
+	"Service.Secrets":       "Docker secrets mounted in the container.",
+	"Service.Volumes":       "Volume mounts.",
+	"Service.Resources":     "CPU, memory and GPU limits.",
+	"Service.HealthCheck":   "Container health check.",
+	"Service.Deploy":        "Deployment strategy.",
+	"Service.Traefik":       "Routing through Traefik: true/false or a detailed configuration.",
+	"Service.BasicAuth":     "Protect the route with HTTP basic auth.",
+	"Service.NetworkAccess": "Which networks the service joins.",
+
+	"Dependency.Service":   "Service of this stack that must start first.",
+	"Dependency.Condition": "What to wait for: started (default), healthy or completed_successfully.",
+
+	"ServiceTraefik.Enabled":      "Route this service through Traefik.",
+	"ServiceTraefik.Rule":         "Custom Traefik rule, replaces the default Host() rule.",
+	"ServiceTraefik.Match":        "Structured rule compiled to a Traefik rule. Mutually exclusive with rule.",
+	"ServiceTraefik.EntryPoints":  "Entry points for the router.",
+	"ServiceTraefik.Middlewares":  "Middlewares applied to the router.",
+	"ServiceTraefik.Priority":     "Router priority.",
+	"ServiceTraefik.TLS":          "Router TLS settings.",
+	"ServiceTraefik.LoadBalancer": "Load balancer settings.",
+	"ServiceTraefik.Labels":       "Extra raw Traefik labels.",
+
+	"RuleMatch.Host":         "Hostnames; any of them matches.",
+	"RuleMatch.HostRegexp":   "Hostname regular expressions; any of them matches.",
+	"RuleMatch.Path":         "Exact paths; any of them matches.",
+	"RuleMatch.PathPrefix":   "Path prefixes; any of them matches.",
+	"RuleMatch.PathRegexp":   "Path regular expressions; any of them matches.",
+	"RuleMatch.Method":       "HTTP methods; any of them matches.",
+	"RuleMatch.Header":       "Headers that must all match exactly.",
+	"RuleMatch.HeaderRegexp": "Headers that must all match a regular expression.",
+	"RuleMatch.Query":        "Query parameters that must all match exactly.",
+	"RuleMatch.ClientIP":     "Client IPs or CIDR ranges; any of them matches.",
+	"RuleMatch.Any":          "Blocks where at least one must match.",
+	"RuleMatch.Not":          "Block that must not match.",
+
+	"ServiceMTLS.CAFiles":  "PEM CA bundles that sign accepted client certificates (default: the harborctl internal CA, see 'harborctl certs issue-client').",
+	"ServiceMTLS.Optional": "Also accept clients without a certificate; presented certificates are still verified.",
+	"ServiceMTLS.Options":  "tls.options profile the client check is added to (default: default).",
+
+	"ServiceDomain.Host":         "Hostname served by the service (ex: shop.example.org, example.org, *.example.org).",
+	"ServiceDomain.RedirectFrom": "Hostnames permanently redirected to host (ex: www.example.org).",
+	"ServiceDomain.CertResolver": "Cert resolver for this domain (default: tls.resolver). Wildcards need a DNS challenge resolver.",
+	"ServiceDomain.CertFile":     "PEM certificate file to serve instead of ACME.",
+	"ServiceDomain.KeyFile":      "PEM private key for cert_file.",
+
+	"Route.Name":        "Route name, appended to the service name for the router (default: its position).",
+	"Route.Hosts":       "Full hostnames matched, including custom apex domains (default: subdomain.domain).",
+	"Route.Paths":       "Path prefixes matched.",
+	"Route.StripPrefix": "Remove the matched prefix before forwarding.",
+	"Route.Methods":     "HTTP methods matched.",
+	"Route.Headers":     "Request headers that must match exactly.",
+	"Route.Port":        "Container port for this route (default: expose).",
+	"Route.EntryPoints": "Entry points for the router.",
+	"Route.Middlewares": "Middlewares applied after the service middlewares.",
+	"Route.Priority":    "Router priority.",
+
+	"StreamRoute.Port":       "Port the container listens on.",
+	"StreamRoute.Listen":     "Port published by Traefik (default: port).",
+	"StreamRoute.EntryPoint": "Existing entrypoint to use instead of creating one from listen.",
+	"StreamRoute.SNI":        "TCP only: hosts matched with HostSNI (requires tls).",
+	"StreamRoute.TLS":        "TCP only: none, passthrough (service terminates TLS) or terminate (Traefik terminates TLS).",
+
+	"TraefikConfig.Middlewares": "Middlewares written to the generated dynamic configuration (reference them by name).",
+	"TraefikConfig.Backends":    "Routes to servers outside Docker, written to the dynamic configuration.",
+
+	"TraefikBackend.URLs":           "Server URLs the load balancer sends traffic to (ex: http://10.0.0.5:8080).",
+	"TraefikBackend.Subdomain":      "Subdomain routed to the backend.",
+	"TraefikBackend.Rule":           "Custom Traefik rule, replaces the default Host() rule.",
+	"TraefikBackend.Match":          "Structured rule compiled to a Traefik rule. Mutually exclusive with rule.",
+	"TraefikBackend.Middlewares":    "Middlewares applied to the router.",
+	"TraefikBackend.PassHostHeader": "Forward the client Host header (default true).",
+
+	"Resources.Memory":     "Memory limit (ex: 512m, 1g).",
+	"Resources.CPUs":       "CPU limit (ex: 0.5, 2).",
+	"Resources.GPUs":       "Number of GPUs or 'all'.",
+	"Resources.ShmSize":    "Size of /dev/shm (ex: 128m).",
+	"Resources.ReserveCPU": "Reserved CPUs.",
+	"Resources.ReserveMem": "Reserved memory.",
+
+	"HealthCheck.Enabled":     "Enable the container health check.",
+	"HealthCheck.Type":        "Probe type: http (default), tcp, exec, grpc or none (disables the image health check).",
+	"HealthCheck.Tool":        "Program that runs the probe: curl (http default), wget, nc (tcp default) or probe (harborctl mounted into the container).",
+	"HealthCheck.Path":        "HTTP path probed (default /health).",
+	"HealthCheck.Port":        "Port probed (default: expose).",
+	"HealthCheck.Method":      "HTTP method (default GET).",
+	"HealthCheck.Status":      "Expected HTTP status (default: any 2xx or 3xx).",
+	"HealthCheck.Headers":     "HTTP headers sent with the probe.",
+	"HealthCheck.Command":     "exec: command run in the container without a shell.",
+	"HealthCheck.Service":     "grpc: service name checked (default: the whole server).",
+	"HealthCheck.Interval":    "Time between probes (ex: 30s).",
+	"HealthCheck.Timeout":     "Probe timeout (ex: 10s).",
+	"HealthCheck.Retries":     "Consecutive failures before unhealthy.",
+	"HealthCheck.StartPeriod": "Grace period before failures count (default 60s).",
+
+	"ServiceMirror.Image":       "Image of the shadow version that receives copies of the requests.",
+	"ServiceMirror.Percent":     "Percentage of requests copied to the shadow (default 10).",
+	"ServiceMirror.Env":         "Environment overrides for the shadow (ex: a test database).",
+	"ServiceMirror.MaxBodySize": "Largest request body copied, in bytes (default: no limit).",
+
+	"DeployConfig.Strategy":      "How new versions replace running containers: blue-green and canary shift Traefik traffic between two colors.",
+	"DeployConfig.HealthTimeout": "Maximum wait for the new version to become healthy (default 5m).",
+	"DeployConfig.Drain":         "Wait after switching traffic before stopping the old version (default 10s).",
+	"DeployConfig.Steps":         "Canary steps (default 10% then 50%, 1m each).",
+	"DeployConfig.Parallelism":   "Containers replaced at a time by rolling updates (default 1).",
+	"DeployConfig.Delay":         "Wait between rolling update batches (default 10s).",
+	"CanaryStep.Weight":          "Percentage of traffic sent to the new version (1-99).",
+	"CanaryStep.Duration":        "Observation time before the next step (ex: 2m).",
+
+	"NetworkAccess.Internet": "Join the public network (outbound internet access).",
+	"NetworkAccess.Internal": "Force private-only networking.",
+	"NetworkAccess.Custom":   "Additional networks to join.",
+
+	"BasicAuth.Enabled":   "Enable basic auth.",
+	"BasicAuth.Username":  "Single user name.",
+	"BasicAuth.Password":  "bcrypt hash (see 'harborctl hash-password').",
+	"BasicAuth.Users":     "user -> bcrypt hash.",
+	"BasicAuth.UsersFile": "htpasswd file.",
+
+	"Dozzle.Enabled":   "Deploy the Dozzle log viewer.",
+	"Dozzle.Subdomain": "Subdomain for Dozzle.",
+	"Beszel.Enabled":   "Deploy Beszel monitoring (hub and agent).",
+	"Beszel.Subdomain": "Subdomain for the Beszel hub.",
+	"Beszel.PublicKey": "Hub SSH public key used by the agent.",
+	"Beszel.Token":     "Agent authentication token.",
+}
+
+// schemaEnums restringe campos a um conjunto de valores
+var schemaEnums = map[string][]interface{}{
+	"TLS.Mode":                     {"acme", "selfsigned", "disabled"},
+	"TLSOptions.MinVersion":        {"VersionTLS10", "VersionTLS11", "VersionTLS12", "VersionTLS13"},
+	"TLSOptions.MaxVersion":        {"VersionTLS10", "VersionTLS11", "VersionTLS12", "VersionTLS13"},
+	"TLSClientAuth.ClientAuthType": {"NoClientCert", "RequestClientCert", "RequireAnyClientCert", "VerifyClientCertIfGiven", "RequireAndVerifyClientCert"},
+	"Profile.TLSMode":              {"acme", "selfsigned", "disabled"},
+	"DeployConfig.Strategy":        {"rolling", "recreate", "blue-green", "canary"},
+	"Dependency.Condition":         {"started", "healthy", "completed_successfully"},
+	"HealthCheck.Type":             {"http", "tcp", "exec", "grpc", "none"},
+	"HealthCheck.Tool":             {"curl", "wget", "nc", "probe"},
+	"TraefikLog.Level":             {"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "PANIC"},
+	"TraefikLog.Format":            {"common", "json"},
+	"TraefikAccessLog.Format": {
+		"common", "json",
+	},
+	"StickyCookie.SameSite": {"none", "lax", "strict"},
+	"StreamRoute.TLS":       {"none", "passthrough", "terminate"},
+}
+
+// schemaRequired lista campos obrigatórios por tipo. version fica de fora: ausente,
+// o arquivo é lido como versão 1
+var schemaRequired = map[string][]string{
+	"Stack":          {"Project", "Domain"},
+	"Service":        {"Name"},
+	"Volume":         {"Name"},
+	"Secret":         {"Name"},
+	"BuildSpec":      {"Context"},
+	"TraefikBackend": {"URLs"},
+	"ServiceDomain":  {"Host"},
+	"CanaryStep":     {"Weight", "Duration"},
+	"ServiceMirror":  {"Image"},
+	"Dependency":     {"Service"},
+}
+
+// schemaGenerator gera definições JSON Schema a partir dos tipos Go
+type schemaGenerator struct {
+	defs map[string]interface{}
+	used map[string]bool // anotações efetivamente usadas
+}
+
+// JSONSchema gera o JSON Schema de stack.yml a partir da árvore de tipos de Stack.
+// Vale para o arquivo base: overlays (stack.<env>.yml) e arquivos de include são
+// parciais e não trazem os campos obrigatórios.
+// Falha se alguma anotação (descrição, enum, obrigatório) referir um campo inexistente,
+// garantindo que as anotações acompanhem as structs.
+func JSONSchema() (map[string]interface{}, error) {
+	g := &schemaGenerator{
+		defs: make(map[string]interface{}),
+		used: make(map[string]bool),
+	}
+
+	root := g.typeSchema(reflect.TypeOf(Stack{}))
+
+	if stale := g.staleAnnotations(); len(stale) > 0 {
+		return nil, fmt.Errorf("schema annotations refer to unknown fields: %s", strings.Join(stale, ", "))
+	}
+
+	// include: é resolvido antes da decodificação e não existe em Stack
+	if stack, ok := g.defs["Stack"].(map[string]interface{}); ok {
+		stack["properties"].(map[string]interface{})[includeKey] = map[string]interface{}{
+			"description": "Files merged under this one (maps deep-merged, lists merged by name).",
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+		}
+	}
+
+	schema := map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         SchemaID,
+		"title":       "harborctl stack.yml",
+		"description": "Stack definition consumed by harborctl. Applies to the base file; overlays and included files are partial.",
+		"$ref":        root["$ref"],
+		"$defs":       g.defs,
+	}
+	return schema, nil
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // evita recursão infinita
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	additional := false
+	var required []string
+
+	requiredFields := make(map[string]bool)
+	for _, f := range schemaRequired[t.Name()] {
+		requiredFields[f] = true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		if len(parts) > 1 && parts[1] == "inline" {
+			// Mapa inline aceita chaves livres (ex: middlewares customizados)
+			additional = true
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		key := t.Name() + "." + f.Name
+		prop := g.fieldSchema(t, f)
+
+		if desc, ok := schemaDescriptions[key]; ok {
+			prop["description"] = desc
+			g.used["description:"+key] = true
+		}
+		if enum, ok := schemaEnums[key]; ok {
+			prop["enum"] = enum
+			g.used["enum:"+key] = true
+		}
+
+		properties[name] = prop
+		if requiredFields[f.Name] {
+			required = append(required, name)
+			g.used["required:"+key] = true
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": additional,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldSchema trata campos polimórficos antes de cair no mapeamento por tipo
+func (g *schemaGenerator) fieldSchema(parent reflect.Type, f reflect.StructField) map[string]interface{} {
+	if parent == reflect.TypeOf(Stack{}) && f.Name == "Version" {
+		versions := make([]interface{}, 0, CurrentVersion)
+		for v := 1; v <= CurrentVersion; v++ {
+			versions = append(versions, v)
+		}
+		return map[string]interface{}{"type": "integer", "enum": versions}
+	}
+
+	if parent == reflect.TypeOf(Service{}) && f.Name == "Traefik" {
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "boolean"},
+				g.typeSchema(reflect.TypeOf(ServiceTraefik{})),
+			},
+		}
+	}
+
+	if parent == reflect.TypeOf(Service{}) && f.Name == "DependsOn" {
+		return map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					g.typeSchema(reflect.TypeOf(Dependency{})),
+				},
+			},
+		}
+	}
+
+	return g.typeSchema(f.Type)
+}
+
+// staleAnnotations lista anotações que não correspondem a nenhum campo
+func (g *schemaGenerator) staleAnnotations() []string {
+	var stale []string
+	for key := range schemaDescriptions {
+		if !g.used["description:"+key] {
+			stale = append(stale, "description "+key)
+		}
+	}
+	for key := range schemaEnums {
+		if !g.used["enum:"+key] {
+			stale = append(stale, "enum "+key)
+		}
+	}
+	for typ, fields := range schemaRequired {
+		for _, f := range fields {
+			if !g.used["required:"+typ+"."+f] {
+				stale = append(stale, "required "+typ+"."+f)
+			}
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONSchemaAnnotationsMatchFields(t *testing.T) {
+	if _, err := JSONSchema(); err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+}
+
+func TestJSONSchemaReportsStaleAnnotations(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject func() func()
+		want   string
+	}{
+		{
+			name: "description",
+			inject: func() func() {
+				schemaDescriptions["Stack.Bogus"] = "not a field"
+				return func() { delete(schemaDescriptions, "Stack.Bogus") }
+			},
+			want: "description Stack.Bogus",
+		},
+		{
+			name: "enum",
+			inject: func() func() {
+				schemaEnums["Service.Bogus"] = []interface{}{"a"}
+				return func() { delete(schemaEnums, "Service.Bogus") }
+			},
+			want: "enum Service.Bogus",
+		},
+		{
+			name: "required on known type",
+			inject: func() func() {
+				saved := schemaRequired["Stack"]
+				schemaRequired["Stack"] = append(append([]string(nil), saved...), "Bogus")
+				return func() { schemaRequired["Stack"] = saved }
+			},
+			want: "required Stack.Bogus",
+		},
+		{
+			name: "required on unknown type",
+			inject: func() func() {
+				schemaRequired["Bogus"] = []string{"Name"}
+				return func() { delete(schemaRequired, "Bogus") }
+			},
+			want: "required Bogus.Name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(tt.inject())
+
+			_, err := JSONSchema()
+			if err == nil {
+				t.Fatal("JSONSchema() error = nil, want stale annotation error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("JSONSchema() error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaStackRequired(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	stack := schema["$defs"].(map[string]interface{})["Stack"].(map[string]interface{})
+	required := stack["required"].([]string)
+	for _, name := range required {
+		if name == "version" {
+			t.Fatalf("Stack required = %v, version is optional (defaults to 1)", required)
+		}
+	}
+}
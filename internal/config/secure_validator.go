@@ -21,28 +21,9 @@ func NewSecureValidator() *SecureValidator {
 	}
 }
 
-// ValidateStack valida uma stack com verificações de segurança
+// ValidateStack valida uma stack com as regras de segurança (mesmas usadas por harborctl validate)
 func (sv *SecureValidator) ValidateStack(stack *Stack) error {
-	// Valida domínio
-	if err := security.ValidateDomainName(stack.Domain); err != nil {
-		return fmt.Errorf("domínio inválido: %w", err)
-	}
-
-	// Valida email apenas se TLS estiver habilitado e for ACME
-	if stack.TLS.Mode == "acme" {
-		if err := security.ValidateEmail(stack.TLS.Email); err != nil {
-			return fmt.Errorf("email inválido: %w", err)
-		}
-	}
-
-	// Valida serviços
-	for i, service := range stack.Services {
-		if err := sv.ValidateService(service); err != nil {
-			return fmt.Errorf("serviço %d (%s) inválido: %w", i, service.Name, err)
-		}
-	}
-
-	return nil
+	return runRules(stack, securityRules(sv)).Err()
 }
 
 // ValidateService valida um serviço
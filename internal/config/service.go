@@ -1,9 +1,12 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"reflect"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,9 +17,13 @@ import (
 // Manager gerencia configurações
 type Manager interface {
 	Load(ctx context.Context, path string) (*Stack, error)
+	LoadEnv(ctx context.Context, path, env string) (*Stack, error)
+	Resolve(ctx context.Context, path, env string) ([]byte, error)
 	Validate(ctx context.Context, stack *Stack) error
+	Check(ctx context.Context, stack *Stack) *Report
 	Create(ctx context.Context, path string, options CreateOptions) error
 	SaveBaseConfig(ctx context.Context, path string, stack *Stack) error
+	Edit(ctx context.Context, path string, edit func(doc *Document) error) error
 }
 
 // CreateOptions configura a criação de stack
@@ -43,6 +50,7 @@ type manager struct {
 	loader    fs.ConfigLoader
 	fs        fs.FileSystem
 	validator Validator
+	lookupEnv LookupEnvFunc
 }
 
 // NewManager cria um novo gerenciador de configuração
@@ -51,27 +59,121 @@ func NewManager(loader fs.ConfigLoader, filesystem fs.FileSystem, validator Vali
 		loader:    loader,
 		fs:        filesystem,
 		validator: validator,
+		lookupEnv: os.LookupEnv,
 	}
 }
 
 func (m *manager) Load(ctx context.Context, path string) (*Stack, error) {
-	data, err := m.loader.Load(ctx, path)
+	return m.LoadEnv(ctx, path, "")
+}
+
+// LoadEnv carrega path com o overlay do ambiente (stack.<env>.yml) mesclado por cima
+func (m *manager) LoadEnv(ctx context.Context, path, env string) (*Stack, error) {
+	root, origins, err := m.resolveDocument(ctx, path, env)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, err
+	}
+
+	// Decodificação estrita: campos desconhecidos são rejeitados com sugestão
+	walker := newSchemaWalker(path, origins)
+	walker.walk(root, reflect.TypeOf(Stack{}), "")
+	if len(walker.unknown) > 0 {
+		return nil, walker.unknown
 	}
 
 	var stack Stack
-	if err := yaml.Unmarshal(data, &stack); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+	if err := root.Decode(&stack); err != nil {
+		return nil, parseError(path, err)
 	}
+	stack.source = walker.source
 
 	return &stack, nil
 }
 
+// Resolve retorna o stack.yml com includes e overlay mesclados, variáveis expandidas e schema migrado
+func (m *manager) Resolve(ctx context.Context, path, env string) ([]byte, error) {
+	root, _, err := m.resolveDocument(ctx, path, env)
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(root, nil)
+}
+
+// resolveDocument carrega o documento YAML (mantendo linha/coluna de cada campo)
+// e aplica includes, overlay do ambiente, interpolação de variáveis e migrações
+func (m *manager) resolveDocument(ctx context.Context, path, env string) (*yaml.Node, map[*yaml.Node]string, error) {
+	loader := newDocumentLoader(ctx, m.loader, m.lookupEnv)
+	root, err := loader.load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if env != "" {
+		if err := validateEnvName(env); err != nil {
+			return nil, nil, err
+		}
+		overlayPath := OverlayPath(path, env)
+		if !m.fs.Exists(overlayPath) {
+			return nil, nil, fmt.Errorf("environment %q: overlay %s not found", env, overlayPath)
+		}
+
+		overlay, err := loader.load(overlayPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		loader.markOrigin(documentRoot(overlay), overlayPath)
+		root.Content[0] = mergeNodes(documentRoot(root), documentRoot(overlay), baseOrder)
+	}
+	stripMergeTags(root)
+
+	// Versões antigas são migradas em memória; versões futuras são recusadas
+	if _, err := migrateDocument(root); err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", path, prefixSpace(err))
+	}
+
+	return root, loader.origins, nil
+}
+
+// prefixSpace separa a mensagem do prefixo arquivo: quando ela não começa com linha:coluna
+func prefixSpace(err error) error {
+	msg := err.Error()
+	if len(msg) > 0 && msg[0] >= '0' && msg[0] <= '9' {
+		return err
+	}
+	return fmt.Errorf(" %w", err)
+}
+
+// parseError converte erros do yaml.v3 para o formato arquivo:linha
+func parseError(path string, err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		lines := make([]string, len(typeErr.Errors))
+		for i, msg := range typeErr.Errors {
+			lines[i] = " - " + withFile(path, msg)
+		}
+		return fmt.Errorf("failed to parse config:\n%s", strings.Join(lines, "\n"))
+	}
+	return fmt.Errorf("failed to parse config: %s", withFile(path, strings.TrimPrefix(err.Error(), "yaml: ")))
+}
+
+// withFile troca o prefixo "line N:" do yaml.v3 por "arquivo:N:"
+func withFile(path, msg string) string {
+	if strings.HasPrefix(msg, "line ") {
+		if idx := strings.Index(msg, ":"); idx > 0 {
+			return fmt.Sprintf("%s:%s:%s", path, strings.TrimPrefix(msg[:idx], "line "), msg[idx+1:])
+		}
+	}
+	return fmt.Sprintf("%s: %s", path, msg)
+}
+
 func (m *manager) Validate(ctx context.Context, stack *Stack) error {
 	return m.validator.Validate(ctx, stack)
 }
 
+func (m *manager) Check(ctx context.Context, stack *Stack) *Report {
+	return m.validator.Check(ctx, stack)
+}
+
 func (m *manager) Create(ctx context.Context, path string, options CreateOptions) error {
 	if m.fs.Exists(path) {
 		return errors.New("stack.yml already exists")
@@ -114,7 +216,7 @@ func (m *manager) Create(ctx context.Context, path string, options CreateOptions
 	// Beszel não usa BasicAuth - usa autenticação própria via tokens
 
 	stack := &Stack{
-		Version:     1,
+		Version:     CurrentVersion,
 		Project:     options.Project,
 		Domain:      options.Domain,
 		Environment: env,
@@ -156,12 +258,61 @@ func (m *manager) Create(ctx context.Context, path string, options CreateOptions
 	return m.fs.WriteFile(path, data, 0644)
 }
 
-// SaveBaseConfig salva a configuração base do servidor
+// SaveBaseConfig salva a configuração base do servidor. Quando o arquivo já existe,
+// apenas os campos alterados em stack são escritos, preservando comentários e formatação.
 func (m *manager) SaveBaseConfig(ctx context.Context, path string, stack *Stack) error {
-	data, err := yaml.Marshal(stack)
+	if !m.fs.Exists(path) {
+		data, err := yaml.Marshal(stack)
+		if err != nil {
+			return fmt.Errorf("failed to marshal base config: %w", err)
+		}
+		return m.fs.WriteFile(path, data, 0644)
+	}
+
+	// Estado de onde stack foi carregada: só o que difere dele é escrito, e apenas
+	// quando o campo está no próprio arquivo (não em um include)
+	before, origins, err := m.resolveDocument(ctx, path, "")
 	if err != nil {
-		return fmt.Errorf("failed to marshal base config: %w", err)
+		return err
 	}
 
-	return m.fs.WriteFile(path, data, 0644)
+	return m.Edit(ctx, path, func(doc *Document) error {
+		if err := doc.Update(before, origins, stack); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// Edit aplica alterações pontuais no arquivo preservando comentários e formatação
+func (m *manager) Edit(ctx context.Context, path string, edit func(doc *Document) error) error {
+	data, err := m.fs.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return parseError(path, err)
+	}
+
+	// O arquivo é editado na versão em que está: migrar aqui reescreveria campos que a
+	// edição não pediu
+	if err := requireCurrentVersion(doc.root); err != nil {
+		return fmt.Errorf("%s:%w", path, prefixSpace(err))
+	}
+
+	if err := edit(doc); err != nil {
+		return err
+	}
+
+	updated, err := doc.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if bytes.Equal(updated, data) {
+		return nil
+	}
+
+	return m.fs.WriteFile(path, updated, 0644)
 }
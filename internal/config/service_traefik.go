@@ -0,0 +1,243 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serviceTraefikFields evita recursão ao decodificar/serializar ServiceTraefik
+type serviceTraefikFields ServiceTraefik
+
+// UnmarshalYAML aceita o formato curto (traefik: true) e o bloco completo
+func (t *ServiceTraefik) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var enabled bool
+		if err := value.Decode(&enabled); err != nil {
+			return fmt.Errorf("line %d: traefik must be true, false or a mapping, got %q", value.Line, value.Value)
+		}
+		*t = ServiceTraefik{Enabled: enabled}
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: traefik must be true, false or a mapping", value.Line)
+	}
+
+	var fields serviceTraefikFields
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*t = ServiceTraefik(fields)
+	return nil
+}
+
+// MarshalYAML mantém o formato curto quando só enabled está definido
+func (t ServiceTraefik) MarshalYAML() (interface{}, error) {
+	if reflect.DeepEqual(t, ServiceTraefik{Enabled: t.Enabled}) {
+		return t.Enabled, nil
+	}
+	return serviceTraefikFields(t), nil
+}
+
+var (
+	traefikNameFormat = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*(@[a-z0-9]+)?$`)
+	cookieNameFormat  = regexp.MustCompile(`^[!#$%&'*+.^_|~0-9A-Za-z-]+$`)
+	hostnameFormat    = regexp.MustCompile(`^(\*\.)?([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+	resolverCommand   = regexp.MustCompile(`^--certificatesresolvers\.([^.=]+)\.`)
+	entryPointCommand = regexp.MustCompile(`^--entrypoints\.([^.=]+)\.address=`)
+)
+
+var healthCheckMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// knownEntryPoints retorna os entry points definidos no Traefik gerado
+func knownEntryPoints(stack *Stack) map[string]bool {
+	known := map[string]bool{"web": true, "websecure": true}
+	if stack.Traefik != nil {
+		for name := range stack.Traefik.EntryPoints {
+			known[name] = true
+		}
+		for _, cmd := range stack.Traefik.Commands {
+			if m := entryPointCommand.FindStringSubmatch(cmd); m != nil {
+				known[m[1]] = true
+			}
+		}
+	}
+	return known
+}
+
+// knownResolvers retorna os cert resolvers definidos no Traefik gerado
+func knownResolvers(stack *Stack) map[string]bool {
+	known := map[string]bool{stack.TLS.Resolver: true}
+	if stack.Traefik != nil {
+		for _, cmd := range stack.Traefik.Commands {
+			if m := resolverCommand.FindStringSubmatch(cmd); m != nil {
+				known[m[1]] = true
+			}
+		}
+	}
+	return known
+}
+
+func validDuration(value string) bool {
+	_, err := time.ParseDuration(value)
+	return err == nil
+}
+
+// checkServiceTraefik valida cada campo do bloco traefik de um serviço
+func checkServiceTraefik(stack *Stack, name string, t *ServiceTraefik, path string, report reportFunc) {
+	checkRuleSource(name, t.Rule, t.Match, path, report)
+
+	if t.Priority < 0 {
+		report(path+".priority", fmt.Sprintf("%s: priority cannot be negative", name), "use 0 for the default or a positive number")
+	}
+
+	entryPoints := knownEntryPoints(stack)
+	for j, ep := range t.EntryPoints {
+		if !entryPoints[ep] {
+			report(fmt.Sprintf("%s.entrypoints[%d]", path, j), fmt.Sprintf("%s: unknown entrypoint %q", name, ep), "use web, websecure or one declared in 'traefik.entrypoints'")
+		}
+	}
+
+	for j, mw := range t.Middlewares {
+		if !traefikNameFormat.MatchString(mw) {
+			report(fmt.Sprintf("%s.middlewares[%d]", path, j), fmt.Sprintf("%s: invalid middleware name %q", name, mw), "use a middleware name, optionally with @provider (ex: auth@file)")
+		}
+	}
+
+	for key := range t.Labels {
+		if !strings.HasPrefix(key, "traefik.") {
+			report(path+".labels."+key, fmt.Sprintf("%s: label %q is not a traefik label", name, key), "traefik labels start with 'traefik.'")
+		}
+	}
+
+	if t.TLS != nil {
+		tls := t.TLS
+		if tls.CertResolver != "" && !knownResolvers(stack)[tls.CertResolver] {
+			report(path+".tls.certResolver", fmt.Sprintf("%s: unknown cert resolver %q", name, tls.CertResolver), fmt.Sprintf("the generated Traefik defines %q (tls.resolver)", stack.TLS.Resolver))
+		}
+		for j, d := range tls.Domains {
+			domainPath := fmt.Sprintf("%s.tls.domains[%d]", path, j)
+			if d.Main == "" {
+				report(domainPath+".main", fmt.Sprintf("%s: tls domain needs 'main'", name), "set the certificate's main domain")
+			} else if !hostnameFormat.MatchString(d.Main) {
+				report(domainPath+".main", fmt.Sprintf("%s: invalid tls domain %q", name, d.Main), "use a hostname without scheme or port (ex: api.example.com)")
+			}
+			for k, san := range d.SANs {
+				if !hostnameFormat.MatchString(san) {
+					report(fmt.Sprintf("%s.sans[%d]", domainPath, k), fmt.Sprintf("%s: invalid tls SAN %q", name, san), "use a hostname without scheme or port")
+				}
+			}
+		}
+		if tls.Options != "" && !traefikNameFormat.MatchString(tls.Options) {
+			report(path+".tls.options", fmt.Sprintf("%s: invalid tls options name %q", name, tls.Options), "use a TLS options name, optionally with @provider")
+		}
+		if tls.Store != "" && !traefikNameFormat.MatchString(tls.Store) {
+			report(path+".tls.store", fmt.Sprintf("%s: invalid tls store name %q", name, tls.Store), "use a TLS store name (ex: default)")
+		}
+	}
+
+	if t.LoadBalancer != nil {
+		checkServiceLB(name, t.LoadBalancer, path+".loadBalancer", report)
+	}
+}
+
+func checkServiceLB(name string, lb *ServiceLB, path string, report reportFunc) {
+	if lb.Sticky != nil && lb.Sticky.Cookie != nil {
+		cookie := lb.Sticky.Cookie
+		if cookie.Name != "" && !cookieNameFormat.MatchString(cookie.Name) {
+			report(path+".sticky.cookie.name", fmt.Sprintf("%s: invalid cookie name %q", name, cookie.Name), "use letters, digits and - _ . without spaces")
+		}
+		switch strings.ToLower(cookie.SameSite) {
+		case "", "none", "lax", "strict":
+		default:
+			report(path+".sticky.cookie.sameSite", fmt.Sprintf("%s: invalid sameSite %q", name, cookie.SameSite), "use one of: none, lax, strict")
+		}
+		if strings.EqualFold(cookie.SameSite, "none") && !cookie.Secure {
+			report(path+".sticky.cookie.secure", fmt.Sprintf("%s: sameSite none requires a secure cookie", name), "set 'secure: true'")
+		}
+	}
+
+	if hc := lb.HealthCheck; hc != nil {
+		hcPath := path + ".healthCheck"
+		if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+			report(hcPath+".path", fmt.Sprintf("%s: health check path must start with '/': %s", name, hc.Path), "use an absolute path (ex: /health)")
+		}
+		if hc.Port < 0 || hc.Port > 65535 {
+			report(hcPath+".port", fmt.Sprintf("%s: invalid health check port %d", name, hc.Port), "use a port between 1 and 65535")
+		}
+		if hc.Interval != "" && !validDuration(hc.Interval) {
+			report(hcPath+".interval", fmt.Sprintf("%s: invalid interval %q", name, hc.Interval), "use a duration like 10s or 1m")
+		}
+		if hc.Timeout != "" && !validDuration(hc.Timeout) {
+			report(hcPath+".timeout", fmt.Sprintf("%s: invalid timeout %q", name, hc.Timeout), "use a duration like 5s")
+		}
+		if hc.Interval != "" && hc.Timeout != "" && validDuration(hc.Interval) && validDuration(hc.Timeout) {
+			interval, _ := time.ParseDuration(hc.Interval)
+			timeout, _ := time.ParseDuration(hc.Timeout)
+			if timeout > interval {
+				report(hcPath+".timeout", fmt.Sprintf("%s: health check timeout %s is longer than the interval %s", name, hc.Timeout, hc.Interval), "keep the timeout below the interval")
+			}
+		}
+		if hc.Hostname != "" && !hostnameFormat.MatchString(hc.Hostname) {
+			report(hcPath+".hostname", fmt.Sprintf("%s: invalid health check hostname %q", name, hc.Hostname), "use a hostname without scheme or port")
+		}
+		if hc.Method != "" && !healthCheckMethods[strings.ToUpper(hc.Method)] {
+			report(hcPath+".method", fmt.Sprintf("%s: invalid health check method %q", name, hc.Method), "use an HTTP method like GET or HEAD")
+		}
+		if hc.Status != 0 && (hc.Status < 100 || hc.Status > 599) {
+			report(hcPath+".status", fmt.Sprintf("%s: invalid health check status %d", name, hc.Status), "use an HTTP status between 100 and 599")
+		}
+		switch hc.Scheme {
+		case "", "http", "https", "h2c":
+		default:
+			report(hcPath+".scheme", fmt.Sprintf("%s: invalid health check scheme %q", name, hc.Scheme), "use one of: http, https, h2c")
+		}
+		for header := range hc.Headers {
+			if header == "" || strings.ContainsAny(header, " :") {
+				report(hcPath+".headers", fmt.Sprintf("%s: invalid health check header %q", name, header), "use a plain header name (ex: X-Health)")
+			}
+		}
+	}
+
+	if lb.ResponseForwarding != nil && lb.ResponseForwarding.FlushInterval != "" && !validDuration(lb.ResponseForwarding.FlushInterval) {
+		report(path+".responseForwarding.flushInterval", fmt.Sprintf("%s: invalid flushInterval %q", name, lb.ResponseForwarding.FlushInterval), "use a duration like 100ms")
+	}
+
+	if lb.ServersTransport != "" && !traefikNameFormat.MatchString(lb.ServersTransport) {
+		report(path+".serversTransport", fmt.Sprintf("%s: invalid serversTransport name %q", name, lb.ServersTransport), "use a transport name, optionally with @provider")
+	}
+}
+
+// Types lista os tipos de middleware do Traefik configurados (cors conta como headers)
+func (m TraefikMiddleware) Types() []string {
+	var types []string
+	add := func(set bool, name string) {
+		if set {
+			types = append(types, name)
+		}
+	}
+	add(m.AddPrefix != nil, "addPrefix")
+	add(m.StripPrefix != nil, "stripPrefix")
+	add(m.ReplacePathRegex != nil, "replacePathRegex")
+	if m.Auth != nil {
+		add(m.Auth.Basic != nil, "basicAuth")
+		add(m.Auth.Digest != nil, "digestAuth")
+		add(m.Auth.Forward != nil, "forwardAuth")
+	}
+	add(m.Headers != nil || m.CORS != nil, "headers")
+	add(m.RateLimit != nil, "rateLimit")
+	add(m.Retry != nil, "retry")
+	add(m.CircuitBreaker != nil, "circuitBreaker")
+	add(m.Compress != nil, "compress")
+	for _, key := range sortedKeys(m.CustomMiddleware) {
+		types = append(types, key)
+	}
+	return types
+}
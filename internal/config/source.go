@@ -0,0 +1,331 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position aponta para um local no arquivo de origem
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsZero indica se a posição é desconhecida
+func (p Position) IsZero() bool {
+	return p.Line == 0
+}
+
+func (p Position) String() string {
+	if p.IsZero() {
+		return p.File
+	}
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// FieldError é um erro associado a um campo do arquivo
+type FieldError struct {
+	Pos        Position
+	Path       string
+	Message    string
+	Suggestion string
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	if loc := e.Pos.String(); loc != "" {
+		b.WriteString(loc)
+		b.WriteString(": ")
+	}
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	if e.Suggestion != "" {
+		fmt.Fprintf(&b, " (did you mean %q?)", e.Suggestion)
+	}
+	return b.String()
+}
+
+// FieldErrors agrega vários erros de campo
+type FieldErrors []*FieldError
+
+func (errs FieldErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = " - " + e.Error()
+	}
+	return "invalid config:\n" + strings.Join(lines, "\n")
+}
+
+// sourceMap guarda a posição de cada campo pelo seu caminho (ex: services[0].expose)
+type sourceMap struct {
+	file      string
+	positions map[string]Position
+}
+
+// Position retorna a posição de um campo carregado do arquivo, se conhecida
+func (s *Stack) Position(path string) Position {
+	if s.source == nil {
+		return Position{}
+	}
+	if pos, ok := s.source.positions[path]; ok {
+		return pos
+	}
+	// Sobe na árvore até encontrar um ancestral conhecido
+	for path != "" {
+		idx := strings.LastIndexAny(path, ".[")
+		if idx < 0 {
+			break
+		}
+		path = path[:idx]
+		if pos, ok := s.source.positions[path]; ok {
+			return pos
+		}
+	}
+	return Position{File: s.source.file}
+}
+
+// SourceFile retorna o arquivo de onde a stack foi carregada
+func (s *Stack) SourceFile() string {
+	if s.source == nil {
+		return ""
+	}
+	return s.source.file
+}
+
+// schemaWalker percorre a árvore YAML comparando com os tipos Go
+type schemaWalker struct {
+	source  *sourceMap
+	origins map[*yaml.Node]string // nós vindos de arquivos incluídos
+	unknown FieldErrors
+}
+
+func newSchemaWalker(file string, origins map[*yaml.Node]string) *schemaWalker {
+	return &schemaWalker{
+		source:  &sourceMap{file: file, positions: make(map[string]Position)},
+		origins: origins,
+	}
+}
+
+func (w *schemaWalker) position(node *yaml.Node) Position {
+	file := w.source.file
+	if origin, ok := w.origins[node]; ok {
+		file = origin
+	}
+	return Position{File: file, Line: node.Line, Column: node.Column}
+}
+
+func (w *schemaWalker) walk(node *yaml.Node, t reflect.Type, path string) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			w.walk(child, t, path)
+		}
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		w.walk(node.Alias, t, path)
+		return
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Struct:
+			w.walkStruct(node, t, path)
+		case reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				childPath := joinPath(path, key.Value)
+				w.source.positions[childPath] = w.position(key)
+				w.walk(value, t.Elem(), childPath)
+			}
+		case reflect.Interface:
+			// Campo livre: registra posições sem validar chaves
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				childPath := joinPath(path, key.Value)
+				w.source.positions[childPath] = w.position(key)
+				w.walk(value, t, childPath)
+			}
+		}
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			elem = t.Elem()
+		case reflect.Interface:
+			elem = t
+		default:
+			return
+		}
+		for i, child := range node.Content {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			w.source.positions[childPath] = w.position(child)
+			w.walk(child, elem, childPath)
+		}
+	}
+}
+
+func (w *schemaWalker) walkStruct(node *yaml.Node, t reflect.Type, path string) {
+	fields, inlineMap := structFields(t)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		childPath := joinPath(path, key.Value)
+		w.source.positions[childPath] = w.position(key)
+
+		fieldType, ok := fields[key.Value]
+		if !ok {
+			if inlineMap != nil {
+				w.walk(value, inlineMap.Elem(), childPath)
+				continue
+			}
+			w.unknown = append(w.unknown, &FieldError{
+				Pos:        w.position(key),
+				Path:       childPath,
+				Message:    fmt.Sprintf("unknown field %q", key.Value),
+				Suggestion: suggestField(key.Value, fields),
+			})
+			continue
+		}
+
+		w.walk(value, fieldType, childPath)
+	}
+}
+
+// structFields mapeia o nome YAML de cada campo para o tipo usado na validação
+func structFields(t reflect.Type) (map[string]reflect.Type, reflect.Type) {
+	fields := make(map[string]reflect.Type)
+	var inlineMap reflect.Type
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // não exportado
+		}
+
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		inline := false
+		for _, opt := range parts[1:] {
+			if opt == "inline" {
+				inline = true
+			}
+		}
+
+		if inline {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Map {
+				inlineMap = ft
+				continue
+			}
+			nested, nestedMap := structFields(ft)
+			for k, v := range nested {
+				fields[k] = v
+			}
+			if nestedMap != nil {
+				inlineMap = nestedMap
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+
+	return fields, inlineMap
+}
+
+// suggestField sugere o campo conhecido mais próximo de um nome digitado errado
+func suggestField(name string, fields map[string]reflect.Type) string {
+	candidates := make([]string, 0, len(fields))
+	for k := range fields {
+		candidates = append(candidates, k)
+	}
+	sort.Strings(candidates)
+
+	normalized := normalizeKey(name)
+	best, bestDist := "", -1
+	for _, candidate := range candidates {
+		if normalizeKey(candidate) == normalized {
+			return candidate
+		}
+		dist := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+
+	// Aceita no máximo ~1/3 do tamanho do nome em edições
+	limit := len(name) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	if bestDist >= 0 && bestDist <= limit {
+		return best
+	}
+	return ""
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
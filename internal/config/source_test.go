@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownFieldsPositionAndSuggestion(t *testing.T) {
+	_, err := loadFiles(t, "",
+		[2]string{"stack.yml", `version: 1
+projet: demo
+include: extra.yml
+services:
+  - name: api
+    imagee: api:1
+    health-check: {enabled: true}
+    zzz: 1
+`},
+		[2]string{"extra.yml", "networks:\n  public:\n    internall: true\n"},
+	)
+
+	var errs FieldErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Load() error = %v, want FieldErrors", err)
+	}
+
+	type found struct {
+		File       string
+		Line, Col  int
+		Path       string
+		Suggestion string
+	}
+	var got []found
+	for _, e := range errs {
+		got = append(got, found{filepath.Base(e.Pos.File), e.Pos.Line, e.Pos.Column, e.Path, e.Suggestion})
+	}
+
+	want := []found{
+		{"stack.yml", 2, 1, "projet", "project"},
+		{"stack.yml", 6, 5, "services[0].imagee", "image"},
+		{"stack.yml", 7, 5, "services[0].health-check", "health_check"},
+		{"stack.yml", 8, 5, "services[0].zzz", ""},
+		// O campo do include aponta para o arquivo de origem
+		{"extra.yml", 3, 5, "networks.public.internall", "internal"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unknown fields = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSuggestField(t *testing.T) {
+	fields := structFieldsOf(t, Service{})
+
+	tests := map[string]string{
+		"imag":         "image",
+		"Image":        "image",
+		"replica":      "replicas",
+		"health-check": "health_check",
+		"HealthCheck":  "health_check",
+		"x":            "",
+		"completely":   "",
+	}
+	for name, want := range tests {
+		if got := suggestField(name, fields); got != want {
+			t.Errorf("suggestField(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func structFieldsOf(t *testing.T, v interface{}) map[string]reflect.Type {
+	t.Helper()
+	fields, _ := structFields(reflect.TypeOf(v))
+	return fields
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// streamTLSModes são os modos de TLS aceitos em rotas TCP
+var streamTLSModes = map[string]bool{"": true, "none": true, "passthrough": true, "terminate": true}
+
+// reservedPorts retorna as portas já usadas pelo Traefik gerado, por protocolo (ex: tcp/443)
+func reservedPorts(stack *Stack) map[string]string {
+	reserved := map[string]string{
+		"tcp/80":   "the web entrypoint",
+		"tcp/443":  "the websecure entrypoint",
+		"tcp/8080": "the Traefik dashboard",
+	}
+	if stack.Traefik == nil {
+		return reserved
+	}
+	for _, port := range stack.Traefik.Ports {
+		if key, ok := publishedPortKey(port); ok {
+			reserved[key] = fmt.Sprintf("traefik.ports %q", port)
+		}
+	}
+	for _, name := range sortedKeys(stack.Traefik.EntryPoints) {
+		if key, ok := addressPortKey(stack.Traefik.EntryPoints[name].Address); ok {
+			reserved[key] = fmt.Sprintf("the %s entrypoint", name)
+		}
+	}
+	for _, cmd := range stack.Traefik.Commands {
+		if m := entryPointCommand.FindStringSubmatch(cmd); m != nil {
+			if key, ok := addressPortKey(strings.TrimPrefix(cmd, m[0])); ok {
+				reserved[key] = fmt.Sprintf("the %s entrypoint", m[1])
+			}
+		}
+	}
+	return reserved
+}
+
+// publishedPortKey extrai a porta do host de "8443:443" ou "53:53/udp"
+func publishedPortKey(port string) (string, bool) {
+	protocol := "tcp"
+	if base, proto, ok := strings.Cut(port, "/"); ok {
+		port, protocol = base, proto
+	}
+	parts := strings.Split(port, ":")
+	host := parts[0]
+	if len(parts) > 1 {
+		host = parts[len(parts)-2] // ignora o IP em "127.0.0.1:80:80"
+	}
+	if _, err := strconv.Atoi(host); err != nil {
+		return "", false
+	}
+	return protocol + "/" + host, true
+}
+
+// addressPortKey extrai a porta de um endereço de entrypoint (":5432" ou ":53/udp")
+func addressPortKey(address string) (string, bool) {
+	protocol := "tcp"
+	if base, proto, ok := strings.Cut(address, "/"); ok {
+		address, protocol = base, proto
+	}
+	idx := strings.LastIndexByte(address, ':')
+	if idx < 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(address[idx+1:]); err != nil {
+		return "", false
+	}
+	return protocol + "/" + address[idx+1:], true
+}
+
+// checkStreamRoutes valida as rotas tcp/udp de um serviço
+func checkStreamRoutes(stack *Stack, sv Service, protocol string, routes []StreamRoute, path string, report reportFunc) {
+	entryPoints := knownEntryPoints(stack)
+	listens := make(map[int]bool)
+
+	for j, route := range routes {
+		routePath := fmt.Sprintf("%s[%d]", path, j)
+
+		if route.Port < 1 || route.Port > 65535 {
+			report(routePath+".port", fmt.Sprintf("%s: invalid %s port %d", sv.Name, protocol, route.Port), "use the port the container listens on (1-65535)")
+		}
+		if route.Listen < 0 || route.Listen > 65535 {
+			report(routePath+".listen", fmt.Sprintf("%s: invalid %s listen port %d", sv.Name, protocol, route.Listen), "use a port between 1 and 65535")
+		}
+
+		if route.EntryPoint != "" {
+			if !entryPoints[route.EntryPoint] {
+				report(routePath+".entrypoint", fmt.Sprintf("%s: unknown entrypoint %q", sv.Name, route.EntryPoint), "declare it in 'traefik.entrypoints' or remove it to create one from the port")
+			}
+			if route.Listen != 0 {
+				report(routePath+".listen", fmt.Sprintf("%s: listen cannot be combined with entrypoint", sv.Name), "the entrypoint already defines the address")
+			}
+		}
+
+		if listens[route.ListenPort()] {
+			report(routePath, fmt.Sprintf("%s: %s port %d is routed twice", sv.Name, protocol, route.ListenPort()), "route each port once per service (a TCP route accepts several sni hosts)")
+		}
+		listens[route.ListenPort()] = true
+
+		if protocol == "udp" {
+			if len(route.SNI) > 0 {
+				report(routePath+".sni", fmt.Sprintf("%s: udp routes do not support sni", sv.Name), "remove 'sni' (UDP has no TLS handshake)")
+			}
+			if route.TLS != "" {
+				report(routePath+".tls", fmt.Sprintf("%s: udp routes do not support tls", sv.Name), "remove 'tls'")
+			}
+			continue
+		}
+
+		if !streamTLSModes[route.TLS] {
+			report(routePath+".tls", fmt.Sprintf("%s: invalid tls mode %q", sv.Name, route.TLS), "use one of: none, passthrough, terminate")
+		}
+		if len(route.SNI) > 0 && (route.TLS == "" || route.TLS == "none") {
+			report(routePath+".sni", fmt.Sprintf("%s: sni requires tls", sv.Name), "set 'tls: passthrough' or 'tls: terminate' (plain TCP has no SNI)")
+		}
+		for k, host := range route.SNI {
+			if !hostnameFormat.MatchString(host) {
+				report(fmt.Sprintf("%s.sni[%d]", routePath, k), fmt.Sprintf("%s: invalid sni host %q", sv.Name, host), "use a hostname without scheme or port")
+			}
+		}
+	}
+}
+
+// streamListener é uma rota tcp/udp publicada em uma porta do Traefik
+type streamListener struct {
+	path    string
+	service string
+	route   StreamRoute
+}
+
+// checkStreamPorts detecta portas tcp/udp que colidem com o Traefik ou entre serviços
+func checkStreamPorts(stack *Stack, report reportFunc) {
+	reserved := reservedPorts(stack)
+	listeners := make(map[string][]streamListener)
+
+	for i, sv := range stack.Services {
+		for protocol, routes := range map[string][]StreamRoute{"tcp": sv.TCP, "udp": sv.UDP} {
+			for j, route := range routes {
+				if route.EntryPoint != "" {
+					continue // entrypoint existente, sem porta nova
+				}
+				key := fmt.Sprintf("%s/%d", protocol, route.ListenPort())
+				path := servicePath(i, fmt.Sprintf("%s[%d]", protocol, j))
+				if owner, ok := reserved[key]; ok {
+					report(path, fmt.Sprintf("%s: %s port %d is already used by %s", sv.Name, protocol, route.ListenPort(), owner), "set 'listen' to a free port")
+					continue
+				}
+				listeners[key] = append(listeners[key], streamListener{path: path, service: sv.Name, route: route})
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(listeners) {
+		shared := listeners[key]
+		if len(shared) < 2 {
+			continue
+		}
+		sort.Slice(shared, func(a, b int) bool { return shared[a].path < shared[b].path })
+
+		// Rotas TCP com TLS compartilham a porta quando o SNI distingue cada uma
+		if strings.HasPrefix(key, "tcp/") && distinctSNI(shared) {
+			continue
+		}
+		protocol, port, _ := strings.Cut(key, "/")
+		for _, l := range shared[1:] {
+			if l.service == shared[0].service {
+				continue // já reportado pelo SVC011
+			}
+			report(l.path, fmt.Sprintf("%s: %s port %s is also routed by %s", l.service, protocol, port, shared[0].service),
+				"use another 'listen' port, or tls with distinct sni hosts to share a TCP port")
+		}
+	}
+}
+
+// distinctSNI indica se todas as rotas usam TLS e hosts SNI exclusivos, sem curinga
+func distinctSNI(listeners []streamListener) bool {
+	hosts := make(map[string]bool)
+	for _, l := range listeners {
+		if l.route.TLS == "" || l.route.TLS == "none" || len(l.route.SNI) == 0 {
+			return false
+		}
+		for _, host := range l.route.SNI {
+			host = strings.ToLower(host)
+			if host == "*" || strings.HasPrefix(host, "*.") || hosts[host] {
+				return false
+			}
+			hosts[host] = true
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+var tlsVersions = map[string]int{
+	"VersionTLS10": 10, "VersionTLS11": 11, "VersionTLS12": 12, "VersionTLS13": 13,
+}
+
+var tlsCurves = map[string]bool{
+	"CurveP256": true, "CurveP384": true, "CurveP521": true, "X25519": true, "X25519MLKEM768": true,
+	"secp256r1": true, "secp384r1": true, "secp521r1": true, "x25519": true,
+}
+
+var clientAuthTypes = map[string]bool{
+	"NoClientCert": true, "RequestClientCert": true, "RequireAnyClientCert": true,
+	"VerifyClientCertIfGiven": true, "RequireAndVerifyClientCert": true,
+}
+
+// cipherSuites retorna os nomes de cipher suites aceitos pelo Go (e pelo Traefik)
+func cipherSuites() map[string]bool {
+	names := make(map[string]bool)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		names[suite.Name] = true
+	}
+	return names
+}
+
+// checkTLSOptions valida os perfis de tls.options, as referências a eles e o mtls dos serviços
+func checkTLSOptions(stack *Stack, report reportFunc) {
+	suites := cipherSuites()
+	for _, name := range sortedKeys(stack.TLS.Options) {
+		options := stack.TLS.Options[name]
+		path := "tls.options." + name
+		if !traefikNameFormat.MatchString(name) || strings.Contains(name, "@") {
+			report(path, fmt.Sprintf("invalid tls options name %q", name), "use letters, digits and - _ .")
+		}
+		if options.MinVersion != "" && tlsVersions[options.MinVersion] == 0 {
+			report(path+".minVersion", fmt.Sprintf("invalid minVersion %q", options.MinVersion), "use VersionTLS12 or VersionTLS13")
+		}
+		if options.MaxVersion != "" && tlsVersions[options.MaxVersion] == 0 {
+			report(path+".maxVersion", fmt.Sprintf("invalid maxVersion %q", options.MaxVersion), "use VersionTLS12 or VersionTLS13")
+		}
+		if options.MinVersion != "" && options.MaxVersion != "" && tlsVersions[options.MinVersion] > tlsVersions[options.MaxVersion] {
+			report(path+".maxVersion", fmt.Sprintf("maxVersion %s is lower than minVersion %s", options.MaxVersion, options.MinVersion), "raise maxVersion or lower minVersion")
+		}
+		for i, suite := range options.CipherSuites {
+			if !suites[suite] {
+				report(fmt.Sprintf("%s.cipherSuites[%d]", path, i), fmt.Sprintf("unknown cipher suite %q", suite), "use a Go cipher suite name (ex: TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256)")
+			}
+		}
+		if len(options.CipherSuites) > 0 && options.MinVersion == "VersionTLS13" {
+			report(path+".cipherSuites", "cipherSuites have no effect with minVersion VersionTLS13", "TLS 1.3 suites are not configurable, remove cipherSuites")
+		}
+		for i, curve := range options.CurvePreferences {
+			if !tlsCurves[curve] {
+				report(fmt.Sprintf("%s.curvePreferences[%d]", path, i), fmt.Sprintf("unknown curve %q", curve), "use X25519, CurveP256, CurveP384 or CurveP521")
+			}
+		}
+		if auth := options.ClientAuth; auth != nil {
+			if auth.ClientAuthType != "" && !clientAuthTypes[auth.ClientAuthType] {
+				report(path+".clientAuth.clientAuthType", fmt.Sprintf("invalid clientAuthType %q", auth.ClientAuthType), "use RequireAndVerifyClientCert or VerifyClientCertIfGiven")
+			}
+			checkCAFiles(auth.CAFiles, path+".clientAuth.caFiles", name, report)
+		}
+	}
+
+	for i, sv := range stack.Services {
+		traefik := sv.GetTraefik()
+		if traefik != nil && traefik.TLS != nil && traefik.TLS.Options != "" {
+			if name := traefik.TLS.Options; !strings.Contains(name, "@") && !knownTLSOptions(stack, name) {
+				report(servicePath(i, "traefik.tls.options"), fmt.Sprintf("%s: tls options %q are not defined", sv.Name, name),
+					"declare them in 'tls.options' or reference another provider with @provider")
+			}
+		}
+
+		mtls := sv.MTLS
+		if mtls == nil {
+			continue
+		}
+		path := servicePath(i, "mtls")
+		if traefik == nil || !traefik.Enabled {
+			report(path, fmt.Sprintf("%s: mtls requires traefik", sv.Name), "set 'traefik: true'")
+		}
+		if stack.TLS.Mode == "disabled" {
+			report(path, fmt.Sprintf("%s: mtls requires TLS", sv.Name), "use 'tls.mode: acme' or 'selfsigned'")
+		}
+		if traefik != nil && traefik.TLS != nil && traefik.TLS.Options != "" {
+			report(path, fmt.Sprintf("%s: mtls and traefik.tls.options cannot be combined", sv.Name), "move the profile name to 'mtls.options'")
+		}
+		if mtls.Options != "" && !knownTLSOptions(stack, mtls.Options) {
+			report(path+".options", fmt.Sprintf("%s: tls options %q are not defined", sv.Name, mtls.Options), "declare them in 'tls.options'")
+		}
+		checkCAFiles(mtls.CAFiles, path+".ca_files", sv.Name, report)
+	}
+}
+
+// knownTLSOptions indica se o perfil existe no arquivo dinâmico gerado
+func knownTLSOptions(stack *Stack, name string) bool {
+	_, ok := stack.TLS.Options[name]
+	return ok || name == "default"
+}
+
+func checkCAFiles(files []string, path, owner string, report reportFunc) {
+	for i, file := range files {
+		if strings.TrimSpace(file) == "" || strings.Contains(file, ":") {
+			report(fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("%s: invalid CA file %q", owner, file), "use a path to a PEM bundle on the host")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// documentRoot retorna o nó de conteúdo de um documento YAML
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// mappingValue retorna o valor de uma chave em um nó de mapeamento
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue substitui ou adiciona uma chave mantendo a posição e os comentários existentes
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			old := node.Content[i+1]
+			value.HeadComment = old.HeadComment
+			value.FootComment = old.FootComment
+			if value.Kind == yaml.ScalarNode {
+				value.LineComment = old.LineComment
+				// Strings mantêm as aspas que o arquivo já usava
+				if quoted := old.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle); quoted != 0 &&
+					old.Kind == yaml.ScalarNode && value.Tag == "!!str" && !strings.Contains(value.Value, "\n") {
+					value.Style = quoted
+				}
+			} else if old.LineComment != "" && node.Content[i].LineComment == "" {
+				// Blocos não têm comentário de linha próprio: move para a chave
+				node.Content[i].LineComment = old.LineComment
+			}
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
+
+// deleteMappingKey remove uma chave de um nó de mapeamento
+func deleteMappingKey(node *yaml.Node, key string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// scalarNode cria um nó escalar a partir de um valor Go
+func scalarNode(value interface{}) *yaml.Node {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	return &node
+}
+
+// detectIndent descobre a indentação usada no arquivo (padrão 2)
+func detectIndent(data []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		n := len(line) - len(trimmed)
+		if n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 2 {
+		return 2
+	}
+	return indent
+}
+
+// encodeDocument serializa o documento preservando comentários e a indentação original
+func encodeDocument(doc *yaml.Node, original []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(detectIndent(original))
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func newTestCA(t *testing.T) *CA {
+	t.Helper()
+	certPEM, keyPEM, err := GenerateCA("harborctl test CA")
+	if err != nil {
+		t.Fatalf("GenerateCA() error = %v", err)
+	}
+	ca, err := LoadCA(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("LoadCA() error = %v", err)
+	}
+	return ca
+}
+
+func TestIssueVerifiesAgainstCA(t *testing.T) {
+	ca := newTestCA(t)
+	hosts := []string{"api.example.test", "*.apps.example.test", "10.0.0.5"}
+
+	certPEM, keyPEM, err := ca.Issue(hosts)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatalf("issued key does not match the certificate: %v", err)
+	}
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	for _, name := range []string{"api.example.test", "web.apps.example.test", "10.0.0.5"} {
+		opts := x509.VerifyOptions{
+			DNSName:   name,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		if _, err := cert.Verify(opts); err != nil {
+			t.Errorf("Verify(%s) error = %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"other.example.test", "a.b.apps.example.test"} {
+		opts := x509.VerifyOptions{DNSName: name, Roots: roots}
+		if _, err := cert.Verify(opts); err == nil {
+			t.Errorf("Verify(%s) succeeded, want a hostname error", name)
+		}
+	}
+
+	// Outra CA não valida o certificado
+	other := x509.NewCertPool()
+	other.AddCert(newTestCA(t).Cert)
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "api.example.test", Roots: other}); err == nil {
+		t.Error("Verify() with another CA succeeded, want an unknown authority error")
+	}
+}
+
+func TestIssueClientVerifiesForClientAuth(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, _, err := ca.IssueClient("alice", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueClient() error = %v", err)
+	}
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}); err == nil {
+		t.Fatal("client certificate verified for server auth")
+	}
+	if cert.Subject.CommonName != "alice" {
+		t.Fatalf("CommonName = %q, want alice", cert.Subject.CommonName)
+	}
+}
+
+func TestIssueNeverOutlivesCA(t *testing.T) {
+	ca := newTestCA(t)
+	ca.Cert.NotAfter = time.Now().Add(48 * time.Hour)
+
+	certPEM, _, err := ca.Issue([]string{"api.example.test"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	if cert.NotAfter.After(ca.Cert.NotAfter) {
+		t.Fatalf("NotAfter = %s, after the CA expiry %s", cert.NotAfter, ca.Cert.NotAfter)
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	ca := newTestCA(t)
+	hosts := []string{"api.example.test", "10.0.0.5"}
+	certPEM, _, err := ca.Issue(hosts)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	otherPEM, _, err := newTestCA(t).Issue(hosts)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		cert  []byte
+		hosts []string
+		now   time.Time
+		want  bool
+	}{
+		{"same hosts", certPEM, hosts, now, false},
+		{"same hosts in another order and case", certPEM, []string{"10.0.0.5", "API.example.test"}, now, false},
+		{"host added", certPEM, append([]string{"web.example.test"}, hosts...), now, true},
+		{"host removed", certPEM, hosts[:1], now, true},
+		{"expiry within the renewal window", certPEM, hosts, now.Add(LeafValidity - RenewBefore + time.Hour), true},
+		{"expiry just outside the renewal window", certPEM, hosts, now.Add(LeafValidity - RenewBefore - 2*time.Hour), false},
+		{"issued by another CA", otherPEM, hosts, now, true},
+		{"not a certificate", []byte("garbage"), hosts, now, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ca.NeedsRenewal(tt.cert, tt.hosts, tt.now); got != tt.want {
+				t.Fatalf("NeedsRenewal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCARejectsLeafCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM, err := ca.Issue([]string{"api.example.test"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if _, err := LoadCA(certPEM, keyPEM); err == nil {
+		t.Fatal("LoadCA() accepted a server certificate")
+	}
+}
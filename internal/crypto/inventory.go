@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Certificate resume um certificado do inventário
+type Certificate struct {
+	Domains  []string  `json:"domains"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+	Resolver string    `json:"resolver,omitempty"`
+	Source   string    `json:"source"`
+}
+
+// acmeStore é o formato do acme.json gravado pelo Traefik, indexado pelo nome do resolver
+type acmeStore map[string]*struct {
+	Certificates []struct {
+		Domain struct {
+			Main string   `json:"main"`
+			SANs []string `json:"sans"`
+		} `json:"domain"`
+		Certificate string `json:"certificate"`
+	} `json:"Certificates"`
+}
+
+// CertificateInfo lê um certificado PEM e o descreve
+func CertificateInfo(certPEM []byte, source string) (Certificate, error) {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	domains := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		domains = append(domains, ip.String())
+	}
+	if len(domains) == 0 && cert.Subject.CommonName != "" {
+		domains = []string{cert.Subject.CommonName}
+	}
+
+	issuer := cert.Issuer.CommonName
+	if issuer == "" && len(cert.Issuer.Organization) > 0 {
+		issuer = cert.Issuer.Organization[0]
+	}
+
+	return Certificate{Domains: domains, Issuer: issuer, NotAfter: cert.NotAfter, Source: source}, nil
+}
+
+// ParseACMEStore lê os certificados de um acme.json do Traefik
+func ParseACMEStore(data []byte) ([]Certificate, error) {
+	var store acmeStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("invalid acme.json: %w", err)
+	}
+
+	resolvers := make([]string, 0, len(store))
+	for name := range store {
+		resolvers = append(resolvers, name)
+	}
+	sort.Strings(resolvers)
+
+	var certs []Certificate
+	for _, resolver := range resolvers {
+		if store[resolver] == nil {
+			continue
+		}
+		for _, entry := range store[resolver].Certificates {
+			certPEM, err := base64.StdEncoding.DecodeString(entry.Certificate)
+			if err != nil {
+				return nil, fmt.Errorf("acme.json: certificate for %s: %w", entry.Domain.Main, err)
+			}
+			cert, err := CertificateInfo(certPEM, "acme")
+			if err != nil {
+				return nil, fmt.Errorf("acme.json: certificate for %s: %w", entry.Domain.Main, err)
+			}
+			cert.Resolver = resolver
+			certs = append(certs, cert)
+		}
+	}
+	return certs, nil
+}
+
+// Covers indica se o certificado vale para o host, incluindo curingas de um nível
+func (c Certificate) Covers(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range c.Domains {
+		domain = strings.ToLower(domain)
+		if domain == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(domain, "*."); ok {
+			if label, rest, found := strings.Cut(host, "."); found && label != "" && label != "*" && rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DaysLeft retorna os dias completos até a expiração (negativo se já expirou)
+func (c Certificate) DaysLeft(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCertificateCovers(t *testing.T) {
+	cert := Certificate{Domains: []string{"example.test", "*.apps.example.test", "API.other.test", "10.0.0.5"}}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.test", true},
+		{"EXAMPLE.test", true},
+		{"api.other.test", true},
+		{"10.0.0.5", true},
+		{"web.apps.example.test", true},
+		{"apps.example.test", false},     // o curinga não cobre o próprio domínio
+		{"a.b.apps.example.test", false}, // nem mais de um nível
+		{"*.apps.example.test", true},    // o mesmo curinga, pedido em tls.domains
+		{"*.example.test", false},        // mas não um curinga mais amplo
+		{".apps.example.test", false},    // nem rótulo vazio
+		{"web.apps.example.test.evil", false},
+		{"www.example.test", false},
+		{"10.0.0.6", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := cert.Covers(tt.host); got != tt.want {
+				t.Fatalf("Covers(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificateDaysLeft(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		notAfter time.Time
+		want     int
+	}{
+		{now.Add(10*24*time.Hour + time.Hour), 10},
+		{now.Add(23 * time.Hour), 0},
+		{now.Add(-time.Hour), -1},
+	}
+
+	for _, tt := range tests {
+		if got := (Certificate{NotAfter: tt.notAfter}).DaysLeft(now); got != tt.want {
+			t.Errorf("DaysLeft(%s) = %d, want %d", tt.notAfter, got, tt.want)
+		}
+	}
+}
+
+func TestParseACMEStore(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, _, err := ca.Issue([]string{"api.example.test", "www.example.test"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	data := fmt.Sprintf(`{
+		"staging": {"Certificates": null},
+		"le": {"Account": {}, "Certificates": [
+			{"domain": {"main": "api.example.test", "sans": ["www.example.test"]}, "certificate": %q}
+		]}
+	}`, base64.StdEncoding.EncodeToString(certPEM))
+
+	certs, err := ParseACMEStore([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseACMEStore() error = %v", err)
+	}
+	if len(certs) != 1 {
+		t.Fatalf("ParseACMEStore() = %d certificates, want 1", len(certs))
+	}
+	cert := certs[0]
+	if cert.Resolver != "le" || cert.Source != "acme" || cert.Issuer != "harborctl test CA" {
+		t.Fatalf("certificate = %+v, want resolver le, source acme, issuer harborctl test CA", cert)
+	}
+	if !cert.Covers("www.example.test") {
+		t.Fatalf("certificate domains = %v, want www.example.test covered", cert.Domains)
+	}
+}
+
+func TestParseACMEStoreErrors(t *testing.T) {
+	tests := map[string]string{
+		"not json":       `{`,
+		"not base64":     `{"le": {"Certificates": [{"domain": {"main": "a.test"}, "certificate": "!!"}]}}`,
+		"not a PEM cert": `{"le": {"Certificates": [{"domain": {"main": "a.test"}, "certificate": "aGVsbG8="}]}}`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseACMEStore([]byte(data)); err == nil {
+				t.Fatal("ParseACMEStore() error = nil")
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package docker
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 type ComposeExecutor interface {
 	ComposeUp(ctx context.Context, file string, build bool) error
@@ -10,6 +14,18 @@ type ComposeExecutor interface {
 	ComposeRestart(ctx context.Context, file string, timeout int) error
 	ComposePause(ctx context.Context, file string) error
 	ComposeUnpause(ctx context.Context, file string) error
+	ComposeUpServices(ctx context.Context, file string, build bool, services []string) error
+	ComposeScale(ctx context.Context, file string, service string, replicas int, build bool) error
+	ComposeRemove(ctx context.Context, file string, services []string) error
+	ComposeContainers(ctx context.Context, file string, service string) ([]Container, error)
+	ComposeConfigHash(ctx context.Context, file string, service string) (string, error)
+	ComposeExecOutput(ctx context.Context, file string, service string, command ...string) ([]byte, error)
+}
+
+// ContainerExecutor acts on individual containers
+type ContainerExecutor interface {
+	ContainerRemove(ctx context.Context, ids []string) error
+	ContainerWait(ctx context.Context, ids []string) ([]int, error)
 }
 
 type PruneExecutor interface {
@@ -18,10 +34,17 @@ type PruneExecutor interface {
 	VolumePrune(ctx context.Context) error
 }
 
-// Executor combines compose and prune operations
+// Waiter pauses between deployment steps
+type Waiter interface {
+	Wait(ctx context.Context, d time.Duration) error
+}
+
+// Executor combines compose, container, prune and wait operations
 type Executor interface {
 	ComposeExecutor
+	ContainerExecutor
 	PruneExecutor
+	Waiter
 }
 
 type LifecycleManager interface {
@@ -38,10 +61,30 @@ type CleanupManager interface {
 	Cleanup(ctx context.Context, options CleanupOptions) error
 }
 
-// Service combines lifecycle and cleanup operations
+// RolloutManager deploys and retires individual compose services
+type RolloutManager interface {
+	DeployServices(ctx context.Context, composePath string, options DeployOptions, services []string) error
+	RemoveServices(ctx context.Context, composePath string, services []string) error
+	ScaleService(ctx context.Context, composePath string, service string, replicas int, build bool) error
+	RemoveContainers(ctx context.Context, ids []string) error
+	Containers(ctx context.Context, composePath string, service string) ([]Container, error)
+	ConfigHash(ctx context.Context, composePath string, service string) (string, error)
+	WaitHealthy(ctx context.Context, composePath string, service string, exclude []string, timeout time.Duration) error
+	WaitCompleted(ctx context.Context, composePath string, service string, timeout time.Duration) error
+	Wait(ctx context.Context, d time.Duration) error
+}
+
+// FileReader reads files from inside running service containers
+type FileReader interface {
+	ReadFile(ctx context.Context, composePath string, service string, path string) ([]byte, error)
+}
+
+// Service combines lifecycle, rollout, cleanup and file reading operations
 type Service interface {
 	LifecycleManager
+	RolloutManager
 	CleanupManager
+	FileReader
 }
 
 // DeployOptions configures deployment
@@ -51,6 +94,38 @@ type DeployOptions struct {
 	Detach bool
 }
 
+// ConfigHashLabel is set by docker compose on every container with the hash of its service configuration
+const ConfigHashLabel = "com.docker.compose.config-hash"
+
+// Container is a container of a compose service
+type Container struct {
+	ID     string
+	Name   string
+	State  string // running, exited, ...
+	Health string // healthy, unhealthy, starting (empty without a health check)
+	Labels string // comma separated key=value pairs
+}
+
+// Running reports whether the container is up
+func (c Container) Running() bool {
+	return c.State == "running"
+}
+
+// Healthy reports whether the container is up and passing its health check, if any
+func (c Container) Healthy() bool {
+	return c.Running() && (c.Health == "" || c.Health == "healthy")
+}
+
+// Label returns the value of a container label
+func (c Container) Label(key string) string {
+	for _, pair := range strings.Split(c.Labels, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
 // CleanupOptions configures cleanup
 type CleanupOptions struct {
 	Images   bool
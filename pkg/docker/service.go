@@ -1,20 +1,35 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
 )
 
 // executor implements Executor
-type executor struct{}
+type executor struct {
+	recorder         dryrun.Recorder
+	dryRunContainers int
+}
 
 // NewExecutor creates a new Docker executor
 func NewExecutor() Executor {
 	return &executor{}
 }
 
+// NewDryRunExecutor creates a Docker executor that records commands instead of running them
+func NewDryRunExecutor(recorder dryrun.Recorder) Executor {
+	return &executor{recorder: recorder}
+}
+
 func (e *executor) ComposeUp(ctx context.Context, file string, build bool) error {
 	args := []string{"compose", "-f", file, "up", "-d"}
 	if build {
@@ -55,6 +70,149 @@ func (e *executor) ComposeUnpause(ctx context.Context, file string) error {
 	return e.run(ctx, "docker", "compose", "-f", file, "unpause")
 }
 
+func (e *executor) ComposeUpServices(ctx context.Context, file string, build bool, services []string) error {
+	args := []string{"compose", "-f", file, "up", "-d"}
+	if build {
+		args = append(args, "--build")
+	}
+	return e.run(ctx, "docker", append(args, services...)...)
+}
+
+func (e *executor) ComposeRemove(ctx context.Context, file string, services []string) error {
+	args := append([]string{"compose", "-f", file, "rm", "-s", "-f"}, services...)
+	return e.run(ctx, "docker", args...)
+}
+
+func (e *executor) ComposeScale(ctx context.Context, file string, service string, replicas int, build bool) error {
+	args := []string{"compose", "-f", file, "up", "-d", "--no-deps", "--no-recreate"}
+	if build {
+		args = append(args, "--build")
+	}
+	args = append(args, "--scale", fmt.Sprintf("%s=%d", service, replicas), service)
+	return e.run(ctx, "docker", args...)
+}
+
+func (e *executor) ComposeContainers(ctx context.Context, file string, service string) ([]Container, error) {
+	args := []string{"compose", "-f", file, "ps", "-a", "--format", "json", service}
+	if e.recorder != nil {
+		// Dry-run assumes one healthy container, new on every call so rollouts see replacements
+		e.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand("docker", args...))
+		e.dryRunContainers++
+		id := fmt.Sprintf("%s-%d", service, e.dryRunContainers)
+		return []Container{{ID: id, Name: id, State: "running", Health: "healthy"}}, nil
+	}
+
+	data, err := e.output(ctx, "docker", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseContainers(data)
+}
+
+// parseContainers reads 'docker compose ps --format json', either a JSON array
+// (older releases) or one object per line
+func parseContainers(data []byte) ([]Container, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var containers []Container
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &containers); err != nil {
+			return nil, fmt.Errorf("unexpected docker compose ps output: %w", err)
+		}
+		return containers, nil
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		var container Container
+		if err := json.Unmarshal([]byte(line), &container); err != nil {
+			return nil, fmt.Errorf("unexpected docker compose ps output: %w", err)
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+func (e *executor) ComposeConfigHash(ctx context.Context, file string, service string) (string, error) {
+	args := []string{"compose", "-f", file, "config", "--hash", service}
+	if e.recorder != nil {
+		e.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand("docker", args...))
+		return "", nil
+	}
+
+	data, err := e.output(ctx, "docker", args...)
+	if err != nil {
+		return "", err
+	}
+	// Output: "<service> <hash>"
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || fields[0] != service {
+		return "", fmt.Errorf("unexpected docker compose config --hash output: %q", strings.TrimSpace(string(data)))
+	}
+	return fields[1], nil
+}
+
+func (e *executor) ComposeExecOutput(ctx context.Context, file string, service string, command ...string) ([]byte, error) {
+	args := append([]string{"compose", "-f", file, "exec", "-T", service}, command...)
+	if e.recorder != nil {
+		// Dry-run has no container to read from; callers get empty output
+		e.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand("docker", args...))
+		return nil, nil
+	}
+	return e.output(ctx, "docker", args...)
+}
+
+func (e *executor) ContainerRemove(ctx context.Context, ids []string) error {
+	if err := e.run(ctx, "docker", append([]string{"stop"}, ids...)...); err != nil {
+		return err
+	}
+	return e.run(ctx, "docker", append([]string{"rm"}, ids...)...)
+}
+
+// ContainerWait blocks until the containers stop and returns their exit codes
+func (e *executor) ContainerWait(ctx context.Context, ids []string) ([]int, error) {
+	args := append([]string{"wait"}, ids...)
+	if e.recorder != nil {
+		// Dry-run assumes every container completes successfully
+		e.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand("docker", args...))
+		return make([]int, len(ids)), nil
+	}
+
+	data, err := e.output(ctx, "docker", args...)
+	if err != nil {
+		return nil, err
+	}
+	var codes []int
+	for _, line := range strings.Fields(string(data)) {
+		code, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected docker wait output: %q", line)
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) != len(ids) {
+		return nil, fmt.Errorf("unexpected docker wait output: %d exit codes for %d containers", len(codes), len(ids))
+	}
+	return codes, nil
+}
+
+func (e *executor) Wait(ctx context.Context, d time.Duration) error {
+	if e.recorder != nil {
+		e.recorder.Record(dryrun.KindWait, "%s", d)
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (e *executor) ImagePrune(ctx context.Context, filters ...string) error {
 	args := []string{"image", "prune", "-af"}
 	for _, filter := range filters {
@@ -76,12 +234,32 @@ func (e *executor) VolumePrune(ctx context.Context) error {
 }
 
 func (e *executor) run(ctx context.Context, name string, args ...string) error {
+	if e.recorder != nil {
+		e.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand(name, args...))
+		return nil
+	}
+
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// healthPollInterval is the delay between health checks while waiting for a service
+const healthPollInterval = 2 * time.Second
+
+// output runs a command and returns its stdout
+func (e *executor) output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
 // service implements Service
 type service struct {
 	executor Executor
@@ -98,7 +276,17 @@ func (s *service) Deploy(ctx context.Context, composePath string, options Deploy
 	if err := s.executor.ComposeUp(ctx, composePath, options.Build); err != nil {
 		return err
 	}
+	return s.afterDeploy(ctx, options)
+}
+
+func (s *service) DeployServices(ctx context.Context, composePath string, options DeployOptions, services []string) error {
+	if err := s.executor.ComposeUpServices(ctx, composePath, options.Build, services); err != nil {
+		return err
+	}
+	return s.afterDeploy(ctx, options)
+}
 
+func (s *service) afterDeploy(ctx context.Context, options DeployOptions) error {
 	if options.Prune {
 		return s.Cleanup(ctx, CleanupOptions{
 			Images:  true,
@@ -134,6 +322,115 @@ func (s *service) Unpause(ctx context.Context, composePath string) error {
 	return s.executor.ComposeUnpause(ctx, composePath)
 }
 
+func (s *service) RemoveServices(ctx context.Context, composePath string, services []string) error {
+	return s.executor.ComposeRemove(ctx, composePath, services)
+}
+
+func (s *service) ScaleService(ctx context.Context, composePath string, service string, replicas int, build bool) error {
+	return s.executor.ComposeScale(ctx, composePath, service, replicas, build)
+}
+
+func (s *service) RemoveContainers(ctx context.Context, ids []string) error {
+	return s.executor.ContainerRemove(ctx, ids)
+}
+
+func (s *service) Containers(ctx context.Context, composePath string, service string) ([]Container, error) {
+	return s.executor.ComposeContainers(ctx, composePath, service)
+}
+
+func (s *service) ConfigHash(ctx context.Context, composePath string, service string) (string, error) {
+	return s.executor.ComposeConfigHash(ctx, composePath, service)
+}
+
+// WaitHealthy polls the service until every container not in exclude is healthy,
+// failing fast when one exits or turns unhealthy
+func (s *service) WaitHealthy(ctx context.Context, composePath string, service string, exclude []string, timeout time.Duration) error {
+	skip := make(map[string]bool, len(exclude))
+	for _, id := range exclude {
+		skip[id] = true
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		containers, err := s.executor.ComposeContainers(ctx, composePath, service)
+		if err != nil {
+			return err
+		}
+
+		waiting := 0
+		healthy := 0
+		for _, container := range containers {
+			if skip[container.ID] {
+				continue
+			}
+			switch {
+			case container.Healthy():
+				healthy++
+			case container.Health == "unhealthy":
+				return fmt.Errorf("%s is unhealthy", container.Name)
+			case container.State != "running" && container.State != "created" && container.State != "restarting":
+				return fmt.Errorf("%s is %s", container.Name, container.State)
+			default:
+				waiting++
+			}
+		}
+		if waiting == 0 && healthy > 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if healthy == 0 && waiting == 0 {
+				return fmt.Errorf("no new container for %s", service)
+			}
+			return fmt.Errorf("%s not healthy after %s", service, timeout)
+		}
+		if err := s.executor.Wait(ctx, healthPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitCompleted waits until every container of the service has exited, failing when one
+// exits with a non-zero code or the timeout expires first
+func (s *service) WaitCompleted(ctx context.Context, composePath string, service string, timeout time.Duration) error {
+	containers, err := s.executor.ComposeContainers(ctx, composePath, service)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("no container for %s", service)
+	}
+	ids := make([]string, len(containers))
+	for i, container := range containers {
+		ids[i] = container.ID
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	codes, err := s.executor.ContainerWait(waitCtx, ids)
+	if err != nil {
+		if waitCtx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s did not complete after %s", service, timeout)
+		}
+		return err
+	}
+	for i, code := range codes {
+		if code != 0 {
+			return fmt.Errorf("%s exited with code %d", containers[i].Name, code)
+		}
+	}
+	return nil
+}
+
+func (s *service) Wait(ctx context.Context, d time.Duration) error {
+	return s.executor.Wait(ctx, d)
+}
+
+// ReadFile returns the contents of path inside the running container of service
+func (s *service) ReadFile(ctx context.Context, composePath string, service string, path string) ([]byte, error) {
+	return s.executor.ComposeExecOutput(ctx, composePath, service, "cat", path)
+}
+
 func (s *service) Cleanup(ctx context.Context, options CleanupOptions) error {
 	if options.Images {
 		if err := s.executor.ImagePrune(ctx, "until="+options.MaxAge); err != nil {
@@ -0,0 +1,24 @@
+package dryrun
+
+import "strings"
+
+// FormatCommand renders a command line the way it would be typed in a shell
+func FormatCommand(name string, args ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, quote(name))
+	for _, arg := range args {
+		parts = append(parts, quote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quote wraps an argument in single quotes when the shell would split it
+func quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(arg, " \t\n'\"$`\\|&;<>()*?[]{}!#~") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
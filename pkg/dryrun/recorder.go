@@ -0,0 +1,62 @@
+package dryrun
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leandrodaf/harborctl/pkg/cli"
+)
+
+// Step kinds recorded during a dry run
+const (
+	KindExec  = "exec"
+	KindWrite = "write"
+	KindMkdir = "mkdir"
+	KindSSH   = "ssh"
+	KindWait  = "wait"
+)
+
+// Step is a single action that would have been performed
+type Step struct {
+	Kind   string
+	Detail string
+}
+
+// Recorder collects the actions a dry run would perform, in order
+type Recorder interface {
+	Record(kind, format string, args ...interface{})
+	Steps() []Step
+}
+
+// recorder implements Recorder printing each step as it is recorded
+type recorder struct {
+	mu     sync.Mutex
+	steps  []Step
+	output cli.Output
+}
+
+// NewRecorder creates a recorder that prints every step to output
+func NewRecorder(output cli.Output) Recorder {
+	return &recorder{output: output}
+}
+
+func (r *recorder) Record(kind, format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	step := Step{Kind: kind, Detail: fmt.Sprintf(format, args...)}
+	r.steps = append(r.steps, step)
+
+	if r.output != nil {
+		r.output.Infof("[dry-run] %2d. %-5s %s", len(r.steps), step.Kind, step.Detail)
+	}
+}
+
+func (r *recorder) Steps() []Step {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	steps := make([]Step, len(r.steps))
+	copy(steps, r.steps)
+	return steps
+}
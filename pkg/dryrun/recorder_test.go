@@ -0,0 +1,70 @@
+package dryrun
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// lines collects what the recorder prints
+type lines []string
+
+func (l *lines) Info(msg string)  { *l = append(*l, msg) }
+func (l *lines) Error(msg string) { *l = append(*l, msg) }
+func (l *lines) Infof(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+func (l *lines) Errorf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+
+func TestRecorderKeepsOrder(t *testing.T) {
+	var printed lines
+	r := NewRecorder(&printed)
+
+	r.Record(KindMkdir, "%s", ".deploy")
+	r.Record(KindWrite, "%s (%d bytes)", "compose.yml", 10)
+	r.Record(KindExec, "%s", "docker compose up -d")
+
+	want := []Step{
+		{Kind: KindMkdir, Detail: ".deploy"},
+		{Kind: KindWrite, Detail: "compose.yml (10 bytes)"},
+		{Kind: KindExec, Detail: "docker compose up -d"},
+	}
+	if got := r.Steps(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Steps() = %+v, want %+v", got, want)
+	}
+	wantPrinted := lines{
+		"[dry-run]  1. mkdir .deploy",
+		"[dry-run]  2. write compose.yml (10 bytes)",
+		"[dry-run]  3. exec  docker compose up -d",
+	}
+	if !reflect.DeepEqual(printed, wantPrinted) {
+		t.Fatalf("printed = %q, want %q", printed, wantPrinted)
+	}
+
+	// Steps returns a copy
+	r.Steps()[0].Kind = "changed"
+	if r.Steps()[0].Kind != KindMkdir {
+		t.Fatal("Steps() exposes the recorder's slice")
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"compose", "up", "-d"}, "docker compose up -d"},
+		{[]string{"run", "--label", "a b"}, "docker run --label 'a b'"},
+		{[]string{"exec", "sh", "-c", "echo $HOME"}, "docker exec sh -c 'echo $HOME'"},
+		{[]string{"logs", "it's"}, `docker logs 'it'\''s'`},
+		{[]string{"env", ""}, "docker env ''"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatCommand("docker", tt.args...); got != tt.want {
+			t.Errorf("FormatCommand(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package fs
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
+)
+
+// dryRunFileSystem records writes instead of touching the disk.
+// Reads see files written earlier in the same run, falling back to the real filesystem.
+type dryRunFileSystem struct {
+	mu       sync.Mutex
+	base     FileSystem
+	recorder dryrun.Recorder
+	files    map[string][]byte
+	dirs     map[string]bool
+}
+
+// NewDryRunFileSystem creates a filesystem that records writes on top of base
+func NewDryRunFileSystem(base FileSystem, recorder dryrun.Recorder) FileSystem {
+	return &dryRunFileSystem{
+		base:     base,
+		recorder: recorder,
+		files:    make(map[string][]byte),
+		dirs:     make(map[string]bool),
+	}
+}
+
+func (d *dryRunFileSystem) ReadFile(path string) ([]byte, error) {
+	d.mu.Lock()
+	data, ok := d.files[filepath.Clean(path)]
+	d.mu.Unlock()
+
+	if ok {
+		return data, nil
+	}
+	return d.base.ReadFile(path)
+}
+
+func (d *dryRunFileSystem) WriteFile(path string, data []byte, perm int) error {
+	d.mu.Lock()
+	d.files[filepath.Clean(path)] = data
+	d.mu.Unlock()
+
+	d.recorder.Record(dryrun.KindWrite, "%s (%d bytes, mode %#o)", path, len(data), perm)
+	return nil
+}
+
+func (d *dryRunFileSystem) Exists(path string) bool {
+	clean := filepath.Clean(path)
+
+	d.mu.Lock()
+	_, isFile := d.files[clean]
+	isDir := d.dirs[clean]
+	d.mu.Unlock()
+
+	return isFile || isDir || d.base.Exists(path)
+}
+
+func (d *dryRunFileSystem) MkdirAll(path string, perm int) error {
+	clean := filepath.Clean(path)
+	if d.base.Exists(clean) {
+		return nil
+	}
+
+	d.mu.Lock()
+	seen := d.dirs[clean]
+	d.dirs[clean] = true
+	d.mu.Unlock()
+
+	if !seen {
+		d.recorder.Record(dryrun.KindMkdir, "%s (mode %#o)", path, perm)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
+)
+
+func TestDryRunFileSystemRecordsWrites(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "stack.yml")
+	if err := os.WriteFile(existing, []byte("project: demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := dryrun.NewRecorder(nil)
+	fsys := NewDryRunFileSystem(NewFileSystem(), recorder)
+
+	deploy := filepath.Join(dir, ".deploy")
+	compose := filepath.Join(deploy, "compose.yml")
+	for _, step := range []func() error{
+		func() error { return fsys.MkdirAll(deploy, 0755) },
+		func() error { return fsys.MkdirAll(deploy, 0755) }, // repeated: recorded once
+		func() error { return fsys.MkdirAll(dir, 0755) },    // already on disk: not recorded
+		func() error { return fsys.WriteFile(compose, []byte("services: {}\n"), 0644) },
+		func() error { return fsys.WriteFile(existing, []byte("project: new\n"), 0600) },
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []dryrun.Step{
+		{Kind: dryrun.KindMkdir, Detail: deploy + " (mode 0755)"},
+		{Kind: dryrun.KindWrite, Detail: compose + " (13 bytes, mode 0644)"},
+		{Kind: dryrun.KindWrite, Detail: existing + " (13 bytes, mode 0600)"},
+	}
+	if got := recorder.Steps(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Steps() = %+v, want %+v", got, want)
+	}
+
+	// Reads see the planned content; the disk is untouched
+	if data, err := fsys.ReadFile(existing); err != nil || string(data) != "project: new\n" {
+		t.Fatalf("ReadFile() = %q, %v, want the planned content", data, err)
+	}
+	if !fsys.Exists(compose) || !fsys.Exists(deploy) {
+		t.Fatal("Exists() = false for a planned file or directory")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "project: demo\n" {
+		t.Fatalf("file on disk = %q, want it unchanged", data)
+	}
+	if _, err := os.Stat(deploy); !os.IsNotExist(err) {
+		t.Fatalf("%s was created on disk: %v", deploy, err)
+	}
+}
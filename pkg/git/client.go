@@ -7,23 +7,38 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
 )
 
-// Client implements secure git operations
-type Client struct{}
+// client implements secure git operations
+type client struct {
+	recorder dryrun.Recorder
+}
 
 // NewClient creates a new git client
-func NewClient() *Client {
-	return &Client{}
+func NewClient() Client {
+	return &client{}
+}
+
+// NewDryRunClient creates a git client that records commands instead of running them
+func NewDryRunClient(recorder dryrun.Recorder) Client {
+	return &client{recorder: recorder}
 }
 
 // Clone clones a repository
-func (c *Client) Clone(ctx context.Context, url, path, token string) error {
+func (c *client) Clone(ctx context.Context, url, path, token string) error {
 	// Validate URL
 	if err := c.validateGitURL(url); err != nil {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
 
+	if c.recorder != nil {
+		// Never print the token, only the public URL
+		c.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand("git", "clone", url, path))
+		return nil
+	}
+
 	// Prepare command
 	var cmd *exec.Cmd
 	if token != "" {
@@ -44,39 +59,21 @@ func (c *Client) Clone(ctx context.Context, url, path, token string) error {
 }
 
 // Pull updates an existing repository
-func (c *Client) Pull(ctx context.Context, path, branch string) error {
+func (c *client) Pull(ctx context.Context, path, branch string) error {
 	// Check if it's a valid git repository
 	if !c.isValidGitRepo(path) {
 		return fmt.Errorf("not a valid git repository: %s", path)
 	}
 
-	// Change to directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err := os.Chdir(oldDir); err != nil {
-			// Log the error but don't fail the operation
-			fmt.Printf("Warning: failed to restore directory: %v\n", err)
-		}
-	}()
-
-	if err := os.Chdir(path); err != nil {
-		return fmt.Errorf("error accessing directory: %w", err)
-	}
-
 	// Checkout branch if specified
 	if branch != "" {
-		cmd := exec.CommandContext(ctx, "git", "checkout", branch)
-		if output, err := cmd.CombinedOutput(); err != nil {
+		if output, err := c.run(ctx, "-C", path, "checkout", branch); err != nil {
 			return fmt.Errorf("error checking out branch %s: %s", branch, string(output))
 		}
 	}
 
 	// Pull
-	cmd := exec.CommandContext(ctx, "git", "pull", "origin")
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := c.run(ctx, "-C", path, "pull", "origin"); err != nil {
 		return fmt.Errorf("error pulling: %s", string(output))
 	}
 
@@ -84,11 +81,12 @@ func (c *Client) Pull(ctx context.Context, path, branch string) error {
 }
 
 // GetLatestCommit gets the latest commit hash
-func (c *Client) GetLatestCommit(ctx context.Context, path string) (string, error) {
+func (c *client) GetLatestCommit(ctx context.Context, path string) (string, error) {
 	if !c.isValidGitRepo(path) {
 		return "", fmt.Errorf("not a valid git repository: %s", path)
 	}
 
+	// Read-only: safe to run even during a dry run
 	cmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
@@ -98,8 +96,19 @@ func (c *Client) GetLatestCommit(ctx context.Context, path string) (string, erro
 	return strings.TrimSpace(string(output)), nil
 }
 
+// run executes a mutating git command, or records it during a dry run
+func (c *client) run(ctx context.Context, args ...string) ([]byte, error) {
+	if c.recorder != nil {
+		c.recorder.Record(dryrun.KindExec, "%s", dryrun.FormatCommand("git", args...))
+		return nil, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	return cmd.CombinedOutput()
+}
+
 // validateGitURL validates a git URL
-func (c *Client) validateGitURL(url string) error {
+func (c *client) validateGitURL(url string) error {
 	// Check if URL is valid
 	if url == "" {
 		return fmt.Errorf("empty URL")
@@ -124,7 +133,7 @@ func (c *Client) validateGitURL(url string) error {
 }
 
 // addTokenToURL adds authentication token to URL
-func (c *Client) addTokenToURL(url, token string) string {
+func (c *client) addTokenToURL(url, token string) string {
 	if strings.HasPrefix(url, "https://github.com/") {
 		return strings.Replace(url, "https://", fmt.Sprintf("https://%s@", token), 1)
 	}
@@ -135,7 +144,7 @@ func (c *Client) addTokenToURL(url, token string) string {
 }
 
 // isValidGitRepo checks if a directory is a valid git repository
-func (c *Client) isValidGitRepo(path string) bool {
+func (c *client) isValidGitRepo(path string) bool {
 	gitDir := filepath.Join(path, ".git")
 	if stat, err := os.Stat(gitDir); err == nil {
 		return stat.IsDir()
@@ -0,0 +1,10 @@
+package git
+
+import "context"
+
+// Client performs git operations on service repositories
+type Client interface {
+	Clone(ctx context.Context, url, path, token string) error
+	Pull(ctx context.Context, path, branch string) error
+	GetLatestCommit(ctx context.Context, path string) (string, error)
+}
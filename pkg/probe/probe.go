@@ -0,0 +1,199 @@
+// Package probe implements the health probes run inside containers by "harborctl probe".
+// harborctl is a static binary, so it can be mounted into images that ship no curl or wget.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPOptions describes an HTTP probe
+type HTTPOptions struct {
+	URL     string
+	Method  string            // default GET
+	Status  int               // expected status; 0 accepts any 2xx or 3xx
+	Headers map[string]string // sent with the request; Host overrides the request host
+	Timeout time.Duration
+}
+
+// HTTP sends one request and checks the response status. Redirects are not followed
+func HTTP(ctx context.Context, opts HTTPOptions) error {
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, opts.URL, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range opts.Headers {
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case opts.Status > 0 && resp.StatusCode != opts.Status:
+		return fmt.Errorf("%s %s: status %d, expected %d", method, opts.URL, resp.StatusCode, opts.Status)
+	case opts.Status == 0 && resp.StatusCode >= 400:
+		return fmt.Errorf("%s %s: status %d", method, opts.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// TCP checks that address accepts connections
+func TCP(ctx context.Context, address string, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// gRPC health checking protocol (grpc.health.v1), encoded by hand to avoid the grpc dependency
+const (
+	grpcHealthPath = "/grpc.health.v1.Health/Check"
+	grpcServing    = 1
+)
+
+var grpcStatuses = map[uint64]string{0: "UNKNOWN", 1: "SERVING", 2: "NOT_SERVING", 3: "SERVICE_UNKNOWN"}
+
+// GRPC calls grpc.health.v1.Health/Check over plaintext HTTP/2 and requires SERVING.
+// An empty service asks for the overall server health
+func GRPC(ctx context.Context, address, service string, timeout time.Duration) error {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Timeout: timeout, Transport: &http.Transport{Protocols: protocols}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+address+grpcHealthPath, bytes.NewReader(grpcFrame(service)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("grpc %s: http status %d", address, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+
+	// Trailers-only responses carry the status in the headers
+	code := resp.Trailer.Get("Grpc-Status")
+	if code == "" {
+		code = resp.Header.Get("Grpc-Status")
+	}
+	if code != "0" {
+		message := resp.Trailer.Get("Grpc-Message")
+		if message == "" {
+			message = resp.Header.Get("Grpc-Message")
+		}
+		return fmt.Errorf("grpc %s: status %s %s", address, code, message)
+	}
+
+	status, err := grpcServingStatus(body)
+	if err != nil {
+		return fmt.Errorf("grpc %s: %w", address, err)
+	}
+	if status != grpcServing {
+		name := grpcStatuses[status]
+		if name == "" {
+			name = fmt.Sprint(status)
+		}
+		return fmt.Errorf("grpc %s: %s", address, name)
+	}
+	return nil
+}
+
+// grpcFrame encodes HealthCheckRequest{service} with the gRPC length prefix
+func grpcFrame(service string) []byte {
+	var message []byte
+	if service != "" {
+		message = append(message, 0x0a) // field 1, length-delimited
+		message = binary.AppendUvarint(message, uint64(len(service)))
+		message = append(message, service...)
+	}
+
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// grpcServingStatus decodes the status field of a framed HealthCheckResponse
+func grpcServingStatus(body []byte) (uint64, error) {
+	if len(body) < 5 {
+		return 0, errors.New("empty response")
+	}
+	if body[0] != 0 {
+		return 0, errors.New("compressed responses are not supported")
+	}
+	size := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < size {
+		return 0, errors.New("truncated response")
+	}
+	message := body[5 : 5+size]
+
+	// Unknown fields are skipped; a missing status means UNKNOWN
+	var status uint64
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errors.New("malformed response")
+		}
+		message = message[n:]
+
+		switch key & 7 {
+		case 0:
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return 0, errors.New("malformed response")
+			}
+			message = message[n:]
+			if key>>3 == 1 {
+				status = value
+			}
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < length {
+				return 0, errors.New("malformed response")
+			}
+			message = message[n+int(length):]
+		default:
+			return 0, errors.New("malformed response")
+		}
+	}
+	return status, nil
+}
@@ -5,15 +5,24 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+
+	"github.com/leandrodaf/harborctl/pkg/dryrun"
 )
 
-type executor struct{}
+type executor struct {
+	recorder dryrun.Recorder
+}
 
 // NewExecutor creates a new SSH executor
 func NewExecutor() Executor {
 	return &executor{}
 }
 
+// NewDryRunExecutor creates an SSH executor that records commands instead of running them
+func NewDryRunExecutor(recorder dryrun.Recorder) Executor {
+	return &executor{recorder: recorder}
+}
+
 func (e *executor) Execute(ctx context.Context, config Config, command string) error {
 	args := []string{
 		"-o", "StrictHostKeyChecking=no",
@@ -29,6 +38,11 @@ func (e *executor) Execute(ctx context.Context, config Config, command string) e
 	target := fmt.Sprintf("%s@%s", config.User, config.Host)
 	args = append(args, target, command)
 
+	if e.recorder != nil {
+		e.recorder.Record(dryrun.KindSSH, "%s", dryrun.FormatCommand("ssh", args...))
+		return nil
+	}
+
 	cmd := exec.CommandContext(ctx, "ssh", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
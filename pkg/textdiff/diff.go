@@ -0,0 +1,306 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff between a and b, or "" when they are equal
+func Unified(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Group operations into hunks with surrounding context
+	for start := 0; start < len(ops); {
+		// Find next change
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		hunkStart := first - contextLines
+		if hunkStart < start {
+			hunkStart = start
+		}
+
+		// Extend the hunk while changes are close together
+		end := first
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*contextLines {
+				break
+			}
+			end = next
+		}
+		hunkEnd := end + contextLines
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		writeHunk(&out, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []op, from, to int) {
+	oldLine, newLine := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != opInsert {
+			oldLine++
+		}
+		if o.kind != opDelete {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, o := range ops[from:to] {
+		switch o.kind {
+		case opEqual:
+			out.WriteString(" " + o.line + "\n")
+		case opDelete:
+			out.WriteString("-" + o.line + "\n")
+		case opInsert:
+			out.WriteString("+" + o.line + "\n")
+		}
+	}
+}
+
+// diffLines computes an edit script using the longest common subsequence
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// RestoreFormatting undoes cosmetic changes a re-encoding made to original:
+// dropped blank lines are re-inserted and lines that only differ in the spacing
+// before a trailing comment keep their original form
+func RestoreFormatting(original, updated string) string {
+	trailingNewline := strings.HasSuffix(updated, "\n")
+
+	var out, deleted, inserted []string
+	flush := func() {
+		// Blank lines before a removed line stay above its replacement
+		leading := 0
+		for leading < len(deleted) && strings.TrimSpace(deleted[leading]) == "" {
+			leading++
+		}
+		if leading < len(deleted) && len(inserted) > 0 {
+			for i := 0; i < leading; i++ {
+				out = append(out, "")
+			}
+			deleted = deleted[leading:]
+		}
+
+		used := make([]bool, len(deleted))
+		for _, line := range inserted {
+			matched := false
+			for i, old := range deleted {
+				if !used[i] && sameIgnoringCommentSpacing(old, line) {
+					line, matched = old, true
+					used[i] = true
+					break
+				}
+			}
+			// A changed line keeps its comment at the column it had
+			for i, old := range deleted {
+				if matched || used[i] {
+					continue
+				}
+				if aligned, ok := alignComment(old, line); ok {
+					line = aligned
+					used[i] = true
+					break
+				}
+			}
+			out = append(out, line)
+		}
+		for _, old := range deleted {
+			if strings.TrimSpace(old) == "" {
+				out = append(out, "")
+			}
+		}
+		deleted, inserted = nil, nil
+	}
+
+	for _, o := range diffLines(splitLines(original), splitLines(updated)) {
+		switch o.kind {
+		case opEqual:
+			flush()
+			out = append(out, o.line)
+		case opDelete:
+			deleted = append(deleted, o.line)
+		case opInsert:
+			inserted = append(inserted, o.line)
+		}
+	}
+	flush()
+
+	// Blank lines of a removed trailing block are not kept
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	result := strings.Join(out, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result
+}
+
+// sameIgnoringCommentSpacing compares two lines, allowing different spacing around a trailing comment
+func sameIgnoringCommentSpacing(a, b string) bool {
+	codeA, commentA := splitComment(a)
+	codeB, commentB := splitComment(b)
+	return codeA == codeB && commentA == commentB
+}
+
+// alignComment moves the trailing comment of line to the column it has in old, when both
+// lines carry the same comment. The comment keeps at least one space before it
+func alignComment(old, line string) (string, bool) {
+	oldStart := commentStart(old)
+	_, oldComment := splitComment(old)
+	code, comment := splitComment(line)
+	if oldStart < 0 || comment == "" || code == "" || comment != oldComment {
+		return line, false
+	}
+	padding := oldStart - len(code)
+	if padding < 1 {
+		padding = 1
+	}
+	return code + strings.Repeat(" ", padding) + comment, true
+}
+
+// splitComment separates a YAML line from its trailing comment. A '#' starts a comment
+// only at the start of the line or after whitespace, and never inside a quoted scalar
+func splitComment(line string) (string, string) {
+	if idx := commentStart(line); idx >= 0 {
+		return strings.TrimRight(line[:idx], " \t"), strings.TrimSpace(line[idx:])
+	}
+	return strings.TrimRight(line, " \t"), ""
+}
+
+// commentStart returns the index of the '#' that opens the line comment, or -1
+func commentStart(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quote = 0
+			}
+		case quote == '\'':
+			// '' is an escaped quote inside a single-quoted scalar
+			if c == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+				} else {
+					quote = 0
+				}
+			}
+		case c == '"' || c == '\'':
+			// Quotes only open a scalar at the start of a value, not inside plain text (don't)
+			if i == 0 || strings.IndexByte(" \t:-[{,", line[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return i
+			}
+		}
+	}
+	return -1
+}
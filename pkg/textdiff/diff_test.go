@@ -0,0 +1,133 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitComment(t *testing.T) {
+	tests := []struct {
+		line    string
+		code    string
+		comment string
+	}{
+		{"key: value", "key: value", ""},
+		{"key: value # note", "key: value", "# note"},
+		{"key: value   #   note  ", "key: value", "#   note"},
+		{"# full line", "", "# full line"},
+		{"  # indented", "", "# indented"},
+		{"url: http://x/#anchor", "url: http://x/#anchor", ""},
+		{`label: "team #1"`, `label: "team #1"`, ""},
+		{`label: "team #1" # why`, `label: "team #1"`, "# why"},
+		{`label: "say \"#1\"" # why`, `label: "say \"#1\""`, "# why"},
+		{"label: 'team #1'", "label: 'team #1'", ""},
+		{"label: 'it''s #1' # why", "label: 'it''s #1'", "# why"},
+		{"- 'a #b'", "- 'a #b'", ""},
+		{"note: don't #1", "note: don't", "#1"},
+		{"list: [\"a #b\", c] # x", "list: [\"a #b\", c]", "# x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			code, comment := splitComment(tt.line)
+			if code != tt.code || comment != tt.comment {
+				t.Fatalf("splitComment(%q) = (%q, %q), want (%q, %q)", tt.line, code, comment, tt.code, tt.comment)
+			}
+		})
+	}
+}
+
+func TestRestoreFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		updated  string
+		want     string
+	}{
+		{
+			name:     "unchanged",
+			original: "a: 1\n\nb: 2 # two\n",
+			updated:  "a: 1\n\nb: 2 # two\n",
+			want:     "a: 1\n\nb: 2 # two\n",
+		},
+		{
+			name:     "blank lines restored",
+			original: "a: 1\n\n\nb: 2\n",
+			updated:  "a: 1\nb: 3\n",
+			want:     "a: 1\n\n\nb: 3\n",
+		},
+		{
+			name:     "comment spacing restored",
+			original: "a: 1    # aligned\nb: 2\n",
+			updated:  "a: 1 # aligned\nb: 3\n",
+			want:     "a: 1    # aligned\nb: 3\n",
+		},
+		{
+			name:     "hash inside quotes is not a comment",
+			original: "label: \"team #1\"\nb: 2\n",
+			updated:  "label: \"team #2\"\nb: 2\n",
+			want:     "label: \"team #2\"\nb: 2\n",
+		},
+		{
+			name:     "quoted value with comment keeps spacing",
+			original: "label: \"team #1\"   # who\nb: 2\n",
+			updated:  "label: \"team #1\" # who\nb: 3\n",
+			want:     "label: \"team #1\"   # who\nb: 3\n",
+		},
+		{
+			name:     "changed line keeps its comment column",
+			original: "domain: example.com   # prod\nb: 2\n",
+			updated:  "hostname: example.com # prod\nb: 2\n",
+			want:     "hostname: example.com # prod\nb: 2\n",
+		},
+		{
+			name:     "shorter changed line pads up to the comment column",
+			original: "image: api:10   # current\n",
+			updated:  "image: api:9 # current\n",
+			want:     "image: api:9    # current\n",
+		},
+		{
+			name:     "removed trailing block drops its blank lines",
+			original: "a: 1\n\nb: 2\n",
+			updated:  "a: 1\n",
+			want:     "a: 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RestoreFormatting(tt.original, tt.updated); got != tt.want {
+				t.Fatalf("RestoreFormatting() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	if got := Unified("a", "b", "x\n", "x\n"); got != "" {
+		t.Fatalf("Unified() of equal texts = %q, want empty", got)
+	}
+
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\nTWO\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+	want := strings.Join([]string{
+		"--- old",
+		"+++ new",
+		"@@ -1,5 +1,5 @@",
+		" one",
+		"-two",
+		"+TWO",
+		" three",
+		" four",
+		" five",
+		"@@ -8,3 +8,4 @@",
+		" eight",
+		" nine",
+		" ten",
+		"+eleven",
+		"",
+	}, "\n")
+	if got := Unified("old", "new", a, b); got != want {
+		t.Fatalf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,283 @@
+// Package traefikrule parses and checks Traefik v3 HTTP router rules.
+package traefikrule
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher is a single matcher call in a rule, like Host(`example.com`)
+type Matcher struct {
+	Name string
+	Args []string
+	Pos  int // byte offset of the matcher name in the rule
+}
+
+// Error is a syntax or argument error at a byte offset of the rule
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// matcherArgs is the number of arguments each Traefik v3 HTTP matcher takes
+var matcherArgs = map[string]int{
+	"Host":         1,
+	"HostRegexp":   1,
+	"Path":         1,
+	"PathPrefix":   1,
+	"PathRegexp":   1,
+	"Method":       1,
+	"Header":       2,
+	"HeaderRegexp": 2,
+	"Query":        2,
+	"QueryRegexp":  2,
+	"ClientIP":     1,
+}
+
+var (
+	hostFormat   = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+	methodFormat = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// Parse checks the rule syntax and each matcher's arguments, returning the matchers in order
+func Parse(rule string) ([]Matcher, error) {
+	p := &parser{input: rule}
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return nil, &Error{Pos: 0, Msg: "empty rule"}
+	}
+	if err := p.parseOr(); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:p.pos+1])
+	}
+	return p.matchers, nil
+}
+
+// parser is a recursive descent parser over the rule grammar:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" or ")" | matcher
+//	matcher = name "(" string { "," string } ")"
+type parser struct {
+	input    string
+	pos      int
+	matchers []Matcher
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &Error{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consumes token if it is next in the input
+func (p *parser) accept(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+	for p.accept("||") {
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseAnd() error {
+	if err := p.parseUnary(); err != nil {
+		return err
+	}
+	for p.accept("&&") {
+		if err := p.parseUnary(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseUnary() error {
+	if p.accept("!") {
+		return p.parseUnary()
+	}
+	if p.accept("(") {
+		if err := p.parseOr(); err != nil {
+			return err
+		}
+		if !p.accept(")") {
+			return p.errorf("missing ')'")
+		}
+		return nil
+	}
+	return p.parseMatcher()
+}
+
+func (p *parser) parseMatcher() error {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) && isIdentChar(p.input[p.pos]) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	if name == "" {
+		if p.pos == len(p.input) {
+			return p.errorf("unexpected end of rule, expected a matcher")
+		}
+		return p.errorf("unexpected %q, expected a matcher", p.input[p.pos:p.pos+1])
+	}
+
+	want, ok := matcherArgs[name]
+	if !ok {
+		p.pos = start
+		return p.errorf("unknown matcher %s%s", name, suggest(name))
+	}
+	if !p.accept("(") {
+		return p.errorf("expected '(' after %s", name)
+	}
+
+	var args []string
+	for {
+		arg, err := p.parseString()
+		if err != nil {
+			return err
+		}
+		args = append(args, arg)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if !p.accept(")") {
+		return p.errorf("expected ')' to close %s", name)
+	}
+
+	if len(args) != want {
+		p.pos = start
+		return p.errorf("%s takes %d argument(s), got %d", name, want, len(args))
+	}
+	if err := checkArgs(name, args); err != nil {
+		return &Error{Pos: start, Msg: err.Error()}
+	}
+
+	p.matchers = append(p.matchers, Matcher{Name: name, Args: args, Pos: start})
+	return nil
+}
+
+// parseString reads a backtick or double-quoted string
+func (p *parser) parseString() (string, error) {
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return "", p.errorf("unexpected end of rule, expected a string")
+	}
+
+	switch p.input[p.pos] {
+	case '`':
+		end := strings.IndexByte(p.input[p.pos+1:], '`')
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, nil
+	case '"':
+		for i := p.pos + 1; i < len(p.input); i++ {
+			if p.input[i] == '\\' {
+				i++
+				continue
+			}
+			if p.input[i] == '"' {
+				value, err := strconv.Unquote(p.input[p.pos : i+1])
+				if err != nil {
+					return "", p.errorf("invalid string %s", p.input[p.pos:i+1])
+				}
+				p.pos = i + 1
+				return value, nil
+			}
+		}
+		return "", p.errorf("unterminated string")
+	}
+	return "", p.errorf("expected a string in backticks, got %q", p.input[p.pos:p.pos+1])
+}
+
+// checkArgs validates the matcher arguments the way Traefik does when loading the router
+func checkArgs(name string, args []string) error {
+	switch name {
+	case "Host":
+		if !hostFormat.MatchString(args[0]) {
+			return fmt.Errorf("Host: invalid hostname %q (use HostRegexp for patterns)", args[0])
+		}
+	case "Path", "PathPrefix":
+		if !strings.HasPrefix(args[0], "/") {
+			return fmt.Errorf("%s: path must start with '/': %q", name, args[0])
+		}
+	case "Method":
+		if !methodFormat.MatchString(args[0]) {
+			return fmt.Errorf("Method: invalid method %q (use uppercase, ex: GET)", args[0])
+		}
+	case "HostRegexp", "PathRegexp":
+		if _, err := regexp.Compile(args[0]); err != nil {
+			return fmt.Errorf("%s: invalid regexp %q: %v", name, args[0], err)
+		}
+	case "HeaderRegexp", "QueryRegexp":
+		if _, err := regexp.Compile(args[1]); err != nil {
+			return fmt.Errorf("%s: invalid regexp %q: %v", name, args[1], err)
+		}
+	case "Header":
+		if args[0] == "" {
+			return fmt.Errorf("Header: empty header name")
+		}
+	case "ClientIP":
+		if net.ParseIP(args[0]) == nil {
+			if _, _, err := net.ParseCIDR(args[0]); err != nil {
+				return fmt.Errorf("ClientIP: invalid IP or CIDR %q", args[0])
+			}
+		}
+	}
+	return nil
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// suggest points to the right matcher for case mistakes and names removed in Traefik v3
+func suggest(name string) string {
+	switch name {
+	case "HostHeader":
+		return " (removed in Traefik v3, use Host)"
+	case "Headers":
+		return " (renamed to Header in Traefik v3)"
+	case "HeadersRegexp":
+		return " (renamed to HeaderRegexp in Traefik v3)"
+	case "HostSNI", "HostSNIRegexp", "ALPN":
+		return " (only valid in TCP rules)"
+	}
+	for known := range matcherArgs {
+		if strings.EqualFold(known, name) {
+			return fmt.Sprintf(" (did you mean %s?)", known)
+		}
+	}
+	return ""
+}
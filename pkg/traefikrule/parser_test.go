@@ -0,0 +1,228 @@
+package traefikrule
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// matcherPositions resume o resultado do Parse como Nome@posição, na ordem de leitura
+func matcherPositions(matchers []Matcher) []string {
+	out := make([]string, len(matchers))
+	for i, m := range matchers {
+		out[i] = fmt.Sprintf("%s@%d", m.Name, m.Pos)
+	}
+	return out
+}
+
+func TestParseOperators(t *testing.T) {
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"Host(`a.com`)", []string{"Host@0"}},
+		{"Host(`a.com`) && Path(`/x`) || Method(`GET`)", []string{"Host@0", "Path@17", "Method@31"}},
+		{"Method(`GET`) || Host(`a.com`) && Path(`/x`)", []string{"Method@0", "Host@17", "Path@34"}},
+		{"!Host(`a.com`) && Path(`/x`)", []string{"Host@1", "Path@18"}},
+		{"!!Host(`a.com`)", []string{"Host@2"}},
+		{"Host(`a.com`)&&Path(`/x`)||Method(`GET`)", []string{"Host@0", "Path@15", "Method@27"}},
+		{"(Host(`a.com`))", []string{"Host@1"}},
+		{"Host(`a.com`) && (Path(`/x`) || Method(`GET`))", []string{"Host@0", "Path@18", "Method@32"}},
+		{"!(Host(`a.com`) || Path(`/x`))", []string{"Host@2", "Path@19"}},
+		{"((Host(`a.com`) || (Path(`/x`) && !Method(`GET`))) && ClientIP(`10.0.0.0/8`))", []string{"Host@2", "Path@20", "Method@35", "ClientIP@54"}},
+		{"  ( Host(`a.com`)\n&&\tPath(`/x`) )  ", []string{"Host@4", "Path@21"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			matchers, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			if got := matcherPositions(matchers); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Parse(%q) = %v, want %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+// Operadores e parênteses fora do lugar falham onde a gramática deixa de aceitar o próximo token:
+// && e || só separam operandos, ! só prefixa um operando e ) só fecha um grupo aberto
+func TestParseOperatorErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+		pos  int
+	}{
+		{"Host(`a.com`) || && Path(`/x`)", "expected a matcher", 17},
+		{"&& Host(`a.com`)", "expected a matcher", 0},
+		{"Host(`a.com`) !Path(`/x`)", "unexpected \"!\"", 14},
+		{"Host(`a.com`) && Path(`/x`)!", "unexpected \"!\"", 27},
+		{"Host(`a.com`) Path(`/x`)", "unexpected \"P\"", 14},
+		{"(Host(`a.com`)) (Path(`/x`))", "unexpected \"(\"", 16},
+		{"Host(`a.com`) || Path(`/x`)) && Method(`GET`)", "unexpected \")\"", 27},
+		{"!(Host(`a.com`) || Path(`/x`)", "missing ')'", 29},
+		{"Host(`a.com`) && (Path(`/x`) || (Method(`GET`))", "missing ')'", 47},
+		{"()", "unexpected \")\", expected a matcher", 1},
+		{"Host(`a.com`) && !", "unexpected end of rule", 18},
+		{"Host(`a.com`) & Path(`/x`)", "unexpected \"&\"", 14},
+		{"Host(`a.com`) | Path(`/x`)", "unexpected \"|\"", 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			var parseErr *Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.rule, err)
+			}
+			if !strings.Contains(parseErr.Msg, tt.want) || parseErr.Pos != tt.pos {
+				t.Fatalf("Parse(%q) error = %q at %d, want %q at %d", tt.rule, parseErr.Msg, parseErr.Pos, tt.want, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"Header(`X-Env`, `prod`)", []string{"X-Env", "prod"}},
+		{`Header("X-Env", "prod")`, []string{"X-Env", "prod"}},
+		{"Header(`X-Env`, \"prod\")", []string{"X-Env", "prod"}},
+		{`Header("X-Quote", "say \"hi\"")`, []string{"X-Quote", `say "hi"`}},
+		{"Header(`X-Raw`, `a\\\"b`)", []string{"X-Raw", `a\"b`}},
+		{"Header(`X-Op`, `a && b || !c`)", []string{"X-Op", "a && b || !c"}},
+		{"Query(`q`, ``)", []string{"q", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			matchers, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			if len(matchers) != 1 || !reflect.DeepEqual(matchers[0].Args, tt.want) {
+				t.Fatalf("Parse(%q) = %+v, want args %q", tt.rule, matchers, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want string
+		pos  int
+	}{
+		{"empty", "   ", "empty rule", 0},
+		{"missing operand", "Host(`a.com`) &&", "expected a matcher", 16},
+		{"dangling not", "!", "expected a matcher", 1},
+		{"double operator", "Host(`a.com`) && || Path(`/x`)", "expected a matcher", 17},
+		{"unclosed paren", "(Host(`a.com`)", "missing ')'", 14},
+		{"extra paren", "Host(`a.com`))", "unexpected \")\"", 13},
+		{"unterminated backtick", "Host(`a.com)", "unterminated string", 5},
+		{"unterminated quote", `Host("a.com)`, "unterminated string", 5},
+		{"single quotes", "Host('a.com')", "expected a string in backticks", 5},
+		{"missing args", "Host()", "expected a string in backticks", 5},
+		{"too few args", "Header(`X-Env`)", "Header takes 2 argument(s), got 1", 0},
+		{"too many args", "Host(`a.com`, `b.com`)", "Host takes 1 argument(s), got 2", 0},
+		{"bad host", "Host(`*.a.com`)", "use HostRegexp", 0},
+		{"bad path", "PathPrefix(`api`)", "path must start with '/'", 0},
+		{"bad method", "Method(`get`)", "use uppercase", 0},
+		{"bad regexp", "PathRegexp(`(`)", "invalid regexp", 0},
+		{"bad client ip", "ClientIP(`10.0.0`)", "invalid IP or CIDR", 0},
+		{"error position", "Path(`/x`) && Method(`get`)", "invalid method", 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			var parseErr *Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.rule, err)
+			}
+			if !strings.Contains(parseErr.Msg, tt.want) {
+				t.Fatalf("Parse(%q) error = %q, want it to contain %q", tt.rule, parseErr.Msg, tt.want)
+			}
+			if parseErr.Pos != tt.pos {
+				t.Fatalf("Parse(%q) error at %d, want %d", tt.rule, parseErr.Pos, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParseMatcherArgCounts(t *testing.T) {
+	args := map[int]string{
+		1: "(`/x`)",
+		2: "(`X-Env`, `prod`)",
+	}
+	valid := map[string]string{
+		"Host":       "(`a.com`)",
+		"HostRegexp": "(`^.+\\.a\\.com$`)",
+		"Method":     "(`GET`)",
+		"ClientIP":   "(`10.0.0.1`)",
+	}
+
+	for name, want := range matcherArgs {
+		t.Run(name, func(t *testing.T) {
+			call := args[want]
+			if v, ok := valid[name]; ok {
+				call = v
+			}
+			if _, err := Parse(name + call); err != nil {
+				t.Fatalf("Parse(%s%s) error = %v", name, call, err)
+			}
+
+			wrong := args[3-want]
+			_, err := Parse(name + wrong)
+			if err == nil || !strings.Contains(err.Error(), "argument(s)") {
+				t.Fatalf("Parse(%s%s) error = %v, want an argument count error", name, wrong, err)
+			}
+		})
+	}
+}
+
+func TestParseRejectsOtherMatchers(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"HostHeader(`a.com`)", "removed in Traefik v3, use Host"},
+		{"Headers(`X-Env`, `prod`)", "renamed to Header in Traefik v3"},
+		{"HeadersRegexp(`X-Env`, `prod`)", "renamed to HeaderRegexp in Traefik v3"},
+		{"HostSNI(`a.com`)", "only valid in TCP rules"},
+		{"ALPN(`h2`)", "only valid in TCP rules"},
+		{"host(`a.com`)", "did you mean Host?"},
+		{"Path(`/x`) && Foo(`bar`)", "unknown matcher Foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Parse(%q) error = %v, want it to contain %q", tt.rule, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMatchersInOrder(t *testing.T) {
+	matchers, err := Parse("Host(`a.com`) && (PathPrefix(`/api`) || !Header(`X-Env`, `dev`))")
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+
+	want := []Matcher{
+		{Name: "Host", Args: []string{"a.com"}, Pos: 0},
+		{Name: "PathPrefix", Args: []string{"/api"}, Pos: 18},
+		{Name: "Header", Args: []string{"X-Env", "dev"}, Pos: 41},
+	}
+	if !reflect.DeepEqual(matchers, want) {
+		t.Fatalf("Parse = %+v, want %+v", matchers, want)
+	}
+}